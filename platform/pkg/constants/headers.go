@@ -5,6 +5,7 @@ package constants
 const (
 	HeaderXForwardedFor = "X-Forwarded-For"
 	HeaderXRealIP       = "X-Real-IP"
+	HeaderForwarded     = "Forwarded"
 	HeaderRequestID     = "X-Request-ID"
 	HeaderAuth          = "Authorization"
 	HeaderCookie        = "Cookie"
@@ -27,3 +28,52 @@ const (
 	// HeaderInternalSignature służy do weryfikacji integralności payloadu.
 	HeaderInternalSignature = "X-Internal-Signature"
 )
+
+// HTTP Headers - Web Session (tryb przeglądarkowy gatewaya)
+// Sesja w ciasteczku HttpOnly zamiast tokenów bearer; metody modyfikujące wymagają tokenu CSRF.
+const (
+	// HeaderSessionMode – "cookie" przy logowaniu prosi o sesję w ciasteczku
+	HeaderSessionMode = "X-Session-Mode"
+	HeaderCSRFToken   = "X-CSRF-Token"
+)
+
+// HTTP Headers - Device Proof (proof-of-possession w stylu DPoP, RFC 9449)
+// JWT podpisany kluczem Ed25519 urządzenia: metoda, URL, czas, nonce i skrót tokenu dostępu.
+const (
+	HeaderDPoP = "DPoP"
+)
+
+// HTTP Headers - Gateway Response Cache
+// Ustawiane przez upstream, zdejmowane przez gateway przed wysłaniem odpowiedzi.
+const (
+	// HeaderCacheTag – tagi wpisu w cache gatewaya (lista po przecinku), do unieważniania
+	HeaderCacheTag = "Cache-Tag"
+)
+
+// HTTP Headers - Idempotency (RFC draft "The Idempotency-Key HTTP Header Field")
+// Obsługiwane przez gateway na trasach z `idempotency:` w routes.yaml.
+const (
+	HeaderIdempotencyKey = "Idempotency-Key"
+	// HeaderIdempotentReplayed – odpowiedź odtworzona z zapisu, żądanie nie trafiło do upstreamu
+	HeaderIdempotentReplayed = "Idempotent-Replayed"
+)
+
+// HTTP Headers - API Versioning (gateway)
+// Wersja API z nagłówka (alternatywa dla prefiksu ścieżki /v1, /v2) i cykl życia wersji.
+const (
+	HeaderAPIVersion = "API-Version"
+	// HeaderAppVersion – wersja aplikacji klienta, porównywana z polityką version-service
+	HeaderAppVersion = "X-App-Version"
+	// HeaderDeprecation – RFC 9745 (@<unix timestamp>)
+	HeaderDeprecation = "Deprecation"
+	// HeaderSunset – RFC 8594 (HTTP-date)
+	HeaderSunset = "Sunset"
+)
+
+// HTTP Headers - Canary (gateway)
+const (
+	// HeaderCanary – wybór wariantu przez testera: canary | stable
+	HeaderCanary = "X-Canary"
+	// HeaderUpstreamVariant – wariant, który obsłużył żądanie (tylko poza produkcją)
+	HeaderUpstreamVariant = "X-Upstream-Variant"
+)
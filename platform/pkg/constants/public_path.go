@@ -1,13 +0,0 @@
-package constants
-
-var PublicPaths = []string{
-	"/auth/login",
-	"/auth/register",
-	"/auth/refresh",
-	"/auth/2fa-verify",
-	"/auth/2fa-resend",
-	"/auth/reset/send",
-	"/auth/reset/verify",
-	"/auth/reset/final",
-	"/health",
-}
@@ -3,6 +3,9 @@ package constants
 const (
 	StreamNotification = "notification_stream"
 	StreamUserUpdates  = "user_updates"
+	StreamAudit        = "audit_stream"
+	StreamCachePurge   = "cache_purge_stream"
 
 	GroupPushNotifiers = "push_notifier_group"
+	GroupGatewayCache  = "gateway_cache_group"
 )
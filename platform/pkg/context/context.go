@@ -8,6 +8,7 @@ type RequestContext struct {
 	SessionID string
 	DeviceID  string
 	IP        string
+	Country   string // ISO 3166-1 alpha-2 z bazy GeoIP gatewaya ("" – nieznany)
 	Roles     []string
 	RiskScore int
 	Challenge string
@@ -0,0 +1,119 @@
+package context
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Envelope – podpisana koperta nagłówka X-Internal-Context: kontekst żądania z czasem
+// wystawienia i ważności, serwisem docelowym, nonce żądania i ID klucza HMAC
+type Envelope struct {
+	Context   RequestContext `json:"ctx"`
+	KeyID     string         `json:"kid"`
+	Audience  string         `json:"aud"`
+	IssuedAt  int64          `json:"iat"` // unix ms
+	ExpiresAt int64          `json:"exp"` // unix ms
+	Nonce     string         `json:"nonce"`
+}
+
+var (
+	ErrUnknownKey       = errors.New("unknown internal key id")
+	ErrInvalidSignature = errors.New("invalid internal context signature")
+	ErrContextExpired   = errors.New("internal context expired")
+	ErrWrongAudience    = errors.New("internal context issued for another service")
+)
+
+const minKeyLength = 32
+
+// Keyring – klucze HMAC kontekstu wewnętrznego. Podpisuje zawsze klucz bieżący, a weryfikuje
+// każdy znany – dzięki temu klucz można wymienić bez przerwy: najpierw serwisy akceptują
+// nowy klucz, potem gateway zaczyna nim podpisywać, na końcu stary znika z listy.
+type Keyring struct {
+	signingKID string
+	keys       map[string][]byte
+}
+
+// NewKeyring – bieżący klucz (kid, sekret) i dodatkowe klucze akceptowane przy weryfikacji
+// w postaci "kid:sekret"
+func NewKeyring(kid string, secret []byte, accepted []string) (*Keyring, error) {
+	if kid == "" || len(secret) < minKeyLength {
+		return nil, fmt.Errorf("internal key %q: id required and secret must be at least %d bytes", kid, minKeyLength)
+	}
+	k := &Keyring{signingKID: kid, keys: map[string][]byte{kid: secret}}
+	for _, entry := range accepted {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		id, key, ok := strings.Cut(entry, ":")
+		if !ok || id == "" || len(key) < minKeyLength {
+			return nil, fmt.Errorf("invalid accepted internal key %q (expected kid:secret, secret min %d bytes)", id, minKeyLength)
+		}
+		if _, dup := k.keys[id]; dup {
+			return nil, fmt.Errorf("duplicate internal key id %q", id)
+		}
+		k.keys[id] = []byte(key)
+	}
+	return k, nil
+}
+
+// Seal podpisuje kontekst dla serwisu audience; koperta jest ważna przez ttl
+func (k *Keyring) Seal(ctx RequestContext, audience string, ttl time.Duration) (payload []byte, signature string, err error) {
+	now := time.Now()
+	payload, err = json.Marshal(Envelope{
+		Context:   ctx,
+		KeyID:     k.signingKID,
+		Audience:  audience,
+		IssuedAt:  now.UnixMilli(),
+		ExpiresAt: now.Add(ttl).UnixMilli(),
+		Nonce:     uuid.NewString(),
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, Sign(payload, k.keys[k.signingKID]), nil
+}
+
+// Open sprawdza podpis kluczem wskazanym przez kid i zwraca kopertę.
+// Ważność i odbiorcę sprawdza Envelope.Check.
+func (k *Keyring) Open(payload []byte, signature string) (*Envelope, error) {
+	var env Envelope
+	if err := json.Unmarshal(payload, &env); err != nil {
+		return nil, ErrInvalidContext
+	}
+	key, ok := k.keys[env.KeyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+	if !Verify(payload, signature, key) {
+		return nil, ErrInvalidSignature
+	}
+	return &env, nil
+}
+
+// Check – koperta ważna w chwili now (z tolerancją rozjazdu zegarów skew) i wystawiona
+// dla jednej z nazw serwisu (pusta lista – odbiorca nie jest sprawdzany)
+func (e *Envelope) Check(now time.Time, audiences []string, skew time.Duration) error {
+	if e.Nonce == "" || e.ExpiresAt <= e.IssuedAt {
+		return ErrInvalidContext
+	}
+	ms := now.UnixMilli()
+	if ms > e.ExpiresAt+skew.Milliseconds() || ms < e.IssuedAt-skew.Milliseconds() {
+		return ErrContextExpired
+	}
+	if len(audiences) > 0 && !slices.Contains(audiences, e.Audience) {
+		return ErrWrongAudience
+	}
+	return nil
+}
+
+// Expiry – koniec ważności koperty
+func (e *Envelope) Expiry() time.Time {
+	return time.UnixMilli(e.ExpiresAt)
+}
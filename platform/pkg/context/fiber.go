@@ -9,3 +9,15 @@ func MustFromFiber(c *fiber.Ctx) *RequestContext {
 	}
 	return ctx
 }
+
+// ClientIP zwraca adres klienta: z RequestContext, z middleware ClientIP (zaufane proxy),
+// a w ostateczności adres połączenia wg konfiguracji Fiber
+func ClientIP(c *fiber.Ctx) string {
+	if ctx, ok := c.Locals(FiberRequestContextKey).(*RequestContext); ok && ctx != nil && ctx.IP != "" {
+		return ctx.IP
+	}
+	if ip, ok := c.Locals(FiberClientIPKey).(string); ok && ip != "" {
+		return ip
+	}
+	return c.IP()
+}
@@ -1,3 +1,6 @@
 package context
 
 const FiberRequestContextKey = "requestContext"
+
+// FiberClientIPKey – adres klienta ustalony przez middleware ClientIP (przed RequestContext)
+const FiberClientIPKey = "clientIP"
@@ -6,23 +6,40 @@ import "maps"
 type ErrorType string
 
 const (
-	Unauthorized ErrorType = "UNAUTHORIZED"
-	Validation   ErrorType = "VALIDATION"
-	NotFound     ErrorType = "NOT_FOUND"
-	Internal     ErrorType = "INTERNAL"
-	BadRequest   ErrorType = "BAD_REQUEST"
-	Timeout      ErrorType = "TIMEOUT"
-	Conflict     ErrorType = "CONFLICT"
+	Unauthorized  ErrorType = "UNAUTHORIZED"
+	Forbidden     ErrorType = "FORBIDDEN"
+	Validation    ErrorType = "VALIDATION"
+	NotFound      ErrorType = "NOT_FOUND"
+	Internal      ErrorType = "INTERNAL"
+	BadRequest    ErrorType = "BAD_REQUEST"
+	Timeout       ErrorType = "TIMEOUT"
+	Conflict      ErrorType = "CONFLICT"
+	TooLarge      ErrorType = "TOO_LARGE"
+	Unprocessable ErrorType = "UNPROCESSABLE"
+	Gone          ErrorType = "GONE"
+	// UpgradeRequired – klient (aplikacja) musi zostać zaktualizowany
+	UpgradeRequired ErrorType = "UPGRADE_REQUIRED"
+	// Unavailable – usługa celowo wyłączona (np. prace serwisowe), klient ponawia później
+	Unavailable ErrorType = "UNAVAILABLE"
+	// BadGateway – upstream odpowiedział, ale odpowiedź nie nadaje się do przekazania klientowi
+	BadGateway ErrorType = "BAD_GATEWAY"
 )
 
 // Domyślne komunikaty dla typów błędów
 var ErrorMessages = map[ErrorType]string{
-	Unauthorized: "Brak autoryzacji.",
-	Validation:   "Nieprawidłowe dane.",
-	NotFound:     "Zasób nie został znaleziony.",
-	Internal:     "Wewnętrzny błąd serwera.",
-	BadRequest:   "Błędne żądanie.",
-	Timeout:      "Przekroczono czas oczekiwania.",
+	Unauthorized:    "Brak autoryzacji.",
+	Forbidden:       "Brak uprawnień.",
+	Validation:      "Nieprawidłowe dane.",
+	NotFound:        "Zasób nie został znaleziony.",
+	Internal:        "Wewnętrzny błąd serwera.",
+	BadRequest:      "Błędne żądanie.",
+	Timeout:         "Przekroczono czas oczekiwania.",
+	TooLarge:        "Treść żądania jest zbyt duża.",
+	Unprocessable:   "Żądanie nie może zostać przetworzone.",
+	Gone:            "Zasób nie jest już dostępny.",
+	UpgradeRequired: "Wymagana aktualizacja aplikacji.",
+	Unavailable:     "Usługa jest chwilowo niedostępna.",
+	BadGateway:      "Nieprawidłowa odpowiedź usługi.",
 }
 
 // AppError to baza dla wszystkich błędów serwisów
@@ -61,6 +78,13 @@ func (e AppError) WithMeta(key string, value any) *AppError {
 	}
 }
 
+// WithMessage – kopia błędu z innym komunikatem (np. komunikat okna serwisowego)
+func (e AppError) WithMessage(msg string) *AppError {
+	e.Meta = maps.Clone(e.Meta)
+	e.Message = msg
+	return &e
+}
+
 func newErr(code string, errType ErrorType, msg string) *AppError {
 	return &AppError{Code: code, Type: errType, Message: msg}
 }
@@ -76,18 +100,26 @@ var (
 	ErrTooManyRequests           = newErr("TOO_MANY_REQUESTS", BadRequest, "Too many requests")
 	ErrUnauthorized              = newErr("UNAUTHORIZED", Unauthorized, "Unauthorized access")
 	ErrInvalidToken              = newErr("INVALID_TOKEN", Unauthorized, "Invalid token")
+	ErrForbidden                 = newErr("FORBIDDEN", Forbidden, "Insufficient permissions")
 	ErrGatewayTimeout            = newErr("GATEWAY_TIMEOUT", Timeout, "Usługa nie odpowiedziała w wymaganym czasie.")
 	ErrUpstreamUnavailable       = newErr("UPSTREAM_UNAVAILABLE", Internal, "Usługa zewnętrzna jest niedostępna.")
 	ErrInvalidDeviceFingerprint  = newErr("INVALID_FINGERPRINT", BadRequest, "Identification failed: Missing device fingerprint")
 	ErrUpstreamTimeout           = newErr("UPSTREAM_TIMEOUT", Timeout, "Upstream service timeout")
 	ErrUpstreamUnreachable       = newErr("UPSTREAM_UNREACHABLE", Internal, "Upstream service unreachable")
+	ErrUpstreamContextRejected   = newErr("UPSTREAM_CONTEXT_REJECTED", BadGateway, "Usługa odrzuciła kontekst żądania bramy.")
 	ErrInternalContextEncoding   = newErr("INTERNAL_CONTEXT_ENCODING", Unauthorized, "Błąd kodowania kontekstu wewnętrznego.")
 	ErrInternalInvalidSignature  = newErr("INTERNAL_INVALID_SIGNATURE", Unauthorized, "Nieprawidłowa sygnatura wewnętrzna.")
 	ErrInternalContextCorruption = newErr("INTERNAL_CONTEXT_CORRUPTION", Unauthorized, "Uszkodzony kontekst wewnętrzny.")
+	ErrInternalContextRequired   = newErr("INTERNAL_CONTEXT_REQUIRED", Unauthorized, "Wymagany podpisany kontekst wewnętrzny.")
+	ErrInternalContextExpired    = newErr("INTERNAL_CONTEXT_EXPIRED", Unauthorized, "Kontekst wewnętrzny wygasł.")
+	ErrInternalContextAudience   = newErr("INTERNAL_CONTEXT_AUDIENCE", Unauthorized, "Kontekst wewnętrzny wystawiono dla innego serwisu.")
+	ErrInternalContextReplayed   = newErr("INTERNAL_CONTEXT_REPLAYED", Unauthorized, "Kontekst wewnętrzny został już użyty.")
 	ErrInvalidRequestBody        = newErr("INVALID_REQUEST_BODY", BadRequest, "Nieprawidłowy format treści żądania.")
 	ErrInvalidSession            = newErr("INVALID_SESSION", Unauthorized, "Nieprawidłowa lub niekompletna sesja urządzenia.")
 	ErrInvalidChallenge          = newErr("INVALID_CHALLENGE", Unauthorized, "Challenge wygasł lub jest nieprawidłowy.")
 	ErrAccountTemporarilyLocked  = newErr("ACCOUNT_TEMPORARILY_LOCKED", Unauthorized, "Konto tymczasowo zablokowane. Spróbuj ponownie za 15 minut.")
+	ErrPayloadTooLarge           = newErr("PAYLOAD_TOO_LARGE", TooLarge, "Treść żądania przekracza dopuszczalny rozmiar.")
+	ErrUpstreamContractViolation = newErr("UPSTREAM_CONTRACT_VIOLATION", Internal, "Odpowiedź usługi jest niezgodna ze specyfikacją API.")
 )
 
 // --- Błędy specyficzne dla auth ---
@@ -99,6 +131,7 @@ var (
 	ErrPasswordTooShort    = newErr("PASSWORD_TOO_SHORT", Validation, "Password must be at least 8 characters")
 	ErrCSRFInvalid         = newErr("CSRF_INVALID", Unauthorized, "CSRF token invalid or missing")
 	ErrInvalidCredentials  = newErr("INVALID_CREDENTIALS", Unauthorized, "Incorrect login data")
+	ErrPasswordMismatch    = newErr("PASSWORD_MISMATCH", Forbidden, "Nieprawidłowe hasło.") // potwierdzenie w trakcie sesji – 401 wylogowałoby aplikację
 	ErrUserNotFound        = newErr("USER_NOT_FOUND", Unauthorized, "User not found")
 	ErrEmailIsSendIfExists = newErr("EMAIL_IS_SEND_IF_EXISTS", Validation, "If the account exists, a reset code has been sent.")
 	ErrAccountLocked       = newErr("ACCOUNT_LOCKED", Unauthorized, "Account locked due to too many failed login attempts")
@@ -109,6 +142,9 @@ var (
 	ErrAccountSuspended    = newErr("ACCOUNT_SUSPENDED", Unauthorized, "Konto użytkownika jest tymczasowo zawieszone.")
 	ErrAccountBanned       = newErr("ACCOUNT_BANNED", Unauthorized, "Konto użytkownika zostało zablokowane.")
 	ErrAccountPending      = newErr("ACCOUNT_PENDING", Unauthorized, "Konto użytkownika oczekuje na weryfikację.")
+	ErrInvalidClient       = newErr("INVALID_CLIENT", Unauthorized, "Client authentication failed")
+	ErrClientNotAllowed    = newErr("UNAUTHORIZED_CLIENT", Unauthorized, "Client is not allowed to perform this operation")
+	ErrBotChallengeFailed  = newErr("BOT_CHALLENGE_FAILED", Unauthorized, "Weryfikacja anty-botowa nie powiodła się.")
 )
 
 // --- Dodatkowe błędy ---
@@ -117,4 +153,45 @@ var (
 	ErrInvalidResetCode     = newErr("INVALID_RESET_CODE", Validation, "Nieprawidłowy kod resetujący.")
 	ErrUntrustedDevice      = newErr("UNTRUSTED_DEVICE", Unauthorized, "To urządzenie nie jest zaufane.")
 	ErrInvalidSignature     = newErr("INVALID_SIGNATURE", Unauthorized, "Nieprawidłowy podpis bezpieczeństwa.")
+	ErrInvalidRecoveryCode  = newErr("INVALID_RECOVERY_CODE", Unauthorized, "Nieprawidłowy lub wykorzystany kod odzyskiwania.")
+	ErrDeviceProofRequired  = newErr("DEVICE_PROOF_REQUIRED", Unauthorized, "Żądanie wymaga dowodu klucza urządzenia (DPoP).")
+	ErrInvalidDeviceProof   = newErr("INVALID_DEVICE_PROOF", Unauthorized, "Nieprawidłowy dowód klucza urządzenia.")
+)
+
+// --- Błędy panelu administracyjnego ---
+var (
+	ErrJustificationRequired = newErr("JUSTIFICATION_REQUIRED", Validation, "Uzasadnienie działania administracyjnego jest wymagane.")
+	ErrPasswordResetRequired = newErr("PASSWORD_RESET_REQUIRED", Unauthorized, "Wymagana jest zmiana hasła. Skorzystaj z resetu hasła.")
+	ErrAdminTargetRole       = newErr("ADMIN_TARGET_ROLE", Forbidden, "Nie można modyfikować konta z równą lub wyższą rolą.")
+)
+
+// --- Błędy Idempotency-Key (gateway) ---
+var (
+	ErrIdempotencyKeyRequired = newErr("IDEMPOTENCY_KEY_REQUIRED", BadRequest, "Nagłówek Idempotency-Key jest wymagany.")
+	ErrIdempotencyKeyInvalid  = newErr("IDEMPOTENCY_KEY_INVALID", BadRequest, "Nieprawidłowy nagłówek Idempotency-Key.")
+	ErrIdempotencyKeyReused   = newErr("IDEMPOTENCY_KEY_REUSED", Unprocessable, "Klucz Idempotency-Key został użyty dla innego żądania.")
+	ErrIdempotencyInProgress  = newErr("IDEMPOTENCY_IN_PROGRESS", Conflict, "Żądanie z tym kluczem Idempotency-Key jest w trakcie przetwarzania.")
+	ErrIdempotencyNoReplay    = newErr("IDEMPOTENCY_NO_REPLAY", Conflict, "Żądanie zostało już wykonane, ale jego odpowiedź nie jest dostępna.")
+)
+
+// --- Błędy wersji API i aplikacji (gateway) ---
+var (
+	ErrAPIVersionUnsupported = newErr("API_VERSION_UNSUPPORTED", BadRequest, "Nieobsługiwana wersja API.")
+	ErrAPIVersionSunset      = newErr("API_VERSION_SUNSET", Gone, "Ta wersja API została wycofana. Zaktualizuj aplikację.")
+	ErrAppUpdateRequired     = newErr("APP_UPDATE_REQUIRED", UpgradeRequired, "Ta wersja aplikacji nie jest już obsługiwana. Zaktualizuj aplikację.")
+)
+
+// --- Błędy filtrowania sieci (gateway) ---
+var (
+	ErrIPBlocked  = newErr("IP_BLOCKED", Forbidden, "Dostęp z tego adresu jest zablokowany.")
+	ErrIPBanned   = newErr("IP_TEMPORARILY_BANNED", Forbidden, "Adres został tymczasowo zablokowany. Spróbuj ponownie później.")
+	ErrGeoBlocked = newErr("GEO_BLOCKED", Forbidden, "Usługa nie jest dostępna w Twoim kraju.")
+)
+
+// --- Błędy admin API i trybu serwisowego (gateway) ---
+var (
+	ErrMaintenance     = newErr("MAINTENANCE", Unavailable, "Trwają prace serwisowe. Spróbuj ponownie później.")
+	ErrUnknownUpstream = newErr("UNKNOWN_UPSTREAM", NotFound, "Nieznany upstream lub instancja.")
+	ErrUnknownRoute    = newErr("UNKNOWN_ROUTE", NotFound, "Nieznana trasa.")
+	ErrUnknownWindow   = newErr("UNKNOWN_MAINTENANCE_WINDOW", NotFound, "Nieznane okno serwisowe (okna z routes.yaml zmienia się w pliku).")
 )
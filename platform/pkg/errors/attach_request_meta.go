@@ -4,6 +4,7 @@ import (
 	"maps"
 
 	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 )
 
 func AttachRequestMeta(c *fiber.Ctx, err *AppError, keysToInclude ...string) {
@@ -20,7 +21,7 @@ func AttachRequestMeta(c *fiber.Ctx, err *AppError, keysToInclude ...string) {
 				newMeta["requestID"] = val
 			}
 		case "ip":
-			newMeta["ip"] = c.IP()
+			newMeta["ip"] = reqctx.ClientIP(c)
 		case "method":
 			newMeta["method"] = c.Method()
 		case "path":
@@ -21,21 +21,8 @@ func SendAppError(c *fiber.Ctx, err error) error {
 		c.Context().Logger().Printf("[WARN] %s: %v", appErr.Type, appErr.Message)
 	}
 
-	// 3. Mapowanie typów na statusy HTTP
-	statusMap := map[ErrorType]int{
-		Validation:   fiber.StatusBadRequest,
-		Unauthorized: fiber.StatusUnauthorized,
-		NotFound:     fiber.StatusNotFound,
-		Internal:     fiber.StatusInternalServerError,
-		BadRequest:   fiber.StatusBadRequest,
-		Timeout:      fiber.StatusGatewayTimeout,
-		Conflict:     fiber.StatusConflict,
-	}
-
-	status, exists := statusMap[appErr.Type]
-	if !exists {
-		status = fiber.StatusInternalServerError
-	}
+	// 3. Mapowanie typu na status HTTP
+	status := HTTPStatus(appErr)
 
 	// 4. Budowa odpowiedzi
 	response := fiber.Map{
@@ -49,3 +36,28 @@ func SendAppError(c *fiber.Ctx, err error) error {
 
 	return c.Status(status).JSON(response)
 }
+
+// HTTPStatus zwraca status HTTP odpowiadający typowi błędu
+func HTTPStatus(appErr *AppError) int {
+	statusMap := map[ErrorType]int{
+		Validation:      fiber.StatusBadRequest,
+		Unauthorized:    fiber.StatusUnauthorized,
+		Forbidden:       fiber.StatusForbidden,
+		NotFound:        fiber.StatusNotFound,
+		Internal:        fiber.StatusInternalServerError,
+		BadRequest:      fiber.StatusBadRequest,
+		Timeout:         fiber.StatusGatewayTimeout,
+		Conflict:        fiber.StatusConflict,
+		TooLarge:        fiber.StatusRequestEntityTooLarge,
+		Unprocessable:   fiber.StatusUnprocessableEntity,
+		Gone:            fiber.StatusGone,
+		UpgradeRequired: fiber.StatusUpgradeRequired,
+		Unavailable:     fiber.StatusServiceUnavailable,
+		BadGateway:      fiber.StatusBadGateway,
+	}
+
+	if status, ok := statusMap[appErr.Type]; ok {
+		return status
+	}
+	return fiber.StatusInternalServerError
+}
@@ -0,0 +1,53 @@
+package events
+
+import (
+	"context"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/constants"
+)
+
+// AuditRecord – format wiadomości konsumowanej przez audit-service (audit_stream).
+// UserID musi być poprawnym UUID – inaczej audit-service odrzuci cały batch.
+type AuditRecord struct {
+	UserID    string         `json:"user_id"`
+	Service   string         `json:"service"`
+	Action    string         `json:"action"`
+	IP        string         `json:"ip"`
+	Timestamp time.Time      `json:"timestamp"`
+	Metadata  map[string]any `json:"metadata,omitempty"`
+}
+
+// AuditIPBanned – tymczasowa blokada adresu IP po przekroczeniu progu reputacji
+// (gateway i auth-service; UserID = uuid.Nil, gdy przewinienie nie ma użytkownika)
+const AuditIPBanned = "IP_BANNED"
+
+// AuditPublisher zapisuje wpisy audytowe bezpośrednio do audit_stream
+type AuditPublisher struct {
+	publisher StreamPublisher
+	service   string
+}
+
+func NewAuditPublisher(publisher StreamPublisher, service string) *AuditPublisher {
+	return &AuditPublisher{
+		publisher: publisher,
+		service:   service,
+	}
+}
+
+func (a *AuditPublisher) Record(
+	ctx context.Context,
+	userID string,
+	action string,
+	ip string,
+	metadata map[string]any,
+) error {
+	return a.publisher.Publish(ctx, constants.StreamAudit, AuditRecord{
+		UserID:    userID,
+		Service:   a.service,
+		Action:    action,
+		IP:        ip,
+		Timestamp: time.Now().UTC(),
+		Metadata:  metadata,
+	})
+}
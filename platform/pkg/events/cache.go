@@ -0,0 +1,34 @@
+package events
+
+import (
+	"context"
+
+	"github.com/zerodayz7/platform/pkg/constants"
+)
+
+// CachePurge – komunikat unieważniający wpisy cache odpowiedzi gatewaya (cache_purge_stream).
+// Z UserID usuwa tylko wpisy tego użytkownika, bez – wpisy z tagiem wszystkich użytkowników.
+type CachePurge struct {
+	UserID string   `json:"user_id,omitempty"`
+	Tags   []string `json:"tags"`
+}
+
+// CachePurger publikuje unieważnienia po zapisach (np. nowe powiadomienie => "notifications")
+type CachePurger struct {
+	publisher StreamPublisher
+}
+
+func NewCachePurger(publisher StreamPublisher) *CachePurger {
+	return &CachePurger{publisher: publisher}
+}
+
+// Purge unieważnia tagi użytkownika; pusty userID oznacza wszystkich użytkowników
+func (p *CachePurger) Purge(ctx context.Context, userID string, tags ...string) error {
+	if len(tags) == 0 {
+		return nil
+	}
+	return p.publisher.Publish(ctx, constants.StreamCachePurge, CachePurge{
+		UserID: userID,
+		Tags:   tags,
+	})
+}
@@ -12,6 +12,10 @@ const (
 	LoginFailed      EventType = "LOGIN_FAILED"
 	Logout           EventType = "LOGOUT"
 
+	// Recovery
+	RecoveryCodeUsed         EventType = "RECOVERY_CODE_USED"
+	RecoveryCodesRegenerated EventType = "RECOVERY_CODES_REGENERATED"
+
 	// Account
 	PasswordChanged EventType = "PASSWORD_CHANGED"
 	EmailChanged    EventType = "EMAIL_CHANGED"
@@ -0,0 +1,140 @@
+package middleware
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+)
+
+// TrustedProxies ustala adres klienta za łańcuchem proxy (ingress, load balancer).
+// Nagłówek jest czytany od prawej: adresy zaufanych proxy są pomijane, a pierwszy
+// niezaufany to klient – wpisów dopisanych przez samego klienta nie da się tak podrobić.
+type TrustedProxies struct {
+	prefixes []netip.Prefix
+	header   string
+}
+
+// NewTrustedProxies – sieci proxy (CIDR lub pojedyncze adresy) i nagłówek, który ustawiają:
+// X-Forwarded-For albo Forwarded (RFC 7239)
+func NewTrustedProxies(values []string, header string) (*TrustedProxies, error) {
+	if header != constants.HeaderXForwardedFor && header != constants.HeaderForwarded {
+		return nil, fmt.Errorf("unsupported proxy header %q", header)
+	}
+
+	t := &TrustedProxies{header: header}
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", v)
+			}
+			addr = addr.Unmap()
+			t.prefixes = append(t.prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q", v)
+		}
+		t.prefixes = append(t.prefixes, p.Masked())
+	}
+	return t, nil
+}
+
+// Trusted – czy adres należy do zaufanego proxy
+func (t *TrustedProxies) Trusted(addr netip.Addr) bool {
+	return slices.ContainsFunc(t.prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+}
+
+// Resolve zwraca adres klienta dla połączenia z remote i wartości nagłówka proxy
+// (wszystkie wystąpienia, w kolejności). Połączenie spoza zaufanych sieci to klient.
+func (t *TrustedProxies) Resolve(remote netip.Addr, values []string) netip.Addr {
+	remote = remote.Unmap()
+	if !t.Trusted(remote) {
+		return remote
+	}
+
+	var hops []string
+	for _, v := range values {
+		if t.header == constants.HeaderForwarded {
+			hops = append(hops, forwardedFor(v)...)
+		} else {
+			hops = append(hops, strings.Split(v, ",")...)
+		}
+	}
+
+	client := remote
+	for i := len(hops) - 1; i >= 0; i-- {
+		addr, ok := parseHop(hops[i])
+		if !ok {
+			// "unknown", ukryty identyfikator albo śmieci – dalej nie wiemy nic pewnego,
+			// więc klientem jest ostatnie proxy, które to dopisało
+			return client
+		}
+		client = addr
+		if !t.Trusted(addr) {
+			return addr
+		}
+	}
+	// Same zaufane adresy – żądanie z wnętrza sieci
+	return client
+}
+
+// Middleware zapisuje ustalony adres w Locals; odczyt przez reqctx.ClientIP
+// (gateway przenosi go do RequestContext.IP). Musi działać przed limiterem i logiem żądania.
+func (t *TrustedProxies) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		remote, ok := netip.AddrFromSlice(c.Context().RemoteIP())
+		if !ok {
+			return c.Next()
+		}
+
+		var values []string
+		for _, v := range c.Request().Header.PeekAll(t.header) {
+			values = append(values, string(v))
+		}
+		c.Locals(reqctx.FiberClientIPKey, t.Resolve(remote, values).String())
+		return c.Next()
+	}
+}
+
+// forwardedFor zwraca wartości for= kolejnych elementów nagłówka Forwarded
+// (for=192.0.2.60;proto=https, for="[2001:db8::1]:4711"). Element bez for= to "".
+func forwardedFor(header string) []string {
+	var out []string
+	for element := range strings.SplitSeq(header, ",") {
+		node := ""
+		for pair := range strings.SplitSeq(element, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if ok && strings.EqualFold(key, "for") {
+				node = strings.Trim(value, `"`)
+			}
+		}
+		out = append(out, node)
+	}
+	return out
+}
+
+// parseHop – adres z wpisu nagłówka, także z portem ("192.0.2.1:443", "[2001:db8::1]:4711")
+func parseHop(hop string) (netip.Addr, bool) {
+	hop = strings.TrimSpace(hop)
+	if addr, err := netip.ParseAddr(strings.Trim(hop, "[]")); err == nil {
+		return addr.Unmap(), true
+	}
+	if host, _, err := net.SplitHostPort(hop); err == nil {
+		if addr, err := netip.ParseAddr(host); err == nil {
+			return addr.Unmap(), true
+		}
+	}
+	return netip.Addr{}, false
+}
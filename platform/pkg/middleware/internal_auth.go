@@ -1,7 +1,11 @@
 package middleware
 
 import (
+	"context"
 	"encoding/base64"
+	"errors"
+	"slices"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/zerodayz7/platform/pkg/constants"
@@ -10,13 +14,43 @@ import (
 	"github.com/zerodayz7/platform/pkg/shared"
 )
 
-func InternalAuthMiddleware(hmacSecret []byte) fiber.Handler {
+// Tolerancja rozjazdu zegarów gatewaya i serwisu
+const internalClockSkew = 5 * time.Second
+
+// Sondy gatewaya (health check, dokumenty OpenAPI) przychodzą bez kontekstu także w trybie strict
+var unsignedPaths = []string{"/health", "/openapi.json"}
+
+// Metody, których gateway nie ponawia – dla nich powtórzony nonce oznacza powtórzone żądanie.
+// Ponowienia GET / PUT / DELETE niosą te same nagłówki, więc tam nonce nie jest sprawdzany.
+var nonRetriedMethods = []string{fiber.MethodPost, fiber.MethodPatch}
+
+// NonceStore zapisuje nonce kopert kontekstu (redis.Cache)
+type NonceStore interface {
+	ClaimInternalNonce(ctx context.Context, nonce string, ttl time.Duration) (bool, error)
+}
+
+// InternalAuthConfig – weryfikacja kontekstu podpisanego przez gateway
+type InternalAuthConfig struct {
+	Keys *reqctx.Keyring
+	// Audience – nazwy serwisu w gatewayu (INTERNAL_AUDIENCE); pusta lista = bez sprawdzania
+	Audience []string
+	// Strict – żądania bez kontekstu są odrzucane (INTERNAL_STRICT)
+	Strict bool
+	// Nonces – nil wyłącza wykrywanie powtórzeń
+	Nonces NonceStore
+}
+
+func InternalAuthMiddleware(cfg InternalAuthConfig) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log := shared.GetLogger()
 		encodedCtx := c.Get(constants.HeaderInternalContext)
 		signature := c.Get(constants.HeaderInternalSignature)
 
 		if encodedCtx == "" || signature == "" {
+			if cfg.Strict && !slices.Contains(unsignedPaths, c.Path()) {
+				log.WarnMap("Unsigned request rejected", map[string]any{"path": c.Path(), "method": c.Method()})
+				return apperr.SendAppError(c, apperr.ErrInternalContextRequired)
+			}
 			return c.Next()
 		}
 
@@ -26,25 +60,55 @@ func InternalAuthMiddleware(hmacSecret []byte) fiber.Handler {
 			return apperr.SendAppError(c, apperr.ErrInternalContextEncoding)
 		}
 
-		// 3. Weryfikuj podpis
-		if !reqctx.Verify(payload, signature, hmacSecret) {
-			return apperr.SendAppError(c, apperr.ErrInternalInvalidSignature)
-		}
-
-		// 4. Deserializuj do struktury
-		ctx, err := reqctx.Decode(payload)
-		if err != nil {
+		// 3. Weryfikuj podpis (klucz wg kid koperty)
+		env, err := cfg.Keys.Open(payload, signature)
+		switch {
+		case errors.Is(err, reqctx.ErrInvalidContext):
 			log.Error("Context decoding failed",
 				"error", err,
 				"raw_payload", base64.StdEncoding.EncodeToString(payload),
 			)
 			return apperr.SendAppError(c, apperr.ErrInternalContextCorruption)
+		case err != nil:
+			log.WarnMap("Internal context signature rejected", map[string]any{"error": err.Error(), "path": c.Path()})
+			return apperr.SendAppError(c, apperr.ErrInternalInvalidSignature)
+		}
+
+		// 4. Ważność, odbiorca i powtórzenie
+		if err := env.Check(time.Now(), cfg.Audience, internalClockSkew); err != nil {
+			log.WarnMap("Internal context rejected", map[string]any{
+				"error":    err.Error(),
+				"audience": env.Audience,
+				"kid":      env.KeyID,
+				"path":     c.Path(),
+			})
+			switch {
+			case errors.Is(err, reqctx.ErrContextExpired):
+				return apperr.SendAppError(c, apperr.ErrInternalContextExpired)
+			case errors.Is(err, reqctx.ErrWrongAudience):
+				return apperr.SendAppError(c, apperr.ErrInternalContextAudience)
+			}
+			return apperr.SendAppError(c, apperr.ErrInternalContextCorruption)
+		}
+
+		if cfg.Nonces != nil && slices.Contains(nonRetriedMethods, c.Method()) {
+			ttl := time.Until(env.Expiry()) + internalClockSkew
+			fresh, err := cfg.Nonces.ClaimInternalNonce(c.UserContext(), env.Nonce, ttl)
+			if err != nil {
+				log.ErrorObj("Internal context nonce not stored", err)
+				return apperr.SendAppError(c, apperr.ErrInternal)
+			}
+			if !fresh {
+				log.WarnMap("Internal context replayed", map[string]any{"nonce": env.Nonce, "path": c.Path()})
+				return apperr.SendAppError(c, apperr.ErrInternalContextReplayed)
+			}
 		}
 
 		// WYSYP CAŁOŚCI
-		log.DebugInfo("Context Dump", ctx)
+		log.DebugInfo("Context Dump", env.Context)
 		// 5. Wrzuć do Locals, żeby Handler go widział
-		c.Locals(reqctx.FiberRequestContextKey, ctx)
+		ctx := env.Context
+		c.Locals(reqctx.FiberRequestContextKey, &ctx)
 
 		return c.Next()
 	}
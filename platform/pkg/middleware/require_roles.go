@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+// RequireRoles przepuszcza żądanie tylko, jeśli RequestContext zawiera co najmniej jedną z ról.
+// Musi być użyty po InternalAuthMiddleware (lub ContextBuilder w gatewayu).
+func RequireRoles(roles ...string) fiber.Handler {
+	allowed := make([]string, len(roles))
+	for i, r := range roles {
+		allowed[i] = strings.ToUpper(r)
+	}
+
+	return func(c *fiber.Ctx) error {
+		rc, ok := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext)
+		if !ok || rc == nil || rc.UserID == nil {
+			return apperr.SendAppError(c, apperr.ErrUnauthorized)
+		}
+
+		if HasAnyRole(rc, allowed...) {
+			return c.Next()
+		}
+
+		shared.GetLogger().WarnMap("Access denied: missing role", map[string]any{
+			"uid":      rc.UserID,
+			"roles":    rc.Roles,
+			"required": allowed,
+			"path":     c.Path(),
+		})
+		return apperr.SendAppError(c, apperr.ErrForbidden)
+	}
+}
+
+// HasAnyRole sprawdza (bez rozróżniania wielkości liter), czy kontekst posiada którąś z ról
+func HasAnyRole(rc *reqctx.RequestContext, roles ...string) bool {
+	for _, r := range rc.Roles {
+		if slices.ContainsFunc(roles, func(want string) bool { return strings.EqualFold(want, r) }) {
+			return true
+		}
+	}
+	return false
+}
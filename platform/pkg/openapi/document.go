@@ -0,0 +1,119 @@
+package openapi
+
+// Minimalny model dokumentu OpenAPI 3.0 – tylko to, co generujemy z tras i pkg/schemas
+
+const Version = "3.0.3"
+
+// DocsPath – ścieżka, pod którą serwisy (i gateway) wystawiają swój dokument
+const DocsPath = "/openapi.json"
+
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components *Components         `json:"components,omitempty"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem – operacje ścieżki, klucz to metoda małymi literami (get, post, ...)
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Parameters  []*Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+	// Roles – wymagane role (co najmniej jedna), uzupełnia gateway na podstawie routes.yaml
+	Roles []string `json:"x-roles,omitempty"`
+}
+
+// Lokalizacje parametrów
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+)
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema,omitempty"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema,omitempty"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas,omitempty"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	Description  string `json:"description,omitempty"`
+}
+
+type Schema struct {
+	Ref         string             `json:"$ref,omitempty"`
+	Type        string             `json:"type,omitempty"`
+	Format      string             `json:"format,omitempty"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Enum        []any              `json:"enum,omitempty"`
+	Pattern     string             `json:"pattern,omitempty"`
+	MinLength   *int               `json:"minLength,omitempty"`
+	MaxLength   *int               `json:"maxLength,omitempty"`
+	MinItems    *int               `json:"minItems,omitempty"`
+	MaxItems    *int               `json:"maxItems,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Nullable    bool               `json:"nullable,omitempty"`
+}
+
+// SchemaRefPrefix – prefiks odwołań do schematów z components
+const SchemaRefPrefix = "#/components/schemas/"
+
+// SchemaRef – odwołanie do schematu z components
+func SchemaRef(name string) *Schema {
+	return &Schema{Ref: SchemaRefPrefix + name}
+}
+
+// ErrorSchemaName – kształt błędu zwracanego przez apperr.SendAppError
+const ErrorSchemaName = "AppError"
+
+func errorSchema() *Schema {
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"code":    {Type: "string"},
+			"message": {Type: "string"},
+			"meta":    {Type: "object", Description: "Error details, e.g. field -> validation message"},
+		},
+		Required: []string{"code", "message"},
+	}
+}
@@ -0,0 +1,257 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// SchemaOf buduje schemat body z tagów json i validate struktury (pkg/schemas)
+func SchemaOf(t reflect.Type) *Schema {
+	return typeSchema(t)
+}
+
+// parametersOf opisuje pola struktury z tagiem query:"..." lub params:"..." jako parametry
+func parametersOf(t reflect.Type, in string) []*Parameter {
+	tag := "query"
+	if in == InPath {
+		tag = "params"
+	}
+
+	var params []*Parameter
+	for _, f := range fieldsOf(t, tag) {
+		schema := typeSchema(f.field.Type)
+		required := applyRules(schema, f, nil)
+		params = append(params, &Parameter{
+			Name:     f.name,
+			In:       in,
+			Required: required || in == InPath,
+			Schema:   schema,
+		})
+	}
+	return params
+}
+
+func typeSchema(t reflect.Type) *Schema {
+	nullable := false
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+		nullable = true
+	}
+
+	var s *Schema
+	switch {
+	case t == timeType:
+		s = &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Struct:
+		s = objectSchema(t)
+	case (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) && t.Elem().Kind() == reflect.Uint8:
+		// encoding/json przesyła []byte jako base64
+		s = &Schema{Type: "string", Format: "byte"}
+	case t.Kind() == reflect.Slice || t.Kind() == reflect.Array:
+		s = &Schema{Type: "array", Items: typeSchema(t.Elem())}
+	case t.Kind() == reflect.Map:
+		s = &Schema{Type: "object"}
+	case t.Kind() == reflect.String:
+		s = &Schema{Type: "string"}
+	case t.Kind() == reflect.Bool:
+		s = &Schema{Type: "boolean"}
+	case t.Kind() >= reflect.Int && t.Kind() <= reflect.Uint64:
+		s = &Schema{Type: "integer"}
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		s = &Schema{Type: "number"}
+	default:
+		s = &Schema{}
+	}
+	s.Nullable = nullable
+	return s
+}
+
+func objectSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	fields := fieldsOf(t, "json")
+
+	// Nazwy Go -> JSON do opisu reguł warunkowych (required_with=ChallengeToken)
+	names := make(map[string]string, len(fields))
+	for _, f := range fields {
+		names[f.field.Name] = f.name
+	}
+
+	for _, f := range fields {
+		prop := typeSchema(f.field.Type)
+		if applyRules(prop, f, names) {
+			s.Required = append(s.Required, f.name)
+		}
+		s.Properties[f.name] = prop
+	}
+	return s
+}
+
+type structField struct {
+	field reflect.StructField
+	name  string
+}
+
+// fieldsOf – eksportowane pola z nazwą z podanego tagu (osadzone struktury są spłaszczane)
+func fieldsOf(t reflect.Type, tag string) []structField {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fields []structField
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Tag.Get(tag) == "" {
+			fields = append(fields, fieldsOf(f.Type, tag)...)
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+		switch {
+		case name == "-":
+			continue
+		case name == "" && tag != "json":
+			// Parametry bez tagu nie są wiązane przez QueryParser / ParamsParser po nazwie API
+			continue
+		case name == "":
+			name = f.Name
+		}
+		fields = append(fields, structField{field: f, name: name})
+	}
+	return fields
+}
+
+// applyRules przenosi reguły validate na schemat. Zwraca true dla pola wymaganego.
+// Reguły warunkowe (required_if / _with / _without) trafiają do opisu pola.
+func applyRules(s *Schema, f structField, names map[string]string) (required bool) {
+	tag := f.field.Tag.Get("validate")
+	if tag == "" || tag == "-" {
+		return false
+	}
+
+	target := s
+	var notes []string
+	for rule := range strings.SplitSeq(tag, ",") {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// Kolejne reguły dotyczą elementów tablicy
+			if target.Items == nil {
+				return required
+			}
+			target = target.Items
+		case "required":
+			if target == s {
+				required = true
+			}
+		case "min", "max", "len":
+			applyBound(target, name, param)
+		case "oneof":
+			for v := range strings.FieldsSeq(param) {
+				target.Enum = append(target.Enum, enumValue(target, v))
+			}
+		case "email":
+			target.Format = "email"
+		case "uuid", "uuid4", "uuid7":
+			target.Format = "uuid"
+		case "url", "uri":
+			target.Format = "uri"
+		case "alphanum":
+			target.Pattern = "^[a-zA-Z0-9]+$"
+		case "numeric", "numeric_byte":
+			if target.Format != "byte" {
+				target.Pattern = "^[0-9]+$"
+			} else {
+				notes = append(notes, "Only digits")
+			}
+		case "passwd":
+			notes = append(notes, "At least 8 characters with an uppercase and a lowercase letter, a digit and a special character")
+		case "required_with":
+			notes = append(notes, "Required together with "+fieldNames(param, names))
+		case "required_without":
+			notes = append(notes, "Required when "+fieldNames(param, names)+" is not provided")
+		case "required_if":
+			parts := strings.Fields(param)
+			if len(parts) >= 2 {
+				notes = append(notes, "Required when "+fieldNames(parts[0], names)+" is "+strings.Join(parts[1:], " "))
+			}
+		}
+	}
+
+	if len(notes) > 0 {
+		s.Description = strings.Join(notes, ". ")
+	}
+	return required
+}
+
+// applyBound – min/max/len oznaczają długość tekstu, liczbę elementów albo wartość liczby
+func applyBound(s *Schema, rule, param string) {
+	switch s.Type {
+	case "string":
+		n, err := strconv.Atoi(param)
+		if err != nil || s.Format == "byte" {
+			return
+		}
+		if rule != "max" {
+			s.MinLength = &n
+		}
+		if rule != "min" {
+			s.MaxLength = &n
+		}
+	case "array":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			return
+		}
+		if rule != "max" {
+			s.MinItems = &n
+		}
+		if rule != "min" {
+			s.MaxItems = &n
+		}
+	case "integer", "number":
+		v, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return
+		}
+		if rule != "max" {
+			s.Minimum = &v
+		}
+		if rule != "min" {
+			s.Maximum = &v
+		}
+	}
+}
+
+func enumValue(s *Schema, v string) any {
+	switch s.Type {
+	case "integer":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return n
+		}
+	case "number":
+		if n, err := strconv.ParseFloat(v, 64); err == nil {
+			return n
+		}
+	}
+	return v
+}
+
+func fieldNames(param string, names map[string]string) string {
+	fields := strings.Fields(param)
+	for i, f := range fields {
+		if name, ok := names[f]; ok {
+			fields[i] = name
+		}
+	}
+	return strings.Join(fields, ", ")
+}
@@ -0,0 +1,221 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Wejścia zadeklarowane przez middleware walidujące, czekające na rejestrację trasy.
+// Argumenty (np. middleware.ValidateBody[T]()) są wyliczane przed wywołaniem
+// group.Post(...), więc hook OnRoute przypisuje je właśnie rejestrowanej trasie.
+var pending struct {
+	sync.Mutex
+	inputs []input
+}
+
+type input struct {
+	in string // "body", InQuery albo InPath
+	t  reflect.Type
+}
+
+const inBody = "body"
+
+// ExpectBody – body trasy ma kształt T (wywoływane przez ValidateBody[T])
+func ExpectBody[T any]() { expect(inBody, reflect.TypeFor[T]()) }
+
+// ExpectQuery – parametry query trasy opisuje T (tag query:"...")
+func ExpectQuery[T any]() { expect(InQuery, reflect.TypeFor[T]()) }
+
+// ExpectParams – parametry ścieżki trasy opisuje T (tag params:"...")
+func ExpectParams[T any]() { expect(InPath, reflect.TypeFor[T]()) }
+
+func expect(in string, t reflect.Type) {
+	pending.Lock()
+	defer pending.Unlock()
+	pending.inputs = append(pending.inputs, input{in: in, t: t})
+}
+
+func takePending() []input {
+	pending.Lock()
+	defer pending.Unlock()
+	inputs := pending.inputs
+	pending.inputs = nil
+	return inputs
+}
+
+// Spec zbiera trasy aplikacji Fiber rejestrowane od New do RegisterRoutes i opisuje
+// je jako dokument OpenAPI. Trasy zdrowia (zarejestrowane wcześniej) i fallback
+// (później) nie trafiają do dokumentu.
+type Spec struct {
+	app    *fiber.App
+	info   Info
+	mu     sync.Mutex
+	inputs map[string][]input // "POST /auth/login" -> wejścia trasy
+	sealed bool
+}
+
+func New(app *fiber.App, info Info) *Spec {
+	s := &Spec{app: app, info: info, inputs: make(map[string][]input)}
+	app.Hooks().OnRoute(s.onRoute)
+	return s
+}
+
+func (s *Spec) onRoute(r fiber.Route) error {
+	// GET rejestruje też HEAD – wejścia należą do GET
+	if r.Method == fiber.MethodHead {
+		return nil
+	}
+	inputs := takePending()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.sealed {
+		return nil
+	}
+	key := r.Method + " " + normalizePath(r.Path)
+	s.inputs[key] = append(s.inputs[key], inputs...)
+	return nil
+}
+
+// RegisterRoutes wystawia GET /openapi.json i kończy zbieranie tras
+func (s *Spec) RegisterRoutes() {
+	s.mu.Lock()
+	s.sealed = true
+	s.mu.Unlock()
+
+	s.app.Get(DocsPath, func(c *fiber.Ctx) error {
+		return c.JSON(s.Document())
+	})
+}
+
+// Document buduje dokument z zebranych tras. Middleware (Use) są pomijane – GetRoutes
+// zwraca tylko trasy, a hook widzi również je.
+func (s *Spec) Document() *Document {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    s.info,
+		Paths:   make(map[string]PathItem),
+		Components: &Components{
+			Schemas: map[string]*Schema{ErrorSchemaName: errorSchema()},
+		},
+	}
+
+	for _, r := range s.app.GetRoutes(true) {
+		path := normalizePath(r.Path)
+		inputs, ok := s.inputs[r.Method+" "+path]
+		if !ok || r.Method == fiber.MethodHead {
+			continue
+		}
+
+		template, pathParams := templatePath(path)
+		op := newOperation(template, pathParams)
+		for _, in := range inputs {
+			describeInput(doc, op, in)
+		}
+
+		item := doc.Paths[template]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[template] = item
+		}
+		item[strings.ToLower(r.Method)] = op
+	}
+	return doc
+}
+
+func newOperation(template string, pathParams []string) *Operation {
+	op := &Operation{
+		Responses: map[string]*Response{
+			"2XX": {Description: "Success"},
+			"default": {
+				Description: "Error",
+				Content: map[string]MediaType{
+					fiber.MIMEApplicationJSON: {Schema: SchemaRef(ErrorSchemaName)},
+				},
+			},
+		},
+	}
+	if tag, _, _ := strings.Cut(strings.TrimPrefix(template, "/"), "/"); tag != "" {
+		op.Tags = []string{tag}
+	}
+	for _, name := range pathParams {
+		op.Parameters = append(op.Parameters, &Parameter{
+			Name:     name,
+			In:       InPath,
+			Required: true,
+			Schema:   &Schema{Type: "string"},
+		})
+	}
+	return op
+}
+
+func describeInput(doc *Document, op *Operation, in input) {
+	if in.in == inBody {
+		name := in.t.Name()
+		if name == "" {
+			op.RequestBody = jsonBody(SchemaOf(in.t))
+			return
+		}
+		doc.Components.Schemas[name] = SchemaOf(in.t)
+		op.RequestBody = jsonBody(SchemaRef(name))
+		return
+	}
+
+	// Opis ze struktury zastępuje domyślny parametr ścieżki (string bez reguł)
+	for _, p := range parametersOf(in.t, in.in) {
+		replaced := false
+		for i, existing := range op.Parameters {
+			if existing.In == p.In && existing.Name == p.Name {
+				op.Parameters[i] = p
+				replaced = true
+			}
+		}
+		if !replaced {
+			op.Parameters = append(op.Parameters, p)
+		}
+	}
+}
+
+func jsonBody(schema *Schema) *RequestBody {
+	return &RequestBody{
+		Required: true,
+		Content:  map[string]MediaType{fiber.MIMEApplicationJSON: {Schema: schema}},
+	}
+}
+
+// normalizePath – grupa z trasą "/" daje "/documents/"; Fiber (bez StrictRouting) traktuje to jak "/documents"
+func normalizePath(path string) string {
+	if len(path) > 1 {
+		path = strings.TrimRight(path, "/")
+	}
+	return path
+}
+
+// templatePath zamienia składnię Fiber (/a/:id, /a/:id<int>, /a/*) na szablon OpenAPI (/a/{id})
+func templatePath(path string) (string, []string) {
+	segments := strings.Split(path, "/")
+	var params []string
+	for i, seg := range segments {
+		var name string
+		switch {
+		case strings.HasPrefix(seg, ":"):
+			name = strings.TrimSuffix(strings.TrimPrefix(seg, ":"), "?")
+			if j := strings.IndexByte(name, '<'); j >= 0 {
+				name = name[:j]
+			}
+		case seg == "*" || seg == "+":
+			name = "path"
+		default:
+			continue
+		}
+		segments[i] = "{" + name + "}"
+		params = append(params, name)
+	}
+	return strings.Join(segments, "/"), params
+}
@@ -2,8 +2,25 @@
 package redis
 
 const (
-	SessionPrefix      = "session:"   // Dla aktywnych sesji użytkowników
-	ChallengePrefix    = "challenge:" // Dla wyzwań Ed25519 (krótki TTL)
-	Login2FAPrefix     = "login:2fa:" // Dla tymczasowych sesji 2FA (kod 6-cyfrowy)
-	SetupSessionPrefix = "setup:session:"
+	SessionPrefix       = "session:"   // Dla aktywnych sesji użytkowników
+	ChallengePrefix     = "challenge:" // Dla wyzwań Ed25519 (krótki TTL)
+	Login2FAPrefix      = "login:2fa:" // Dla tymczasowych sesji 2FA (kod 6-cyfrowy)
+	SetupSessionPrefix  = "setup:session:"
+	UserSessionsPrefix  = "user:sessions:"    // Indeks sesji użytkownika (ZSET, score = created_at)
+	LoginFailPrefix     = "login:fail:"       // Liczniki nieudanych logowań (IP / konto / fingerprint)
+	BotChallengePrefix  = "login:challenge:"  // Wydane wyzwania anty-botowe (PoW / CAPTCHA)
+	RateLimitPrefix     = "ratelimit:"        // Okna limitów tras gatewaya (fixed window)
+	HTTPCachePrefix     = "httpcache:"        // Cache odpowiedzi gatewaya (wpisy, Vary, tagi)
+	IdempotencyPrefix   = "idempotency:"      // Klucze Idempotency-Key gatewaya (blokada + zapisana odpowiedź)
+	GatewayOverridesKey = "gateway:overrides" // Zmiany operatorów z admin API gatewaya (HASH)
+	IPReputationPrefix  = "reputation:ip:"    // Punkty reputacji adresów IP (okno od pierwszego przewinienia)
+	IPBanPrefix         = "reputation:ban:"   // Tymczasowe blokady adresów IP
+	DeviceProofPrefix   = "dpop:jti:"         // Zużyte nonce dowodów urządzenia (ochrona przed powtórzeniem)
+	InternalNoncePrefix = "internal:nonce:"   // Zużyte nonce podpisanego kontekstu gatewaya
+
+	GatewayMaintenanceAnnouncedPrefix = "gateway:maintenance:announced:" // Wysłane zapowiedzi okien serwisowych
 )
+
+// SessionRevokedChannel – kanał Pub/Sub z SID-ami zakończonych sesji
+// (gateway zrywa na tej podstawie połączenia WebSocket / SSE)
+const SessionRevokedChannel = "events:session:revoked"
@@ -0,0 +1,12 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// ClaimProofNonce zapisuje nonce (jti) dowodu urządzenia w obrębie sesji. false – nonce
+// był już użyty, czyli dowód jest powtórzeniem. ttl nie krótszy niż maksymalny wiek dowodu.
+func (c *Cache) ClaimProofNonce(ctx context.Context, sid, jti string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, DeviceProofPrefix+sid+":"+jti, 1, ttl).Result()
+}
@@ -0,0 +1,94 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// CachedResponse – odpowiedź upstreamu zapisana w cache gatewaya
+type CachedResponse struct {
+	Status   int                 `json:"status"`
+	Header   map[string][]string `json:"header"`
+	Body     []byte              `json:"body"`
+	StoredAt int64               `json:"stored_at"` // unix seconds (nagłówek Age)
+}
+
+// Klucze: httpcache:vary:<base> (nagłówki Vary zasobu), httpcache:entry:<key>,
+// httpcache:tag:<tag> (SET kluczy wpisów oznaczonych tagiem)
+func httpCacheKey(kind, id string) string {
+	return HTTPCachePrefix + kind + ":" + id
+}
+
+// ResponseVary zwraca nagłówki Vary zapamiętane dla zasobu (ok=false – brak wpisu)
+func (c *Cache) ResponseVary(ctx context.Context, base string) (vary []string, ok bool, err error) {
+	data, err := c.client.Get(ctx, httpCacheKey("vary", base)).Bytes()
+	if err != nil {
+		if err == goredis.Nil {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	if err := json.Unmarshal(data, &vary); err != nil {
+		return nil, false, err
+	}
+	return vary, true, nil
+}
+
+// GetResponse pobiera wpis cache; brak wpisu zwraca redis.Nil
+func (c *Cache) GetResponse(ctx context.Context, key string) (*CachedResponse, error) {
+	data, err := c.client.Get(ctx, httpCacheKey("entry", key)).Bytes()
+	if err != nil {
+		return nil, err
+	}
+	var resp CachedResponse
+	if err := json.Unmarshal(data, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// StoreResponse zapisuje wpis wraz z listą Vary zasobu i dopisuje go do zbiorów tagów.
+// Zbiór tagu żyje co najmniej tak długo, jak najdłużej żyjący wpis.
+func (c *Cache) StoreResponse(
+	ctx context.Context,
+	base, key string,
+	vary []string,
+	resp CachedResponse,
+	ttl time.Duration,
+	tags []string,
+) error {
+	entry, err := json.Marshal(resp)
+	if err != nil {
+		return err
+	}
+	varyData, _ := json.Marshal(vary)
+
+	_, err = c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Set(ctx, httpCacheKey("entry", key), entry, ttl)
+		pipe.Set(ctx, httpCacheKey("vary", base), varyData, ttl)
+		for _, tag := range tags {
+			tagKey := httpCacheKey("tag", tag)
+			pipe.SAdd(ctx, tagKey, key)
+			pipe.ExpireNX(ctx, tagKey, ttl)
+			pipe.ExpireGT(ctx, tagKey, ttl)
+		}
+		return nil
+	})
+	return err
+}
+
+// PurgeResponseTags usuwa wszystkie wpisy oznaczone tagami i zwraca ich liczbę
+func (c *Cache) PurgeResponseTags(ctx context.Context, tags ...string) (int, error) {
+	if len(tags) == 0 {
+		return 0, nil
+	}
+
+	keys := make([]string, len(tags))
+	for i, tag := range tags {
+		keys[i] = httpCacheKey("tag", tag)
+	}
+	return c.client.Eval(ctx, purgeTagsScript, keys, httpCacheKey("entry", "")).Int()
+}
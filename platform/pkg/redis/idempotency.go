@@ -0,0 +1,77 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// IdempotencyRecord – stan klucza Idempotency-Key: blokada "w toku" albo wynik żądania
+type IdempotencyRecord struct {
+	Fingerprint string          `json:"fingerprint"`     // skrót metody, ścieżki i body
+	Owner       string          `json:"owner,omitempty"` // request ID żądania, które zajęło klucz
+	Completed   bool            `json:"completed"`
+	Response    *CachedResponse `json:"response,omitempty"` // nil przy Completed = odpowiedź za duża do zapisu
+	CreatedAt   int64           `json:"created_at"`
+}
+
+func idempotencyKey(key string) string {
+	return IdempotencyPrefix + key
+}
+
+// AcquireIdempotencyKey atomowo zajmuje klucz rekordem "w toku" na czas lockTTL.
+// Zwraca nil, gdy klucz został zajęty, albo istniejący rekord (w toku lub zakończony).
+func (c *Cache) AcquireIdempotencyKey(ctx context.Context, key string, pending IdempotencyRecord, lockTTL time.Duration) (*IdempotencyRecord, error) {
+	data, err := json.Marshal(pending)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := c.client.Eval(ctx, idempotencyAcquireScript, []string{idempotencyKey(key)}, data, lockTTL.Milliseconds()).Text()
+	if err != nil {
+		if err == goredis.Nil {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var existing IdempotencyRecord
+	if err := json.Unmarshal([]byte(res), &existing); err != nil {
+		return nil, err
+	}
+	return &existing, nil
+}
+
+// CompleteIdempotencyKey zastępuje blokadę wynikiem żądania. false – blokada wygasła
+// i klucz należy już do innego żądania (wynik nie został zapisany).
+func (c *Cache) CompleteIdempotencyKey(ctx context.Context, key string, pending, result IdempotencyRecord, ttl time.Duration) (bool, error) {
+	lock, err := json.Marshal(pending)
+	if err != nil {
+		return false, err
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return false, err
+	}
+	return c.settleIdempotencyKey(ctx, key, lock, data, ttl)
+}
+
+// ReleaseIdempotencyKey zwalnia blokadę bez zapisu wyniku (klient może ponowić żądanie)
+func (c *Cache) ReleaseIdempotencyKey(ctx context.Context, key string, pending IdempotencyRecord) error {
+	lock, err := json.Marshal(pending)
+	if err != nil {
+		return err
+	}
+	_, err = c.settleIdempotencyKey(ctx, key, lock, nil, 0)
+	return err
+}
+
+func (c *Cache) settleIdempotencyKey(ctx context.Context, key string, lock, result []byte, ttl time.Duration) (bool, error) {
+	res, err := c.client.Eval(ctx, idempotencySettleScript, []string{idempotencyKey(key)}, lock, result, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, err
+	}
+	return res == 1, nil
+}
@@ -0,0 +1,12 @@
+package redis
+
+import (
+	"context"
+	"time"
+)
+
+// ClaimInternalNonce zapisuje nonce podpisanego kontekstu gatewaya do końca jego ważności.
+// false – koperta z tym nonce już dotarła (powtórzenie przechwyconych nagłówków).
+func (c *Cache) ClaimInternalNonce(ctx context.Context, nonce string, ttl time.Duration) (bool, error) {
+	return c.client.SetNX(ctx, InternalNoncePrefix+nonce, 1, ttl).Result()
+}
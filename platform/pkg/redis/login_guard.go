@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// BotChallenge przechowuje wyzwanie anty-botowe wydane przy logowaniu
+type BotChallenge struct {
+	Kind        string `json:"kind"`
+	Nonce       string `json:"nonce,omitempty"`
+	Difficulty  int    `json:"difficulty,omitempty"`
+	Email       string `json:"email"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// --- Liczniki nieudanych logowań ---
+
+// IncrLoginFailures zwiększa liczniki porażek dla podanych wymiarów (np. "ip:1.2.3.4").
+// Okno liczone jest od pierwszej porażki – kolejne nie przedłużają TTL.
+func (c *Cache) IncrLoginFailures(ctx context.Context, window time.Duration, dims ...string) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, d := range dims {
+			pipe.Incr(ctx, LoginFailPrefix+d)
+			pipe.ExpireNX(ctx, LoginFailPrefix+d, window)
+		}
+		return nil
+	})
+	return err
+}
+
+// MaxLoginFailures zwraca największy licznik porażek spośród podanych wymiarów
+func (c *Cache) MaxLoginFailures(ctx context.Context, dims ...string) (int, error) {
+	if len(dims) == 0 {
+		return 0, nil
+	}
+	keys := make([]string, len(dims))
+	for i, d := range dims {
+		keys[i] = LoginFailPrefix + d
+	}
+
+	vals, err := c.client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	maxFails := 0
+	for _, v := range vals {
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		if n, err := strconv.Atoi(s); err == nil && n > maxFails {
+			maxFails = n
+		}
+	}
+	return maxFails, nil
+}
+
+// ResetLoginFailures czyści liczniki porażek (np. po udanym logowaniu)
+func (c *Cache) ResetLoginFailures(ctx context.Context, dims ...string) error {
+	if len(dims) == 0 {
+		return nil
+	}
+	keys := make([]string, len(dims))
+	for i, d := range dims {
+		keys[i] = LoginFailPrefix + d
+	}
+	return c.client.Del(ctx, keys...).Err()
+}
+
+// --- Wyzwania anty-botowe ---
+
+// SetBotChallenge zapisuje wydane wyzwanie pod jednorazowym tokenem
+func (c *Cache) SetBotChallenge(ctx context.Context, token string, ch BotChallenge, ttl time.Duration) error {
+	data, _ := json.Marshal(ch)
+	return c.client.Set(ctx, BotChallengePrefix+token, data, ttl).Err()
+}
+
+// TakeBotChallenge pobiera i atomowo usuwa wyzwanie (zabezpieczenie Replay Attack)
+func (c *Cache) TakeBotChallenge(ctx context.Context, token string) (*BotChallenge, error) {
+	data, err := c.client.GetDel(ctx, BotChallengePrefix+token).Result()
+	if err != nil {
+		return nil, err
+	}
+	var ch BotChallenge
+	if err := json.Unmarshal([]byte(data), &ch); err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
@@ -0,0 +1,26 @@
+package redis
+
+import (
+	"context"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// HitRateLimit zlicza żądanie w oknie stałym i zwraca aktualny licznik oraz czas do resetu.
+// Okno liczone jest od pierwszego żądania – kolejne nie przedłużają TTL.
+func (c *Cache) HitRateLimit(ctx context.Context, key string, window time.Duration) (int64, time.Duration, error) {
+	var incr *goredis.IntCmd
+	var ttl *goredis.DurationCmd
+
+	_, err := c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.Incr(ctx, RateLimitPrefix+key)
+		pipe.ExpireNX(ctx, RateLimitPrefix+key, window)
+		ttl = pipe.PTTL(ctx, RateLimitPrefix+key)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+	return incr.Val(), ttl.Val(), nil
+}
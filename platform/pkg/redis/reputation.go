@@ -0,0 +1,55 @@
+package redis
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+)
+
+// ReputationPolicy – progi reputacji IP (viper.ReputationConfig)
+type ReputationPolicy struct {
+	Window       time.Duration
+	BanThreshold int64
+	BanDuration  time.Duration
+}
+
+// PenalizeIP dolicza punkty adresowi IP. Po przekroczeniu progu zakłada tymczasową
+// blokadę; banned=true zwraca tylko wywołanie, które ją założyło (audyt raz na blokadę).
+func (c *Cache) PenalizeIP(ctx context.Context, ip string, points int64, p ReputationPolicy) (score int64, banned bool, err error) {
+	var incr *goredis.IntCmd
+	_, err = c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		incr = pipe.IncrBy(ctx, IPReputationPrefix+ip, points)
+		pipe.ExpireNX(ctx, IPReputationPrefix+ip, p.Window)
+		return nil
+	})
+	if err != nil {
+		return 0, false, err
+	}
+
+	score = incr.Val()
+	if score < p.BanThreshold {
+		return score, false, nil
+	}
+	banned, err = c.client.SetNX(ctx, IPBanPrefix+ip, score, p.BanDuration).Result()
+	return score, banned, err
+}
+
+// IPStatus zwraca punkty adresu i pozostały czas jego blokady (0 = brak) jednym zapytaniem
+func (c *Cache) IPStatus(ctx context.Context, ip string) (score int64, ban time.Duration, err error) {
+	pipe := c.client.Pipeline()
+	get := pipe.Get(ctx, IPReputationPrefix+ip)
+	pttl := pipe.PTTL(ctx, IPBanPrefix+ip)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, goredis.Nil) {
+		return 0, 0, err
+	}
+	score, _ = get.Int64()
+	// PTTL: -2 = brak klucza; blokada zawsze ma TTL
+	return score, max(pttl.Val(), 0), nil
+}
+
+// ClearIPReputation zdejmuje blokadę i zeruje punkty adresu (admin API)
+func (c *Cache) ClearIPReputation(ctx context.Context, ip string) error {
+	return c.client.Del(ctx, IPBanPrefix+ip, IPReputationPrefix+ip).Err()
+}
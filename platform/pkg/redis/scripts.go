@@ -6,3 +6,21 @@ import (
 
 //go:embed scripts/verify_2fa.lua
 var verify2FAScript string
+
+//go:embed scripts/start_session.lua
+var startSessionScript string
+
+//go:embed scripts/touch_session.lua
+var touchSessionScript string
+
+//go:embed scripts/purge_tags.lua
+var purgeTagsScript string
+
+//go:embed scripts/idempotency_acquire.lua
+var idempotencyAcquireScript string
+
+//go:embed scripts/idempotency_settle.lua
+var idempotencySettleScript string
+
+//go:embed scripts/mark_web_session.lua
+var markWebSessionScript string
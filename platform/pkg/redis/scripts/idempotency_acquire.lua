@@ -0,0 +1,13 @@
+-- KEYS[1] = idempotency:{scope}:{key}
+-- ARGV[1] = rekord "w toku" (JSON)
+-- ARGV[2] = czas blokady (ms)
+--
+-- Zwraca istniejący rekord albo nil, gdy klucz został właśnie zajęty
+
+local current = redis.call("GET", KEYS[1])
+if current then
+  return current
+end
+
+redis.call("SET", KEYS[1], ARGV[1], "PX", ARGV[2])
+return false
@@ -0,0 +1,17 @@
+-- KEYS[1] = idempotency:{scope}:{key}
+-- ARGV[1] = rekord "w toku" zapisany przy zajęciu klucza (JSON)
+-- ARGV[2] = rekord końcowy (JSON) albo "" – zwolnienie klucza
+-- ARGV[3] = czas życia rekordu końcowego (ms)
+--
+-- Rozstrzyga tylko właściciel blokady: po jej wygaśnięciu klucz mógł zająć kto inny
+
+if redis.call("GET", KEYS[1]) ~= ARGV[1] then
+  return 0
+end
+
+if ARGV[2] == "" then
+  redis.call("DEL", KEYS[1])
+else
+  redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return 1
@@ -0,0 +1,12 @@
+-- KEYS[1] = session:{sid}
+
+local data = redis.call("GET", KEYS[1])
+if not data then
+  return 0
+end
+
+local session = cjson.decode(data)
+session.web = true
+redis.call("SET", KEYS[1], cjson.encode(session), "KEEPTTL")
+
+return 1
@@ -0,0 +1,15 @@
+-- KEYS    = httpcache:tag:{tag} (zbiory kluczy wpisów)
+-- ARGV[1] = prefix wpisów (httpcache:entry:)
+--
+-- Atomowo: wpis dopisany do tagu w trakcie purge nie może przetrwać bez swojego tagu
+
+local purged = 0
+for _, tagKey in ipairs(KEYS) do
+  local members = redis.call("SMEMBERS", tagKey)
+  for _, key in ipairs(members) do
+    purged = purged + redis.call("DEL", ARGV[1] .. key)
+  end
+  redis.call("DEL", tagKey)
+end
+
+return purged
@@ -0,0 +1,34 @@
+-- KEYS[1] = session:{sid}
+-- KEYS[2] = user:sessions:{uid}
+-- ARGV[1] = session json
+-- ARGV[2] = idle ttl seconds
+-- ARGV[3] = sid
+-- ARGV[4] = score (created_at, ms)
+-- ARGV[5] = max sessions per user (0 = bez limitu)
+-- ARGV[6] = session key prefix
+-- ARGV[7] = index ttl seconds
+
+redis.call("SET", KEYS[1], ARGV[1], "EX", ARGV[2])
+redis.call("ZADD", KEYS[2], ARGV[4], ARGV[3])
+redis.call("EXPIRE", KEYS[2], ARGV[7])
+
+-- Sprzątanie wpisów po sesjach, które wygasły same (idle / absolute)
+local members = redis.call("ZRANGE", KEYS[2], 0, -1)
+for _, sid in ipairs(members) do
+  if redis.call("EXISTS", ARGV[6] .. sid) == 0 then
+    redis.call("ZREM", KEYS[2], sid)
+  end
+end
+
+-- Limit równoległych sesji: usuwamy najstarsze
+local evicted = {}
+local max = tonumber(ARGV[5])
+if max > 0 then
+  while redis.call("ZCARD", KEYS[2]) > max do
+    local oldest = redis.call("ZPOPMIN", KEYS[2])
+    redis.call("DEL", ARGV[6] .. oldest[1])
+    table.insert(evicted, oldest[1])
+  end
+end
+
+return evicted
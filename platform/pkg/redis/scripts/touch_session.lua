@@ -0,0 +1,33 @@
+-- KEYS[1] = session:{sid}
+-- ARGV[1] = now (unix seconds)
+-- ARGV[2] = idle ttl seconds
+-- ARGV[3] = absolute ttl seconds
+
+local data = redis.call("GET", KEYS[1])
+if not data then
+  return { "NOT_FOUND" }
+end
+
+local session = cjson.decode(data)
+local now = tonumber(ARGV[1])
+
+-- Sesje sprzed wprowadzenia created_at liczymy od teraz
+if type(session.created_at) ~= "number" then
+  session.created_at = now
+end
+
+local remaining = session.created_at + tonumber(ARGV[3]) - now
+if remaining <= 0 then
+  redis.call("DEL", KEYS[1])
+  -- Wpis w indeksie user:sessions:{uid} usuwa wywołujący (klucz spoza KEYS)
+  return { "EXPIRED", session.user_id }
+end
+
+-- Okno bezczynności przesuwa się, ale nigdy poza absolutny czas życia
+local ttl = math.min(tonumber(ARGV[2]), remaining)
+session.last_seen_at = now
+
+local encoded = cjson.encode(session)
+redis.call("SET", KEYS[1], encoded, "EX", ttl)
+
+return { "OK", encoded }
@@ -3,16 +3,27 @@ package redis
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
+
+	goredis "github.com/redis/go-redis/v9"
 )
 
+// ErrSessionLifetimeExceeded oznacza, że sesja przekroczyła absolutny czas życia
+var ErrSessionLifetimeExceeded = errors.New("session absolute lifetime exceeded")
+
 // UserSession przechowuje dane aktywnej sesji użytkownika
 type UserSession struct {
 	UserID      string   `json:"user_id"`
 	Fingerprint string   `json:"fingerprint"`
 	Roles       []string `json:"roles,omitempty"`
+	DeviceKey   string   `json:"device_key,omitempty"` // klucz publiczny Ed25519 urządzenia (base64) – dowody DPoP
+	Web         bool     `json:"web,omitempty"`        // sesja przeglądarkowa – dostępna także przez ciasteczko
 	Challenge   string   `json:"challenge,omitempty"`
 	IP          string   `json:"ip,omitempty"`
+	CreatedAt   int64    `json:"created_at,omitempty"`   // unix seconds
+	LastSeenAt  int64    `json:"last_seen_at,omitempty"` // unix seconds
 }
 
 // --- Metody dla Sesji Głównej ---
@@ -38,7 +49,179 @@ func (c *Cache) GetSession(ctx context.Context, sid string) (*UserSession, error
 
 // DeleteSession usuwa sesję użytkownika
 func (c *Cache) DeleteSession(ctx context.Context, sid string) error {
-	return c.client.Del(ctx, SessionPrefix+sid).Err()
+	_, err := c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, SessionPrefix+sid)
+		pipe.Publish(ctx, SessionRevokedChannel, sid)
+		return nil
+	})
+	return err
+}
+
+// StartSession zapisuje nową sesję, dopisuje ją do indeksu użytkownika i usuwa
+// najstarsze sesje ponad limit. Zwraca SID-y sesji wyrzuconych z powodu limitu.
+// Niezerowe sess.CreatedAt zachowuje absolutny czas życia sesji, którą nowa zastępuje;
+// po jego upływie zwraca ErrSessionLifetimeExceeded.
+func (c *Cache) StartSession(ctx context.Context, sid string, sess UserSession, idleTTL, absoluteTTL time.Duration, maxPerUser int) ([]string, error) {
+	now := time.Now()
+	if sess.CreatedAt == 0 {
+		sess.CreatedAt = now.Unix()
+	}
+	sess.LastSeenAt = now.Unix()
+
+	// CreatedAt przeniesiony ze starszej sesji (odświeżenie tokenu) skraca okno bezczynności
+	if absoluteTTL > 0 {
+		remaining := time.Unix(sess.CreatedAt, 0).Add(absoluteTTL).Sub(now)
+		if remaining < time.Second {
+			return nil, ErrSessionLifetimeExceeded
+		}
+		idleTTL = min(idleTTL, remaining)
+	}
+
+	data, _ := json.Marshal(sess)
+	res, err := c.client.Eval(
+		ctx,
+		startSessionScript,
+		[]string{SessionPrefix + sid, UserSessionsPrefix + sess.UserID},
+		data,
+		int(idleTTL.Seconds()),
+		sid,
+		now.UnixMilli(),
+		maxPerUser,
+		SessionPrefix,
+		int(max(absoluteTTL, idleTTL).Seconds()),
+	).StringSlice()
+	if err != nil && !errors.Is(err, goredis.Nil) {
+		return nil, err
+	}
+
+	// Sesje wyrzucone z powodu limitu – powiadamiamy gateway (best effort, klucze już usunięte)
+	if len(res) > 0 {
+		_, _ = c.client.Pipelined(ctx, func(pipe goredis.Pipeliner) error {
+			for _, evicted := range res {
+				pipe.Publish(ctx, SessionRevokedChannel, evicted)
+			}
+			return nil
+		})
+	}
+	return res, nil
+}
+
+// TouchSession odczytuje sesję i przesuwa okno bezczynności (maksymalnie do absolutnego
+// czasu życia liczonego od CreatedAt). Brak sesji zwraca redis.Nil.
+func (c *Cache) TouchSession(ctx context.Context, sid string, idleTTL, absoluteTTL time.Duration) (*UserSession, error) {
+	res, err := c.client.Eval(
+		ctx,
+		touchSessionScript,
+		[]string{SessionPrefix + sid},
+		time.Now().Unix(),
+		int(idleTTL.Seconds()),
+		int(absoluteTTL.Seconds()),
+	).StringSlice()
+	if err != nil {
+		return nil, err
+	}
+	if len(res) == 0 {
+		return nil, fmt.Errorf("invalid lua response from touch_session script")
+	}
+
+	switch res[0] {
+	case "NOT_FOUND":
+		return nil, goredis.Nil
+	case "EXPIRED":
+		// Indeks użytkownika to klucz zależny od zawartości sesji, więc nie może być w KEYS
+		// skryptu. Best effort – osierocone wpisy usuwa też StartSession.
+		if len(res) > 1 && res[1] != "" {
+			_ = c.client.ZRem(ctx, UserSessionsPrefix+res[1], sid).Err()
+		}
+		return nil, ErrSessionLifetimeExceeded
+	}
+
+	if len(res) < 2 {
+		return nil, fmt.Errorf("invalid lua response from touch_session script")
+	}
+	var sess UserSession
+	if err := json.Unmarshal([]byte(res[1]), &sess); err != nil {
+		return nil, err
+	}
+	return &sess, nil
+}
+
+// SessionExists – sesja nadal istnieje (nie wygasła, nie została wylogowana ani wyrzucona)
+func (c *Cache) SessionExists(ctx context.Context, sid string) (bool, error) {
+	n, err := c.client.Exists(ctx, SessionPrefix+sid).Result()
+	return n > 0, err
+}
+
+// MarkWebSession oznacza sesję jako przeglądarkową (ciasteczko sesji), bez zmiany TTL.
+// Brak sesji zwraca redis.Nil.
+func (c *Cache) MarkWebSession(ctx context.Context, sid string) error {
+	marked, err := c.client.Eval(ctx, markWebSessionScript, []string{SessionPrefix + sid}).Int()
+	if err != nil {
+		return err
+	}
+	if marked == 0 {
+		return goredis.Nil
+	}
+	return nil
+}
+
+// EndSession usuwa sesję oraz jej wpis w indeksie użytkownika
+func (c *Cache) EndSession(ctx context.Context, sid string, userID string) error {
+	_, err := c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		pipe.Del(ctx, SessionPrefix+sid)
+		pipe.ZRem(ctx, UserSessionsPrefix+userID, sid)
+		pipe.Publish(ctx, SessionRevokedChannel, sid)
+		return nil
+	})
+	return err
+}
+
+// ListUserSessions zwraca SID-y sesji z indeksu użytkownika (od najstarszej)
+func (c *Cache) ListUserSessions(ctx context.Context, userID string) ([]string, error) {
+	return c.client.ZRange(ctx, UserSessionsPrefix+userID, 0, -1).Result()
+}
+
+// EndUserSessions usuwa wszystkie sesje użytkownika (np. po banie) i zwraca ich liczbę
+func (c *Cache) EndUserSessions(ctx context.Context, userID string) (int, error) {
+	sids, err := c.ListUserSessions(ctx, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = c.client.TxPipelined(ctx, func(pipe goredis.Pipeliner) error {
+		for _, sid := range sids {
+			pipe.Del(ctx, SessionPrefix+sid)
+			pipe.Publish(ctx, SessionRevokedChannel, sid)
+		}
+		pipe.Del(ctx, UserSessionsPrefix+userID)
+		return nil
+	})
+	return len(sids), err
+}
+
+// WatchRevokedSessions wywołuje fn dla każdego SID-a z SessionRevokedChannel, aż do
+// anulowania ctx. Po zerwaniu połączenia go-redis sam odnawia subskrypcję – komunikaty
+// z tej przerwy przepadają, dlatego odbiorca powinien dodatkowo okresowo sprawdzać sesje.
+func (c *Cache) WatchRevokedSessions(ctx context.Context, fn func(sid string)) error {
+	sub := c.client.Subscribe(ctx, SessionRevokedChannel)
+	defer sub.Close()
+
+	if _, err := sub.Receive(ctx); err != nil {
+		return err
+	}
+
+	ch := sub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case msg, ok := <-ch:
+			if !ok {
+				return nil
+			}
+			fn(msg.Payload)
+		}
+	}
 }
 
 // UpdateSession pozwala na atomową modyfikację sesji za pomocą funkcji
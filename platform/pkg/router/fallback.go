@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/zerodayz7/platform/pkg/errors"
 
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/shared"
 
 	"github.com/gofiber/fiber/v2"
@@ -19,7 +20,7 @@ func SetupFallbackHandlers(app *fiber.App) {
 		log.WarnMap("404 Not Found", map[string]any{
 			"path":      c.Path(),
 			"method":    c.Method(),
-			"ip":        c.IP(),
+			"ip":        reqctx.ClientIP(c),
 			"userAgent": c.Get("User-Agent"),
 			"requestID": c.Locals("requestid"),
 		})
@@ -2,6 +2,7 @@ package health
 
 import (
 	"context"
+	"maps"
 	"net/http"
 	"strings"
 	"time"
@@ -15,6 +16,10 @@ type Checker struct {
 	Service   string
 	Version   string
 	Upstreams []string
+	// Extra – dodatkowe sprawdzenia specyficzne dla serwisu (np. stan circuit breakerów)
+	Extra func(ctx context.Context) map[string]string
+	// HTTPClient – klient sond (np. z certyfikatem mTLS); nil = domyślny z limitem 2s
+	HTTPClient *http.Client
 }
 
 func (c *Checker) RunChecks(ctx context.Context) map[string]string {
@@ -28,29 +33,49 @@ func (c *Checker) RunChecks(ctx context.Context) map[string]string {
 		}
 	}
 
-	for _, url := range c.Upstreams {
+	for url, ok := range c.ProbeUpstreams(ctx) {
 		name := "upstream_" + extractName(url)
+		if ok {
+			checks[name] = "ok"
+		} else {
+			checks[name] = "down"
+		}
+	}
+
+	if c.Extra != nil {
+		maps.Copy(checks, c.Extra(ctx))
+	}
+
+	return checks
+}
 
+// ProbeUpstreams odpytuje każdy adres z Upstreams (GET, status < 500 = zdrowy).
+// Wynik kluczowany pełnym URL – używany też przez aktywne sondowanie instancji w gatewayu.
+func (c *Checker) ProbeUpstreams(ctx context.Context) map[string]bool {
+	results := make(map[string]bool, len(c.Upstreams))
+	client := c.HTTPClient
+	if client == nil {
+		client = &http.Client{Timeout: 2 * time.Second}
+	}
+
+	for _, url := range c.Upstreams {
 		// Tworzymy request z kontekstem
 		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
-			checks[name] = "down"
+			results[url] = false
 			continue
 		}
 
-		client := &http.Client{Timeout: 2 * time.Second}
 		resp, err := client.Do(req)
-
-		if err == nil && resp.StatusCode < 500 {
-			checks[name] = "ok"
+		if err == nil {
 			resp.Body.Close()
-		} else {
-			checks[name] = "down"
 		}
+		results[url] = err == nil && resp.StatusCode < 500
 	}
 
-	return checks
+	return results
 }
+
 func extractName(url string) string {
 	if i := strings.Index(url, "://"); i != -1 {
 		url = url[i+3:]
@@ -0,0 +1,62 @@
+package schemas
+
+import "time"
+
+// ===== Admin: zarządzanie użytkownikami =====
+
+type AdminUserSearchQuery struct {
+	Query    string `query:"q" validate:"omitempty,max=100"`
+	Page     int    `query:"page" validate:"omitempty,min=1"`
+	PageSize int    `query:"page_size" validate:"omitempty,min=1,max=100"`
+}
+
+type AdminUserParams struct {
+	ID string `params:"id" validate:"required,uuid"`
+}
+
+type AdminChangeStatusRequest struct {
+	Status        string     `json:"status" validate:"required,oneof=ACTIVE SUSPENDED BANNED"`
+	LockedUntil   *time.Time `json:"locked_until" validate:"required_if=Status SUSPENDED"`
+	Justification string     `json:"justification" validate:"required,min=10,max=500"`
+}
+
+// AdminActionRequest – każda akcja administracyjna wymaga uzasadnienia (trafia do audytu)
+type AdminActionRequest struct {
+	Justification string `json:"justification" validate:"required,min=10,max=500"`
+}
+
+// ===== Admin: operacje na gatewayu =====
+
+// GatewayDrainRequest – wyłączenie instancji z ruchu (pusty Instance = wszystkie instancje upstreamu)
+type GatewayDrainRequest struct {
+	Instance      string `json:"instance" validate:"omitempty,url"`
+	Drain         bool   `json:"drain"`
+	Justification string `json:"justification" validate:"required,min=10,max=500"`
+}
+
+// GatewayBreakerRequest – wymuszenie stanu breakera (auto = powrót do automatu)
+type GatewayBreakerRequest struct {
+	State         string `json:"state" validate:"required,oneof=open closed auto"`
+	Justification string `json:"justification" validate:"required,min=10,max=500"`
+}
+
+// GatewayMaintenanceRequest – tryb serwisowy trasy (Route = klucz z GET /admin/routes)
+type GatewayMaintenanceRequest struct {
+	Route         string `json:"route" validate:"required"`
+	Enabled       bool   `json:"enabled"`
+	Justification string `json:"justification" validate:"required,min=10,max=500"`
+}
+
+// GatewayMaintenanceWindowRequest – zaplanowanie okna serwisowego (to samo ID co w routes.yaml zastępuje okno z pliku)
+type GatewayMaintenanceWindowRequest struct {
+	ID        string            `json:"id" validate:"required,max=64"`
+	Upstreams []string          `json:"upstreams" validate:"required_without=Paths"`
+	Paths     []string          `json:"paths" validate:"required_without=Upstreams"`
+	Start     time.Time         `json:"start" validate:"required"`
+	End       time.Time         `json:"end" validate:"required,gtfield=Start"`
+	Message   map[string]string `json:"message" validate:"required,min=1"`
+	Testers   []string          `json:"testers" validate:"omitempty,max=100,dive,uuid"`
+	// AnnounceMinutes – zapowiedź dla wszystkich użytkowników tyle minut przed startem (0 = bez)
+	AnnounceMinutes int    `json:"announce_minutes" validate:"gte=0,lte=43200"`
+	Justification   string `json:"justification" validate:"required,min=10,max=500"`
+}
@@ -9,6 +9,9 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password []byte `json:"password" validate:"required"`
+	// Opcjonalne – wymagane dopiero po otrzymaniu odpowiedzi typu "botChallenge"
+	ChallengeToken    string `json:"challenge_token,omitempty" validate:"omitempty,max=128"`
+	ChallengeSolution string `json:"challenge_solution,omitempty" validate:"required_with=ChallengeToken,max=2048"`
 }
 
 type VerifyDeviceRequest struct {
@@ -20,10 +23,27 @@ type TwoFARequest struct {
 	Token string `json:"token" validate:"required"`
 }
 
+type TwoFARecoverRequest struct {
+	Token        string `json:"token" validate:"required"`
+	RecoveryCode string `json:"recovery_code" validate:"required,min=10,max=32"`
+}
+
+// RegenerateRecoveryCodesRequest – nowe kody wymagają ponownego podania hasła
+// (przejęta sesja sama nie wystarcza, żeby unieważnić kody właściciela)
+type RegenerateRecoveryCodesRequest struct {
+	Password []byte `json:"password" validate:"required"`
+}
+
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
 
+// LogoutRequest – sesja jest wskazana przez token lub ciasteczko; refresh token jest
+// opcjonalny (portal w trybie przeglądarkowym go nie ma)
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
 // ===== Reset Password =====
 type ResetPasswordRequest struct {
 	Value  string `json:"value" validate:"required,email"`
@@ -39,11 +59,13 @@ type ResetPasswordFinalRequest struct {
 	Token       string `json:"reset_token" validate:"required"`
 	Code        string `json:"code" validate:"required,len=6"`
 	NewPassword string `json:"new_password" validate:"required,min=8"`
-	Signature   string `json:"signature" validate:"required"`
+	Signature   string `json:"signature" validate:"required_without=RecoveryCode"`
 	Fingerprint string `json:"fingerprint" validate:"required"`
 	DeviceName  string `json:"device_name"`
 	Platform    string `json:"platform"`
 	PublicKey   string `json:"public_key"`
+	// Alternatywa dla podpisu, gdy użytkownik utracił zaufane urządzenie
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,min=10,max=32"`
 }
 
 type RegisterDeviceRequest struct {
@@ -55,11 +77,12 @@ type RegisterDeviceRequest struct {
 }
 
 type FinalizeResetRequest struct {
-	Token       string `json:"token" validate:"required"`
-	Password    string `json:"password" validate:"required,min=8"`
-	Signature   string `json:"signature" validate:"required"`
-	Fingerprint string `json:"fingerprint" validate:"required"`
-	PublicKey   string `json:"public_key" validate:"required"`
-	DeviceName  string `json:"device_name" validate:"required"`
-	Platform    string `json:"platform" validate:"required"`
+	Token        string `json:"token" validate:"required"`
+	Password     string `json:"password" validate:"required,min=8"`
+	Signature    string `json:"signature" validate:"required_without=RecoveryCode"`
+	RecoveryCode string `json:"recovery_code" validate:"omitempty,min=10,max=32"`
+	Fingerprint  string `json:"fingerprint" validate:"required"`
+	PublicKey    string `json:"public_key" validate:"required_without=RecoveryCode"`
+	DeviceName   string `json:"device_name" validate:"required"`
+	Platform     string `json:"platform" validate:"required"`
 }
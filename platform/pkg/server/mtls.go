@@ -0,0 +1,176 @@
+package server
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+)
+
+// Certificates trzyma certyfikat usługi i pulę CA z plików MTLS_*. Pliki są sprawdzane
+// co ReloadInterval; nowa para jest podmieniana atomowo i obowiązuje od następnego
+// handshake'u (otwarte połączenia zostają przy starej). Błędny plik nie zastępuje poprzedniego.
+type Certificates struct {
+	cfg  viper.MTLSConfig
+	cert atomic.Pointer[tls.Certificate]
+	pool atomic.Pointer[x509.CertPool]
+	sum  [sha256.Size]byte // treść wczytanych plików – przeładowanie tylko po zmianie
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+// LoadCertificates wczytuje pliki (błąd przy starcie jest fatalny) i uruchamia ich obserwację
+func LoadCertificates(cfg viper.MTLSConfig) (*Certificates, error) {
+	c := &Certificates{cfg: cfg, stop: make(chan struct{})}
+	if _, err := c.reload(); err != nil {
+		return nil, err
+	}
+	go c.watch()
+	return c, nil
+}
+
+// Certificate – aktualny certyfikat usługi
+func (c *Certificates) Certificate() *tls.Certificate {
+	return c.cert.Load()
+}
+
+// Pool – aktualna pula zaufanych CA
+func (c *Certificates) Pool() *x509.CertPool {
+	return c.pool.Load()
+}
+
+// Close zatrzymuje obserwację plików (nil – mTLS wyłączone)
+func (c *Certificates) Close() {
+	if c == nil {
+		return
+	}
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+func (c *Certificates) reload() (changed bool, err error) {
+	certPEM, err := os.ReadFile(c.cfg.CertFile)
+	if err != nil {
+		return false, err
+	}
+	keyPEM, err := os.ReadFile(c.cfg.KeyFile)
+	if err != nil {
+		return false, err
+	}
+	caPEM, err := os.ReadFile(c.cfg.CAFile)
+	if err != nil {
+		return false, err
+	}
+
+	sum := sha256.Sum256(bytes.Join([][]byte{certPEM, keyPEM, caPEM}, nil))
+	if sum == c.sum {
+		return false, nil
+	}
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return false, fmt.Errorf("mtls certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return false, fmt.Errorf("mtls CA file %s: no certificates", c.cfg.CAFile)
+	}
+
+	c.cert.Store(&cert)
+	c.pool.Store(pool)
+	c.sum = sum
+	return true, nil
+}
+
+func (c *Certificates) watch() {
+	log := shared.GetLogger()
+	ticker := time.NewTicker(c.cfg.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+		}
+
+		changed, err := c.reload()
+		switch {
+		case err != nil:
+			log.ErrorMap("mTLS certificates reload failed, keeping previous", map[string]any{
+				"cert":  c.cfg.CertFile,
+				"error": err.Error(),
+			})
+		case changed:
+			log.InfoMap("mTLS certificates reloaded", map[string]any{
+				"cert":    c.cfg.CertFile,
+				"expires": c.Certificate().Leaf.NotAfter,
+			})
+		}
+	}
+}
+
+// ClientConfig – konfiguracja klienta łączącego się z usługą o tożsamości serverName
+// (SAN DNS certyfikatu usługi), z własnym certyfikatem do uwierzytelnienia
+func (c *Certificates) ClientConfig(serverName string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		ServerName: serverName,
+		RootCAs:    c.Pool(),
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return c.Certificate(), nil
+		},
+	}
+}
+
+// MutualTLS – konfiguracja serwera wymagająca certyfikatu klienta podpisanego przez CA
+// i – gdy podano MTLS_CLIENT_NAMES – jednej z tych tożsamości. Przekazywana w
+// server.Config.TLS; nil (bez błędu), gdy MTLS_ENABLED=false.
+func MutualTLS(cfg viper.MTLSConfig) (*tls.Config, *Certificates, error) {
+	if !cfg.Enabled {
+		return nil, nil, nil
+	}
+	certs, err := LoadCertificates(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	verify := func(cs tls.ConnectionState) error {
+		if len(cfg.ClientNames) == 0 {
+			return nil
+		}
+		leaf := cs.PeerCertificates[0]
+		if slices.ContainsFunc(cfg.ClientNames, func(name string) bool { return leaf.VerifyHostname(name) == nil }) {
+			return nil
+		}
+		return fmt.Errorf("mtls: client %q is not allowed", leaf.Subject.CommonName)
+	}
+
+	// Konfiguracja per handshake – zawsze z aktualnym certyfikatem i pulą CA
+	return &tls.Config{
+		MinVersion: tls.VersionTLS13,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			cert := certs.Certificate()
+			if cert == nil {
+				return nil, errors.New("mtls: no server certificate")
+			}
+			return &tls.Config{
+				MinVersion:       tls.VersionTLS13,
+				Certificates:     []tls.Certificate{*cert},
+				ClientAuth:       tls.RequireAndVerifyClientCert,
+				ClientCAs:        certs.Pool(),
+				VerifyConnection: verify,
+			}, nil
+		},
+	}, certs, nil
+}
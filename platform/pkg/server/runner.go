@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"os"
 	"os/signal"
@@ -19,6 +20,18 @@ type Config struct {
 	AppVersion string
 	Env        string
 	Shutdown   time.Duration
+	// TLS – serwer przyjmuje tylko połączenia TLS (np. MutualTLS); nil = zwykłe HTTP
+	TLS *tls.Config
+	// Extra – dodatkowe aplikacje na własnych adresach (np. admin API gatewaya),
+	// uruchamiane i zamykane razem z główną
+	Extra []Listener
+}
+
+// Listener – dodatkowa aplikacja Fiber nasłuchująca pod Address (host:port)
+type Listener struct {
+	Name    string
+	App     *fiber.App
+	Address string
 }
 
 func Run(app *fiber.App, cfg Config, log shared.Logger, cleanup func()) {
@@ -35,11 +48,26 @@ func Run(app *fiber.App, cfg Config, log shared.Logger, cleanup func()) {
 			"version": cfg.AppVersion,
 			"address": address,
 			"env":     cfg.Env,
+			"tls":     cfg.TLS != nil,
 		})
 
+		if cfg.TLS != nil {
+			ln, err := tls.Listen("tcp", address, cfg.TLS)
+			if err != nil {
+				return err
+			}
+			return app.Listener(ln)
+		}
 		return app.Listen(address)
 	})
 
+	for _, extra := range cfg.Extra {
+		g.Go(func() error {
+			log.Info("Listener started", map[string]any{"name": extra.Name, "address": extra.Address})
+			return extra.App.Listen(extra.Address)
+		})
+	}
+
 	g.Go(func() error {
 		<-gCtx.Done()
 
@@ -49,6 +77,11 @@ func Run(app *fiber.App, cfg Config, log shared.Logger, cleanup func()) {
 		if err != nil {
 			log.ErrorObj("Fiber shutdown failed", err)
 		}
+		for _, extra := range cfg.Extra {
+			if err := extra.App.ShutdownWithTimeout(cfg.Shutdown); err != nil {
+				log.ErrorObj("Fiber shutdown failed ("+extra.Name+")", err)
+			}
+		}
 
 		if cleanup != nil {
 			cleanup()
@@ -5,9 +5,18 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"go.uber.org/zap"
 )
 
+const logFieldsKey = "logFields"
+
+// AddLogField dokłada pole do wpisu "Request completed" (np. decyzja routingu w gatewayu)
+func AddLogField(c *fiber.Ctx, field zap.Field) {
+	fields, _ := c.Locals(logFieldsKey).([]zap.Field)
+	c.Locals(logFieldsKey, append(fields, field))
+}
+
 func RequestLoggerMiddleware() fiber.Handler {
 	allowedHeaders := []string{
 		// "Content-Type",
@@ -78,14 +87,19 @@ func RequestLoggerMiddleware() fiber.Handler {
 		requestID := c.Locals("requestid")
 		// log (Strukturalny)
 		// 1. ZAWSZE logujemy strukturalnie do Zap (pójdzie do konsoli i do pliku JSON)
-		log.Info("Request completed",
+		fields := []any{
 			zap.String("method", c.Method()),
 			zap.String("path", c.Path()),
 			zap.Int("status", c.Response().StatusCode()),
 			zap.String("latency", latency.String()),
 			zap.Any("request_id", requestID),
-			zap.String("ip", c.IP()),
-		)
+			zap.String("ip", reqctx.ClientIP(c)),
+		}
+		extra, _ := c.Locals(logFieldsKey).([]zap.Field)
+		for _, f := range extra {
+			fields = append(fields, f)
+		}
+		log.Info("Request completed", fields...)
 
 		// 2. TYLKO W DEV wypisujemy dodatkowo "ładny" blok do konsoli
 		// if isDev {
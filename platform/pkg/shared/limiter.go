@@ -6,6 +6,7 @@ import (
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/errors"
 )
 
@@ -19,6 +20,7 @@ const (
 	LimitAudit         LimitGroup = "audit"
 	LimitReset         LimitGroup = "reset"
 	LimitNotifications LimitGroup = "notifications"
+	LimitOAuth         LimitGroup = "oauth"
 )
 
 var (
@@ -26,6 +28,27 @@ var (
 	limitersLock sync.RWMutex
 )
 
+type limitPreset struct {
+	Max    int
+	Window time.Duration
+}
+
+var limitPresets = map[LimitGroup]limitPreset{
+	LimitGlobal:        {Max: 100, Window: 60 * time.Second},
+	LimitAuth:          {Max: 5, Window: 60 * time.Second},
+	LimitHealth:        {Max: 50, Window: 30 * time.Second},
+	LimitAudit:         {Max: 50, Window: 1 * time.Minute},
+	LimitReset:         {Max: 3, Window: 1 * time.Hour},
+	LimitNotifications: {Max: 30, Window: 1 * time.Minute},
+	LimitOAuth:         {Max: 300, Window: 1 * time.Minute},
+}
+
+// LimitPreset zwraca limit dla grupy – używane przez trasy definiowane w konfiguracji gatewaya
+func LimitPreset(group LimitGroup) (max int, window time.Duration, ok bool) {
+	p, ok := limitPresets[group]
+	return p.Max, p.Window, ok
+}
+
 func GetLimiter(group LimitGroup, storage fiber.Storage) fiber.Handler {
 	limitersLock.RLock()
 	if l, exists := limiters[group]; exists {
@@ -47,21 +70,9 @@ func GetLimiter(group LimitGroup, storage fiber.Storage) fiber.Handler {
 }
 
 func createLimiter(group LimitGroup, storage fiber.Storage) fiber.Handler {
-	presets := map[LimitGroup]struct {
-		Max    int
-		Window time.Duration
-	}{
-		LimitGlobal:        {Max: 100, Window: 60 * time.Second},
-		LimitAuth:          {Max: 5, Window: 60 * time.Second},
-		LimitHealth:        {Max: 50, Window: 30 * time.Second},
-		LimitAudit:         {Max: 50, Window: 1 * time.Minute},
-		LimitReset:         {Max: 3, Window: 1 * time.Hour},
-		LimitNotifications: {Max: 30, Window: 1 * time.Minute},
-	}
-
-	cfg, ok := presets[group]
+	cfg, ok := limitPresets[group]
 	if !ok {
-		cfg = presets[LimitGlobal]
+		cfg = limitPresets[LimitGlobal]
 	}
 
 	return limiter.New(limiter.Config{
@@ -69,7 +80,7 @@ func createLimiter(group LimitGroup, storage fiber.Storage) fiber.Handler {
 		Expiration: cfg.Window,
 		Storage:    storage,
 		KeyGenerator: func(c *fiber.Ctx) string {
-			return c.IP()
+			return reqctx.ClientIP(c)
 		},
 		LimitReached: func(c *fiber.Ctx) error {
 			return errors.SendAppError(c, errors.ErrTooManyRequests)
@@ -11,14 +11,19 @@ var validate = validator.New()
 
 // Mapa komunikatów błędów - Single Source of Truth
 var errorMessages = map[string]string{
-	"required":     "This field is required",
-	"min":          "Minimum length not met",
-	"max":          "Maximum length exceeded",
-	"len":          "Must be exactly 6 characters",
-	"alphanum":     "Can only contain letters and numbers",
-	"email":        "Invalid email address",
-	"passwd":       "Password must be at least 8 chars, include uppercase, lowercase, number and special character",
-	"numeric_byte": "This field must contain only digits",
+	"required":         "This field is required",
+	"min":              "Minimum length not met",
+	"max":              "Maximum length exceeded",
+	"len":              "Must be exactly 6 characters",
+	"alphanum":         "Can only contain letters and numbers",
+	"email":            "Invalid email address",
+	"passwd":           "Password must be at least 8 chars, include uppercase, lowercase, number and special character",
+	"numeric_byte":     "This field must contain only digits",
+	"required_with":    "This field is required together with a related field",
+	"required_without": "This field is required when its alternative is not provided",
+	"required_if":      "This field is required for the selected value",
+	"oneof":            "Value is not one of the allowed options",
+	"uuid":             "Invalid identifier format",
 }
 
 func init() {
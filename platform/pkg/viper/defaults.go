@@ -13,6 +13,9 @@ func SetSharedDefaults(serviceName string) {
 	viper.SetDefault("PREFORK", false)
 	viper.SetDefault("CASE_SENSITIVE", true)
 	viper.SetDefault("STRICT_ROUTING", false)
+	// CIDR lub adresy; nagłówek: X-Forwarded-For albo Forwarded (RFC 7239)
+	viper.SetDefault("TRUSTED_PROXIES", "127.0.0.1,::1")
+	viper.SetDefault("PROXY_HEADER", "X-Forwarded-For")
 
 	viper.SetDefault("CORS_ALLOW_ORIGINS", "*")
 
@@ -60,17 +63,93 @@ func SetSharedDefaults(serviceName string) {
 	viper.SetDefault("PROXY_IDLE_CONN_TIMEOUT", "90s")
 	viper.SetDefault("PROXY_MAX_IDLE_CONNS_PER_HOST", 20)
 	viper.SetDefault("PROXY_REQUEST_TIMEOUT", "30s")
+	viper.SetDefault("PROXY_STREAM_SESSION_CHECK", "30s")
+
+	// Circuit breaker i ponowienia per upstream
+	viper.SetDefault("PROXY_BREAKER_FAILURE_THRESHOLD", 5)
+	viper.SetDefault("PROXY_BREAKER_OPEN_TIMEOUT", "30s")
+	viper.SetDefault("PROXY_BREAKER_HALF_OPEN_PROBES", 2)
+	viper.SetDefault("PROXY_RETRY_MAX", 2)
+	viper.SetDefault("PROXY_RETRY_BACKOFF", "50ms")
+	viper.SetDefault("PROXY_RETRY_BACKOFF_MAX", "500ms")
+	viper.SetDefault("PROXY_RETRY_BUDGET_RATIO", 0.2)
+	viper.SetDefault("PROXY_RETRY_BUDGET_MIN", 10)
+
+	// Tablica tras gatewaya (przeładowywana przy zmianie pliku)
+	viper.SetDefault("GATEWAY_ROUTES_FILE", "routes.yaml")
+	viper.SetDefault("GATEWAY_ROUTES_WATCH", true)
+	viper.SetDefault("GATEWAY_OPENAPI_DIR", "openapi")
 
 	// Session
 	viper.SetDefault("REDIS_SESSION_TTL", "60m")
+	viper.SetDefault("REDIS_SESSION_ABSOLUTE_TTL", "12h")
+	viper.SetDefault("REDIS_SESSION_MAX_PER_USER", 5)
+
+	// Login guard (wyzwanie anty-botowe przed blokadą konta)
+	viper.SetDefault("LOGIN_CHALLENGE_THRESHOLD", 3)
+	viper.SetDefault("LOGIN_CHALLENGE_WINDOW", "15m")
+	viper.SetDefault("LOGIN_CHALLENGE_MODE", "pow")
+	viper.SetDefault("LOGIN_CHALLENGE_TTL", "2m")
+	viper.SetDefault("LOGIN_POW_DIFFICULTY", 20)
+	viper.SetDefault("LOGIN_CAPTCHA_PROVIDER", "stub")
+	viper.SetDefault("LOGIN_CAPTCHA_STUB_TOKEN", "")
+	viper.SetDefault("LOGIN_CAPTCHA_SECRET", "")
+	viper.SetDefault("LOGIN_CAPTCHA_VERIFY_URL", "")
+	viper.SetDefault("LOGIN_CAPTCHA_TIMEOUT", "5s")
 
 	// Services URLs
 	viper.SetDefault("SERVICE_AUTH_URL", "http://localhost:8082")
 	viper.SetDefault("SERVICE_DOCS_URL", "http://localhost:8083")
 	viper.SetDefault("SERVICE_NOTIFY_URL", "http://localhost:8084")
 	viper.SetDefault("SERVICE_USERS_URL", "http://localhost:3000")
+	viper.SetDefault("SERVICE_VERSION_URL", "http://localhost:3005")
+	viper.SetDefault("SERVICE_LB_STRATEGY", "round_robin")
+	viper.SetDefault("SERVICE_HEALTH_INTERVAL", "10s")
+	viper.SetDefault("SERVICE_EJECT_AFTER", 3)
+	viper.SetDefault("SERVICE_EJECT_DURATION", "30s")
+
+	// mTLS gateway <-> serwisy (wyłączone – ruch wewnętrzny chroni wtedy tylko HMAC kontekstu)
+	viper.SetDefault("MTLS_ENABLED", false)
+	viper.SetDefault("MTLS_CERT_FILE", "")
+	viper.SetDefault("MTLS_KEY_FILE", "")
+	viper.SetDefault("MTLS_CA_FILE", "")
+	viper.SetDefault("MTLS_CLIENT_NAMES", "gateway")
+	viper.SetDefault("MTLS_UPSTREAM_IDENTITIES", "")
+	viper.SetDefault("MTLS_RELOAD_INTERVAL", "1m")
+
+	// Admin API gatewaya (wyłączone; adres tylko lokalny – dostęp przez bastion / port-forward)
+	viper.SetDefault("ADMIN_ENABLED", false)
+	viper.SetDefault("ADMIN_ADDRESS", "127.0.0.1:8090")
+	viper.SetDefault("ADMIN_ROLES", "ADMIN")
+	viper.SetDefault("ADMIN_SYNC_INTERVAL", "5s")
+	viper.SetDefault("ADMIN_ALLOW_CIDRS", "")
+
+	// Filtrowanie sieci w gatewayu (brak list i bazy geo = wyłączone)
+	viper.SetDefault("NETWORK_DENY_CIDRS", "")
+	viper.SetDefault("GEOIP_DATABASE", "")
+	viper.SetDefault("GEOIP_DENY_COUNTRIES", "")
+
+	// Reputacja IP: 20 nieudanych logowań albo 8 sfałszowanych tokenów w ciągu godziny = 15 min blokady
+	viper.SetDefault("REPUTATION_ENABLED", true)
+	viper.SetDefault("REPUTATION_WINDOW", "1h")
+	viper.SetDefault("REPUTATION_BAN_THRESHOLD", 200)
+	viper.SetDefault("REPUTATION_BAN_DURATION", "15m")
+	viper.SetDefault("REPUTATION_FAILED_LOGIN_POINTS", 10)
+	viper.SetDefault("REPUTATION_INVALID_SIGNATURE_POINTS", 25)
+
+	viper.SetDefault("WEB_SESSION_ENABLED", false)
+	viper.SetDefault("WEB_SESSION_CSRF_SECRET", "")
+
+	// Dowody urządzenia (DPoP) – wyłączone do czasu aktualizacji aplikacji mobilnych
+	viper.SetDefault("DEVICE_PROOF_MODE", "off")
+	viper.SetDefault("DEVICE_PROOF_MAX_AGE", "60s")
 
 	viper.SetDefault("INTERNAL_HMAC_SECRET", "")
+	viper.SetDefault("INTERNAL_HMAC_KEY_ID", "k1")
+	viper.SetDefault("INTERNAL_HMAC_ACCEPTED_KEYS", "")
+	viper.SetDefault("INTERNAL_CONTEXT_TTL", "30s")
+	viper.SetDefault("INTERNAL_AUDIENCE", "")
+	viper.SetDefault("INTERNAL_STRICT", false)
 	viper.SetDefault("INTERNAL_ENCRYPTION_KEY", "")
 	viper.SetDefault("INTERNAL_HASH_SALT", "")
 }
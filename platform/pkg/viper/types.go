@@ -5,16 +5,36 @@ import (
 	"time"
 )
 
+// ServicesConfig – każdy serwis może mieć kilka instancji (lista URL oddzielona przecinkami)
 type ServicesConfig struct {
-	Auth      string `mapstructure:"SERVICE_AUTH_URL" validate:"required,url"`
-	Documents string `mapstructure:"SERVICE_DOCS_URL" validate:"required,url"`
-	Notify    string `mapstructure:"SERVICE_NOTIFY_URL" validate:"required,url"`
-	Users     string `mapstructure:"SERVICE_USERS_URL" validate:"required,url"`
+	Auth      []string `mapstructure:"SERVICE_AUTH_URL" validate:"required,min=1,dive,url"`
+	Documents []string `mapstructure:"SERVICE_DOCS_URL" validate:"required,min=1,dive,url"`
+	Notify    []string `mapstructure:"SERVICE_NOTIFY_URL" validate:"required,min=1,dive,url"`
+	Users     []string `mapstructure:"SERVICE_USERS_URL" validate:"required,min=1,dive,url"`
+	Version   []string `mapstructure:"SERVICE_VERSION_URL" validate:"required,min=1,dive,url"`
+
+	// Wybór instancji: round_robin | least_conn | consistent_hash (po ID użytkownika)
+	Balancer string `mapstructure:"SERVICE_LB_STRATEGY" validate:"oneof=round_robin least_conn consistent_hash"`
+	// Aktywne sondowanie /health każdej instancji
+	HealthInterval time.Duration `mapstructure:"SERVICE_HEALTH_INTERVAL" validate:"required"`
+	// Pasywne wyłączenie instancji po EjectAfter kolejnych błędach na EjectDuration
+	EjectAfter    int           `mapstructure:"SERVICE_EJECT_AFTER" validate:"min=1"`
+	EjectDuration time.Duration `mapstructure:"SERVICE_EJECT_DURATION" validate:"required"`
 }
 
 type InternalSecurityConfig struct {
 	// HMAC musi mieć co najmniej 32 znaki dla realnego bezpieczeństwa (używany do podpisów)
 	HMACSecret string `mapstructure:"INTERNAL_HMAC_SECRET" validate:"required,min=32"`
+	// KeyID – identyfikator klucza INTERNAL_HMAC_SECRET w podpisanej kopercie (kid)
+	KeyID string `mapstructure:"INTERNAL_HMAC_KEY_ID" validate:"required"`
+	// AcceptedKeys – dodatkowe klucze akceptowane przy weryfikacji ("kid:sekret"), na czas rotacji
+	AcceptedKeys []string `mapstructure:"INTERNAL_HMAC_ACCEPTED_KEYS"`
+	// ContextTTL – ważność podpisanego kontekstu wystawianego przez gateway
+	ContextTTL time.Duration `mapstructure:"INTERNAL_CONTEXT_TTL" validate:"required"`
+	// Audience – nazwy serwisu w gatewayu (upstream w routes.yaml), dla których kontekst jest przyjmowany
+	Audience []string `mapstructure:"INTERNAL_AUDIENCE"`
+	// Strict – serwis odrzuca żądania bez podpisanego kontekstu (poza /health i /openapi.json)
+	Strict bool `mapstructure:"INTERNAL_STRICT"`
 
 	// EncryptionKey musi mieć dokładnie 32 znaki dla AES-256 (szyfrowanie danych PII)
 	EncryptionKey string `mapstructure:"INTERNAL_ENCRYPTION_KEY" validate:"required,len=32"`
@@ -23,6 +43,74 @@ type InternalSecurityConfig struct {
 	HashSalt string `mapstructure:"INTERNAL_HASH_SALT" validate:"omitempty,min=16"`
 }
 
+// MTLSConfig – wzajemne TLS w ruchu gateway -> serwisy. Pliki są sprawdzane co
+// ReloadInterval, więc rotacja certyfikatów nie wymaga restartu.
+type MTLSConfig struct {
+	Enabled  bool   `mapstructure:"MTLS_ENABLED"`
+	CertFile string `mapstructure:"MTLS_CERT_FILE" validate:"required_if=Enabled true"`
+	KeyFile  string `mapstructure:"MTLS_KEY_FILE" validate:"required_if=Enabled true"`
+	// CAFile – wewnętrzne CA, którym weryfikujemy certyfikat drugiej strony
+	CAFile string `mapstructure:"MTLS_CA_FILE" validate:"required_if=Enabled true"`
+	// ClientNames – (serwisy) tożsamości klientów wpuszczanych przez serwis (SAN DNS), np. gateway
+	ClientNames []string `mapstructure:"MTLS_CLIENT_NAMES"`
+	// UpstreamIdentities – (gateway) oczekiwana tożsamość serwisu: auth=auth-service,documents=citizen-docs;
+	// serwis bez wpisu jest weryfikowany po nazwie hosta z URL
+	UpstreamIdentities []string      `mapstructure:"MTLS_UPSTREAM_IDENTITIES"`
+	ReloadInterval     time.Duration `mapstructure:"MTLS_RELOAD_INTERVAL" validate:"required"`
+}
+
+// AdminConfig – admin API gatewaya na osobnym adresie (domyślnie tylko loopback).
+// Dostęp: JWT + sesja Redis + jedna z ról Roles; każda akcja trafia do audytu.
+type AdminConfig struct {
+	Enabled bool     `mapstructure:"ADMIN_ENABLED"`
+	Address string   `mapstructure:"ADMIN_ADDRESS" validate:"required_if=Enabled true"`
+	Roles   []string `mapstructure:"ADMIN_ROLES" validate:"required_if=Enabled true"`
+	// SyncInterval – co ile instancja stosuje zmiany operatorów zapisane w Redis (drain, breaker, maintenance)
+	SyncInterval time.Duration `mapstructure:"ADMIN_SYNC_INTERVAL" validate:"required"`
+	// AllowCIDRs – sieci, z których wolno połączyć się z admin API (puste = bez ograniczeń)
+	AllowCIDRs []string `mapstructure:"ADMIN_ALLOW_CIDRS"`
+}
+
+// NetworkConfig – filtrowanie ruchu w gatewayu po adresie klienta (przed routingiem)
+type NetworkConfig struct {
+	// DenyCIDRs – adresy i sieci odrzucane zawsze (403)
+	DenyCIDRs []string `mapstructure:"NETWORK_DENY_CIDRS"`
+	// GeoIPDatabase – plik MMDB z krajami (np. GeoLite2-Country); puste = reguły krajów wyłączone
+	GeoIPDatabase string `mapstructure:"GEOIP_DATABASE"`
+	// DenyCountries – kody ISO 3166-1 alpha-2 odrzucane na wszystkich trasach
+	DenyCountries []string `mapstructure:"GEOIP_DENY_COUNTRIES"`
+}
+
+// ReputationConfig – punkty reputacji adresów IP (wspólne dla gatewaya i auth-service)
+// i tymczasowa blokada adresu po przekroczeniu progu
+type ReputationConfig struct {
+	Enabled bool `mapstructure:"REPUTATION_ENABLED"`
+	// Window – punkty wygasają po tym czasie od pierwszego przewinienia
+	Window       time.Duration `mapstructure:"REPUTATION_WINDOW" validate:"required"`
+	BanThreshold int           `mapstructure:"REPUTATION_BAN_THRESHOLD" validate:"min=1"`
+	BanDuration  time.Duration `mapstructure:"REPUTATION_BAN_DURATION" validate:"required"`
+	// Punkty za przewinienia: nieudane logowanie (auth-service), token z błędnym podpisem (gateway)
+	FailedLoginPoints      int `mapstructure:"REPUTATION_FAILED_LOGIN_POINTS" validate:"min=0"`
+	InvalidSignaturePoints int `mapstructure:"REPUTATION_INVALID_SIGNATURE_POINTS" validate:"min=0"`
+}
+
+// WebSessionConfig – tryb przeglądarkowy gatewaya: sesja w ciasteczku HttpOnly zamiast
+// tokenów bearer i token CSRF na metodach modyfikujących
+type WebSessionConfig struct {
+	Enabled bool `mapstructure:"WEB_SESSION_ENABLED"`
+	// Klucz HMAC tokenów CSRF (min. 32 znaki)
+	CSRFSecret string `mapstructure:"WEB_SESSION_CSRF_SECRET" validate:"required_if=Enabled true"`
+}
+
+// DeviceProofConfig – dowody posiadania klucza urządzenia (nagłówek DPoP) dla sesji
+// powiązanych z zarejestrowanym urządzeniem
+type DeviceProofConfig struct {
+	// off – bez weryfikacji; report – weryfikacja tylko w logach i metrykach; enforce – odrzucanie
+	Mode string `mapstructure:"DEVICE_PROOF_MODE" validate:"oneof=off report enforce"`
+	// MaxAge – maksymalny wiek dowodu (iat); tyle też pamiętane są zużyte nonce
+	MaxAge time.Duration `mapstructure:"DEVICE_PROOF_MAX_AGE" validate:"required"`
+}
+
 type OTELConfig struct {
 	Enabled     bool   `mapstructure:"OTEL_ENABLED"`
 	Endpoint    string `mapstructure:"OTEL_ENDPOINT" validate:"required_if=Enabled true"`
@@ -46,10 +134,60 @@ type ProxyConfig struct {
 	IdleConnTimeout     time.Duration `mapstructure:"PROXY_IDLE_CONN_TIMEOUT"`
 	MaxIdleConnsPerHost int           `mapstructure:"PROXY_MAX_IDLE_CONNS_PER_HOST" validate:"min=1"`
 	RequestTimeout      time.Duration `mapstructure:"PROXY_REQUEST_TIMEOUT"`
+	// StreamSessionCheck – co ile gateway sprawdza sesje otwartych połączeń WebSocket / SSE
+	StreamSessionCheck time.Duration `mapstructure:"PROXY_STREAM_SESSION_CHECK" validate:"required"`
 }
 
 type SessionConfig struct {
+	// TTL to okno bezczynności – przesuwane przy każdym żądaniu
 	TTL time.Duration `mapstructure:"REDIS_SESSION_TTL" validate:"required"`
+	// AbsoluteTTL to twardy limit życia sesji liczony od zalogowania
+	AbsoluteTTL time.Duration `mapstructure:"REDIS_SESSION_ABSOLUTE_TTL" validate:"required,gtefield=TTL"`
+	// MaxPerUser ogranicza liczbę równoległych sesji (0 = bez limitu)
+	MaxPerUser int `mapstructure:"REDIS_SESSION_MAX_PER_USER" validate:"min=0"`
+}
+
+// LoginGuardConfig steruje wyzwaniem anty-botowym przy logowaniu (PoW / CAPTCHA)
+type LoginGuardConfig struct {
+	Threshold     int           `mapstructure:"LOGIN_CHALLENGE_THRESHOLD" validate:"min=1"`
+	Window        time.Duration `mapstructure:"LOGIN_CHALLENGE_WINDOW" validate:"required"`
+	Mode          string        `mapstructure:"LOGIN_CHALLENGE_MODE" validate:"oneof=pow captcha"`
+	ChallengeTTL  time.Duration `mapstructure:"LOGIN_CHALLENGE_TTL" validate:"required"`
+	PoWDifficulty int           `mapstructure:"LOGIN_POW_DIFFICULTY" validate:"min=8,max=32"`
+
+	// CAPTCHA (tylko Mode=captcha): dostawca stub (tylko poza produkcją, akceptuje CaptchaStubToken), turnstile albo
+	// hcaptcha (weryfikacja sekretem w siteverify; pusty VerifyURL = domyślny adres dostawcy)
+	CaptchaProvider  string        `mapstructure:"LOGIN_CAPTCHA_PROVIDER" validate:"oneof=stub turnstile hcaptcha"`
+	CaptchaStubToken string        `mapstructure:"LOGIN_CAPTCHA_STUB_TOKEN"`
+	CaptchaSecret    string        `mapstructure:"LOGIN_CAPTCHA_SECRET"`
+	CaptchaVerifyURL string        `mapstructure:"LOGIN_CAPTCHA_VERIFY_URL"`
+	CaptchaTimeout   time.Duration `mapstructure:"LOGIN_CAPTCHA_TIMEOUT" validate:"required"`
+}
+
+// ResilienceConfig steruje circuit breakerem i ponowieniami per upstream w gatewayu
+type ResilienceConfig struct {
+	// Liczba kolejnych porażek otwierająca breaker
+	FailureThreshold int `mapstructure:"PROXY_BREAKER_FAILURE_THRESHOLD" validate:"min=1"`
+	// Czas w stanie open, po którym breaker wpuszcza próbne żądania (half-open)
+	OpenTimeout time.Duration `mapstructure:"PROXY_BREAKER_OPEN_TIMEOUT" validate:"required"`
+	// Liczba próbnych żądań w half-open (tyle sukcesów zamyka breaker)
+	HalfOpenProbes int `mapstructure:"PROXY_BREAKER_HALF_OPEN_PROBES" validate:"min=1"`
+
+	// Dodatkowe próby dla metod idempotentnych (0 = bez ponowień)
+	RetryMax        int           `mapstructure:"PROXY_RETRY_MAX" validate:"min=0,max=5"`
+	RetryBackoff    time.Duration `mapstructure:"PROXY_RETRY_BACKOFF" validate:"required"`
+	RetryBackoffMax time.Duration `mapstructure:"PROXY_RETRY_BACKOFF_MAX" validate:"required,gtefield=RetryBackoff"`
+	// Budżet ponowień: max udział ponowień w ruchu (okno 10s) i minimum zawsze dostępne
+	RetryBudgetRatio float64 `mapstructure:"PROXY_RETRY_BUDGET_RATIO" validate:"min=0,max=1"`
+	RetryBudgetMin   int     `mapstructure:"PROXY_RETRY_BUDGET_MIN" validate:"min=0"`
+}
+
+// RoutesConfig wskazuje deklaratywną tablicę tras gatewaya (YAML)
+type RoutesConfig struct {
+	File  string `mapstructure:"GATEWAY_ROUTES_FILE"`
+	Watch bool   `mapstructure:"GATEWAY_ROUTES_WATCH"`
+	// OpenAPIDir – katalog specyfikacji serwisów (<upstream>.yaml); pusty = bez walidacji
+	OpenAPIDir string `mapstructure:"GATEWAY_OPENAPI_DIR"`
 }
 
 type ServerConfig struct {
@@ -65,6 +203,9 @@ type ServerConfig struct {
 	IdleTimeout   time.Duration `mapstructure:"IDLE_TIMEOUT"`
 	ReadTimeout   time.Duration `mapstructure:"READ_TIMEOUT"`
 	WriteTimeout  time.Duration `mapstructure:"WRITE_TIMEOUT"`
+	// Proxy (ingress, load balancer), którym wierzymy w nagłówek adresu klienta
+	TrustedProxies []string `mapstructure:"TRUSTED_PROXIES"`
+	ProxyHeader    string   `mapstructure:"PROXY_HEADER" validate:"oneof=X-Forwarded-For Forwarded"`
 }
 
 type RedisConfig struct {
@@ -86,17 +227,26 @@ type JWTConfig struct {
 }
 
 type Config struct {
-	Server    ServerConfig           `mapstructure:",squash"`
-	Redis     RedisConfig            `mapstructure:",squash"`
-	Session   SessionConfig          `mapstructure:",squash"`
-	Proxy     ProxyConfig            `mapstructure:",squash"`
-	CORSAllow string                 `mapstructure:"CORS_ALLOW_ORIGINS" validate:"required"`
-	Shutdown  time.Duration          `mapstructure:"SHUTDOWN_TIMEOUT" validate:"required"`
-	JWT       JWTConfig              `mapstructure:",squash"`
-	OTEL      OTELConfig             `mapstructure:",squash"`
-	Internal  InternalSecurityConfig `mapstructure:",squash"`
-	Services  ServicesConfig         `mapstructure:",squash"`
-	Database  DBConfig               `mapstructure:",squash"`
+	Server      ServerConfig           `mapstructure:",squash"`
+	Redis       RedisConfig            `mapstructure:",squash"`
+	Session     SessionConfig          `mapstructure:",squash"`
+	LoginGuard  LoginGuardConfig       `mapstructure:",squash"`
+	Proxy       ProxyConfig            `mapstructure:",squash"`
+	Routes      RoutesConfig           `mapstructure:",squash"`
+	Resilience  ResilienceConfig       `mapstructure:",squash"`
+	CORSAllow   string                 `mapstructure:"CORS_ALLOW_ORIGINS" validate:"required"`
+	Shutdown    time.Duration          `mapstructure:"SHUTDOWN_TIMEOUT" validate:"required"`
+	JWT         JWTConfig              `mapstructure:",squash"`
+	OTEL        OTELConfig             `mapstructure:",squash"`
+	Internal    InternalSecurityConfig `mapstructure:",squash"`
+	Services    ServicesConfig         `mapstructure:",squash"`
+	MTLS        MTLSConfig             `mapstructure:",squash"`
+	Admin       AdminConfig            `mapstructure:",squash"`
+	Network     NetworkConfig          `mapstructure:",squash"`
+	Reputation  ReputationConfig       `mapstructure:",squash"`
+	WebSession  WebSessionConfig       `mapstructure:",squash"`
+	DeviceProof DeviceProofConfig      `mapstructure:",squash"`
+	Database    DBConfig               `mapstructure:",squash"`
 }
 
 // GetDSN tworzy string połączenia dla GORM/Postgres
@@ -29,7 +29,10 @@ func main() {
 	defer closeDB()
 
 	// Initialize DI container
-	container := di.NewContainer(db, nil, log, &config.AppConfig)
+	container, err := di.NewContainer(db, nil, log, &config.AppConfig)
+	if err != nil {
+		log.Fatal("Container setup failed", "error", err)
+	}
 
 	// Start background worker
 	utils.SafeGo(log, container.AuditWorker.Start)
@@ -38,6 +41,12 @@ func main() {
 	app := config.NewAuditApp(container)
 	router.SetupRoutes(app, container)
 
+	// MTLS_ENABLED – serwis przyjmuje tylko połączenia z certyfikatem klienta (gateway)
+	tlsConfig, certs, err := server.MutualTLS(config.AppConfig.MTLS)
+	if err != nil {
+		log.Fatal("mTLS setup failed", "error", err)
+	}
+
 	// Start server with unified run handler
 	server.Run(
 		app,
@@ -47,10 +56,12 @@ func main() {
 			AppVersion: config.AppConfig.Server.AppVersion,
 			Env:        config.AppConfig.Server.Env,
 			Shutdown:   config.AppConfig.Shutdown,
+			TLS:        tlsConfig,
 		},
 		*log,
 		func() {
 			closeDB()
+			certs.Close()
 			// Additional resource cleanup can be added here
 		},
 	)
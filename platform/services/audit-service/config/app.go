@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/zerodayz7/platform/pkg/middleware"
 	"github.com/zerodayz7/platform/pkg/server"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/audit-service/internal/di"
@@ -26,7 +27,7 @@ func NewAuditApp(container *di.Container) *fiber.App {
 
 		ProxyHeader:             fiber.HeaderXForwardedFor,
 		EnableTrustedProxyCheck: true,
-		TrustedProxies:          []string{"127.0.0.1", "::1"},
+		TrustedProxies:          cfg.TrustedProxies, // adres gatewaya (TRUSTED_PROXIES)
 		BodyLimit:               cfg.BodyLimitMB * 1024 * 1024,
 		DisableStartupMessage:   true,
 		EnableIPValidation:      true,
@@ -41,6 +42,12 @@ func NewAuditApp(container *di.Container) *fiber.App {
 	app.Use(recover.New())
 	app.Use(shared.GetLimiter(shared.LimitGlobal, nil))
 	app.Use(shared.RequestLoggerMiddleware())
+	// 3. Podpisany kontekst gatewaya (odczyt audytu tylko przez gateway, z rolą z sesji)
+	app.Use(middleware.InternalAuthMiddleware(middleware.InternalAuthConfig{
+		Keys:     container.InternalKeys,
+		Audience: container.Config.Internal.Audience,
+		Strict:   container.Config.Internal.Strict,
+	}))
 
 	return app
 }
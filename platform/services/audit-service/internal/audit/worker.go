@@ -6,12 +6,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/zerodayz7/platform/pkg/constants"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/shared"
 )
 
 const (
-	auditStream   = "audit_stream"
+	auditStream   = constants.StreamAudit
 	auditGroup    = "audit_service_group"
 	auditConsumer = "worker_1"
 	batchSize     = 100
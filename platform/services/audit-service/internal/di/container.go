@@ -1,7 +1,10 @@
 package di
 
 import (
+	"fmt"
+
 	"github.com/jackc/pgx/v5/pgxpool"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/pkg/viper"
@@ -13,12 +16,13 @@ type Container struct {
 	AuditHandler *audit.AuditHandler
 	AuditWorker  *audit.AuditWorker
 	Redis        *redis.Client
+	InternalKeys *reqctx.Keyring
 	Logger       *shared.Logger
 	Config       *viper.Config
 }
 
 // NewContainer teraz przyjmuje cfg, aby wstrzyknąć go do aplikacji i handlerów.
-func NewContainer(dbPool *pgxpool.Pool, redisClient *redis.Client, logger *shared.Logger, cfg *viper.Config) *Container {
+func NewContainer(dbPool *pgxpool.Pool, redisClient *redis.Client, logger *shared.Logger, cfg *viper.Config) (*Container, error) {
 	// 1. Inicjalizacja zapytań sqlc
 	queries := dbgen.New(dbPool)
 
@@ -31,11 +35,18 @@ func NewContainer(dbPool *pgxpool.Pool, redisClient *redis.Client, logger *share
 	// 4. Worker (procesy asynchroniczne Redis)
 	auditW := audit.NewAuditWorker(redisClient, auditSvc, logger)
 
+	// 5. Klucze kontekstu podpisanego przez gateway
+	internalKeys, err := reqctx.NewKeyring(cfg.Internal.KeyID, []byte(cfg.Internal.HMACSecret), cfg.Internal.AcceptedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("INTERNAL_HMAC_ACCEPTED_KEYS: %w", err)
+	}
+
 	return &Container{
 		AuditHandler: auditH,
 		AuditWorker:  auditW,
 		Redis:        redisClient,
+		InternalKeys: internalKeys,
 		Logger:       logger,
 		Config:       cfg, // Mapowanie przekazanego configu
-	}
+	}, nil
 }
@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	pkgRouter "github.com/zerodayz7/platform/pkg/router"
 	"github.com/zerodayz7/platform/pkg/router/health"
 	"github.com/zerodayz7/platform/pkg/shared"
@@ -20,6 +21,11 @@ func SetupRoutes(app *fiber.App, container *di.Container) {
 	}
 	health.RegisterRoutes(app, checker)
 
+	docs := openapi.New(app, openapi.Info{
+		Title:   "audit-service",
+		Version: container.Config.Server.AppVersion,
+	})
+
 	// 3. Grupa audit z dedykowanym limiterem.
 	auditGroup := app.Group("/audit")
 	{
@@ -40,6 +46,9 @@ func SetupRoutes(app *fiber.App, container *di.Container) {
 		auditGroup.Get("/action/:action", h.ListLogsByAction)
 	}
 
+	// GET /openapi.json – trasy zarejestrowane powyżej (pkg/openapi)
+	docs.RegisterRoutes()
+
 	// 4. Uniwersalne Fallback Handlery (404, favicon itp.) z pkg.
 	pkgRouter.SetupFallbackHandlers(app)
 }
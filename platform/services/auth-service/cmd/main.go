@@ -36,14 +36,23 @@ func main() {
 	}
 	defer redisClient.Close()
 
-	db, closeDB := config.MustInitDB(config.AppConfig.Database)
+	db, closeDB := config.MustInitDB(config.AppConfig.Database, config.AppConfig.Server.Env)
 	defer closeDB()
 
-	container := di.NewContainer(db, redisClient, &config.AppConfig)
+	container, err := di.NewContainer(db, redisClient, &config.AppConfig)
+	if err != nil {
+		log.Fatal("Container setup failed", "error", err)
+	}
 	app := config.NewAuthApp(container)
 
 	router.SetupRoutes(app, container)
 
+	// MTLS_ENABLED – serwis przyjmuje tylko połączenia z certyfikatem klienta (gateway)
+	tlsConfig, certs, err := server.MutualTLS(config.AppConfig.MTLS)
+	if err != nil {
+		log.Fatal("mTLS setup failed", "error", err)
+	}
+
 	server.Run(
 		app,
 		server.Config{
@@ -52,10 +61,12 @@ func main() {
 			AppVersion: config.AppConfig.Server.AppVersion,
 			Env:        config.AppConfig.Server.Env,
 			Shutdown:   config.AppConfig.Shutdown,
+			TLS:        tlsConfig,
 		},
 		*log,
 		func() {
 			closeDB()
+			certs.Close()
 			_ = redisClient.Close()
 		},
 	)
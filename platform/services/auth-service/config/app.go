@@ -26,7 +26,7 @@ func NewAuthApp(container *di.Container) *fiber.App {
 
 		ProxyHeader:             fiber.HeaderXForwardedFor,
 		EnableTrustedProxyCheck: true,
-		TrustedProxies:          []string{"127.0.0.1", "::1"},
+		TrustedProxies:          cfg.TrustedProxies, // adres gatewaya (TRUSTED_PROXIES)
 		BodyLimit:               cfg.BodyLimitMB * 1024 * 1024,
 		DisableStartupMessage:   true,
 		EnableIPValidation:      true,
@@ -42,7 +42,12 @@ func NewAuthApp(container *di.Container) *fiber.App {
 	app.Use(recover.New())
 	app.Use(shared.GetLimiter(shared.LimitGlobal, nil))
 	app.Use(shared.RequestLoggerMiddleware())
-	app.Use(middleware.InternalAuthMiddleware(container.InternalSecret))
+	app.Use(middleware.InternalAuthMiddleware(middleware.InternalAuthConfig{
+		Keys:     container.InternalKeys,
+		Audience: container.Config.Internal.Audience,
+		Strict:   container.Config.Internal.Strict,
+		Nonces:   container.Cache,
+	}))
 
 	return app
 }
@@ -8,6 +8,7 @@ import (
 	// Zmień te ścieżki na Twoje faktyczne ścieżki w projekcie!
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	"github.com/zerodayz7/platform/services/auth-service/internal/shared/security"
 )
 
 func SeedData(db *gorm.DB) error {
@@ -53,3 +54,28 @@ func SeedData(db *gorm.DB) error {
 
 	return nil
 }
+
+// SeedOAuthClients tworzy klienta deweloperskiego dla /oauth/introspect i /oauth/revoke.
+// Sekret: dev-oauth-secret-change-me (tylko do testów lokalnych – w ENV=production pomijany)
+func SeedOAuthClients(db *gorm.DB) error {
+	log := shared.GetLogger()
+
+	secretHash, err := security.HashPassword("dev-oauth-secret-change-me")
+	if err != nil {
+		return fmt.Errorf("failed to hash oauth client secret: %w", err)
+	}
+
+	client := model.OAuthClient{
+		ClientID:   "dev-gateway",
+		SecretHash: secretHash,
+		Name:       "Development client",
+		Scopes:     model.OAuthScopeIntrospect + " " + model.OAuthScopeRevoke,
+		Active:     true,
+	}
+	if err := db.Create(&client).Error; err != nil {
+		return fmt.Errorf("failed to seed oauth client %s: %w", client.ClientID, err)
+	}
+
+	log.Info(fmt.Sprintf("Utworzono klienta OAuth: %s (Scopes: %s)", client.ClientID, client.Scopes))
+	return nil
+}
@@ -7,13 +7,15 @@ import (
 	"gorm.io/gorm"
 )
 
-func MustInitDB(cfg viper.DBConfig) (*gorm.DB, func()) {
+func MustInitDB(cfg viper.DBConfig, env string) (*gorm.DB, func()) {
 	// 1. Inicjalizacja z pkg - przekazujemy modele do migracji
 	db, closeDB, err := database.NewPostgres(cfg,
 		&model.User{},
 		&model.UserPermission{},
 		&model.RefreshToken{},
 		&model.UserDevice{},
+		&model.OAuthClient{},
+		&model.RecoveryCode{},
 	)
 	if err != nil {
 		panic(err)
@@ -25,5 +27,12 @@ func MustInitDB(cfg viper.DBConfig) (*gorm.DB, func()) {
 		panic(err)
 	}
 
+	// Klient OAuth ze znanym sekretem tylko poza produkcją – tam klientów zakłada się ręcznie
+	if env != "production" {
+		if err := database.RunSeed(db, &model.OAuthClient{}, SeedOAuthClients); err != nil {
+			panic(err)
+		}
+	}
+
 	return db, closeDB
 }
@@ -1,38 +1,49 @@
 package di
 
 import (
+	"fmt"
+
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/viper"
 	"gorm.io/gorm"
 )
 
 type Container struct {
-	Repos          *Repositories
-	Services       *Services
-	Handlers       *Handlers
-	Redis          *redis.Client
-	Cache          *redis.Cache
-	InternalSecret []byte
-	Config         *viper.Config
+	Repos        *Repositories
+	Services     *Services
+	Handlers     *Handlers
+	Redis        *redis.Client
+	Cache        *redis.Cache
+	InternalKeys *reqctx.Keyring
+	Config       *viper.Config
 }
 
-func NewContainer(db *gorm.DB, redisClient *redis.Client, cfg *viper.Config) *Container {
+func NewContainer(db *gorm.DB, redisClient *redis.Client, cfg *viper.Config) (*Container, error) {
 	cache := redis.NewCache(
 		redisClient,
 		cfg.Session.TTL,
 	)
 
 	repos := NewRepositories(db)
-	services := NewServices(repos, cache, cfg)
+	services, err := NewServices(repos, cache, cfg)
+	if err != nil {
+		return nil, err
+	}
 	handlers := NewHandlers(services, cache, cfg)
 
-	return &Container{
-		Repos:          repos,
-		Services:       services,
-		Handlers:       handlers,
-		Redis:          redisClient,
-		Cache:          cache,
-		InternalSecret: []byte(cfg.Internal.HMACSecret),
-		Config:         cfg,
+	internalKeys, err := reqctx.NewKeyring(cfg.Internal.KeyID, []byte(cfg.Internal.HMACSecret), cfg.Internal.AcceptedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("INTERNAL_HMAC_ACCEPTED_KEYS: %w", err)
 	}
+
+	return &Container{
+		Repos:        repos,
+		Services:     services,
+		Handlers:     handlers,
+		Redis:        redisClient,
+		Cache:        cache,
+		InternalKeys: internalKeys,
+		Config:       cfg,
+	}, nil
 }
@@ -11,12 +11,16 @@ type Handlers struct {
 	AuthHandler  *handler.AuthHandler
 	ResetHandler *handler.ResetHandler
 	UserHandler  *handler.UserHandler
+	OAuthHandler *handler.OAuthHandler
+	AdminHandler *handler.AdminHandler
 }
 
 func NewHandlers(services *Services, cache *redis.Cache, cfg *viper.Config) *Handlers {
 	return &Handlers{
-		AuthHandler:  handler.NewAuthHandler(services.AuthService, cache, cfg),
+		AuthHandler:  handler.NewAuthHandler(services.AuthService, services.RecoveryService, cache, cfg),
 		ResetHandler: handler.NewResetHandler(services.PasswordResetService, cache),
 		UserHandler:  handler.NewUserHandler(services.UserService),
+		OAuthHandler: handler.NewOAuthHandler(services.OAuthService),
+		AdminHandler: handler.NewAdminHandler(services.AdminService),
 	}
 }
@@ -10,11 +10,15 @@ import (
 type Repositories struct {
 	UserRepo         repo.UserRepository
 	RefreshTokenRepo repo.RefreshTokenRepository
+	OAuthClientRepo  repo.OAuthClientRepository
+	RecoveryCodeRepo repo.RecoveryCodeRepository
 }
 
 func NewRepositories(db *gorm.DB) *Repositories {
 	return &Repositories{
 		UserRepo:         repoDB.NewUserRepository(db),
 		RefreshTokenRepo: repoDB.NewRefreshTokenRepository(db),
+		OAuthClientRepo:  repoDB.NewOAuthClientRepository(db),
+		RecoveryCodeRepo: repoDB.NewRecoveryCodeRepository(db),
 	}
 }
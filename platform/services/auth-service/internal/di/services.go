@@ -1,6 +1,7 @@
 package di
 
 import (
+	"github.com/zerodayz7/platform/pkg/events"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/viper"
 	"github.com/zerodayz7/platform/services/auth-service/internal/service"
@@ -10,15 +11,41 @@ type Services struct {
 	AuthService          service.AuthService
 	UserService          service.UserService
 	PasswordResetService service.PasswordResetService
+	OAuthService         service.OAuthService
+	RecoveryService      service.RecoveryService
+	AdminService         service.AdminService
 }
 
-func NewServices(repos *Repositories, cache *redis.Cache, cfg *viper.Config) *Services {
+func NewServices(repos *Repositories, cache *redis.Cache, cfg *viper.Config) (*Services, error) {
+	captcha, err := service.NewCaptchaVerifier(cfg.LoginGuard, cfg.Server.Env)
+	if err != nil {
+		return nil, err
+	}
+
+	emitter := events.NewEmitter(cache, cfg.Server.AppName)
+	audit := events.NewAuditPublisher(cache, cfg.Server.AppName)
+
+	recoveryService := service.NewRecoveryService(
+		repos.RecoveryCodeRepo,
+		cache,
+		emitter,
+		cfg,
+	)
+
 	return &Services{
 		AuthService: service.NewAuthService(
 			repos.UserRepo,
 			repos.RefreshTokenRepo,
 			cache,
 			cfg,
+			service.NewLoginGuard(
+				cache,
+				cfg.LoginGuard,
+				cfg.Reputation,
+				captcha,
+				audit,
+			),
+			recoveryService,
 		),
 		UserService: service.NewUserService(
 			repos.UserRepo,
@@ -28,6 +55,21 @@ func NewServices(repos *Repositories, cache *redis.Cache, cfg *viper.Config) *Se
 			repos.UserRepo,
 			repos.RefreshTokenRepo,
 			cache,
+			recoveryService,
 		),
-	}
+		OAuthService: service.NewOAuthService(
+			repos.OAuthClientRepo,
+			repos.RefreshTokenRepo,
+			cache,
+			cfg,
+		),
+		RecoveryService: recoveryService,
+		AdminService: service.NewAdminService(
+			repos.UserRepo,
+			repos.RefreshTokenRepo,
+			repos.RecoveryCodeRepo,
+			cache,
+			audit,
+		),
+	}, nil
 }
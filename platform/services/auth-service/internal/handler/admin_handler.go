@@ -0,0 +1,103 @@
+package handler
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/schemas"
+	"github.com/zerodayz7/platform/services/auth-service/internal/service"
+)
+
+type AdminHandler struct {
+	adminService service.AdminService
+}
+
+func NewAdminHandler(adminService service.AdminService) *AdminHandler {
+	return &AdminHandler{adminService: adminService}
+}
+
+// #region SEARCH
+// GET /admin/users?q=&page=&page_size=
+func (h *AdminHandler) SearchUsers(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	query := c.Locals("validatedQuery").(*schemas.AdminUserSearchQuery)
+
+	resp, err := h.adminService.SearchUsers(ctx, *query)
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// #region DETAILS
+// GET /admin/users/:id
+func (h *AdminHandler) GetUser(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	resp, err := h.adminService.GetUserDetails(ctx, adminActor(c), targetUserID(c))
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.JSON(resp)
+}
+
+// #region STATUS
+// PATCH /admin/users/:id/status
+func (h *AdminHandler) ChangeStatus(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	body := c.Locals("validatedBody").(schemas.AdminChangeStatusRequest)
+
+	if err := h.adminService.ChangeStatus(ctx, adminActor(c), targetUserID(c), body); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// #region 2FA RESET
+// POST /admin/users/:id/2fa-reset
+func (h *AdminHandler) Reset2FA(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	body := c.Locals("validatedBody").(schemas.AdminActionRequest)
+
+	if err := h.adminService.Reset2FA(ctx, adminActor(c), targetUserID(c), body.Justification); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// #region PASSWORD RESET
+// POST /admin/users/:id/password-reset
+func (h *AdminHandler) ForcePasswordReset(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 5*time.Second)
+	defer cancel()
+
+	body := c.Locals("validatedBody").(schemas.AdminActionRequest)
+
+	if err := h.adminService.ForcePasswordReset(ctx, adminActor(c), targetUserID(c), body.Justification); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.SendStatus(fiber.StatusNoContent)
+}
+
+// adminActor – RequireRoles gwarantuje obecność UserID w kontekście
+func adminActor(c *fiber.Ctx) service.AdminActor {
+	rc := reqctx.MustFromFiber(c)
+	return service.AdminActor{UserID: *rc.UserID, IP: rc.IP}
+}
+
+// targetUserID – format UUID został już sprawdzony przez ValidateParams
+func targetUserID(c *fiber.Ctx) uuid.UUID {
+	params := c.Locals("validatedParams").(*schemas.AdminUserParams)
+	return uuid.MustParse(params.ID)
+}
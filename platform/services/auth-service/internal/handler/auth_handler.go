@@ -17,16 +17,18 @@ import (
 )
 
 type AuthHandler struct {
-	authService service.AuthService
-	cache       *redis.Cache
-	cfg         *viper.Config
+	authService     service.AuthService
+	recoveryService service.RecoveryService
+	cache           *redis.Cache
+	cfg             *viper.Config
 }
 
-func NewAuthHandler(authService service.AuthService, cache *redis.Cache, cfg *viper.Config) *AuthHandler { // USUNIĘTO *
+func NewAuthHandler(authService service.AuthService, recoveryService service.RecoveryService, cache *redis.Cache, cfg *viper.Config) *AuthHandler { // USUNIĘTO *
 	return &AuthHandler{
-		authService: authService,
-		cache:       cache,
-		cfg:         cfg,
+		authService:     authService,
+		recoveryService: recoveryService,
+		cache:           cache,
+		cfg:             cfg,
 	}
 }
 
@@ -46,7 +48,14 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return apperr.SendAppError(c, apperr.ErrInvalidDeviceFingerprint)
 	}
 
-	response, err := h.authService.AttemptLogin(ctx, body.Email, []byte(body.Password), fingerprint)
+	attempt := service.LoginAttempt{
+		Fingerprint:       fingerprint,
+		IP:                rc.IP,
+		ChallengeToken:    body.ChallengeToken,
+		ChallengeSolution: body.ChallengeSolution,
+	}
+
+	response, err := h.authService.AttemptLogin(ctx, body.Email, []byte(body.Password), attempt)
 	if err != nil {
 		log.WarnObj("Login failed", map[string]any{"email": body.Email, "err": err.Error()})
 		return apperr.SendAppError(c, err)
@@ -136,7 +145,7 @@ func (h *AuthHandler) Verify2FA(c *fiber.Ctx) error {
 		body.Token,
 		body.Code,
 		fingerprint,
-		c.IP(),
+		reqctx.ClientIP(c),
 	)
 	if err != nil {
 		log.WarnObj("2FA failed", map[string]any{"token": body.Token, "err": err.Error()})
@@ -201,6 +210,51 @@ func (h *AuthHandler) Logout(c *fiber.Ctx) error {
 	})
 }
 
+// #region RECOVER 2FA
+// Recover2FA pozwala dokończyć logowanie kodem odzyskiwania zamiast kodu 2FA.
+func (h *AuthHandler) Recover2FA(c *fiber.Ctx) error {
+	log := shared.GetLogger()
+	body := c.Locals("validatedBody").(schemas.TwoFARecoverRequest)
+	fingerprint := c.Get(constants.HeaderDeviceFingerprint)
+
+	response, err := h.authService.Recover2FA(
+		c.Context(),
+		body.Token,
+		body.RecoveryCode,
+		fingerprint,
+		reqctx.ClientIP(c),
+	)
+	if err != nil {
+		log.WarnObj("2FA recovery failed", map[string]any{"token": body.Token, "err": err.Error()})
+		return apperr.SendAppError(c, err)
+	}
+
+	return c.JSON(response)
+}
+
+// #region REGENERATE RECOVERY CODES
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	rc := reqctx.MustFromFiber(c)
+	if rc.UserID == nil {
+		return apperr.SendAppError(c, apperr.ErrUnauthorized)
+	}
+	body := c.Locals("validatedBody").(schemas.RegenerateRecoveryCodesRequest)
+
+	if err := h.authService.ConfirmPassword(c.Context(), *rc.UserID, body.Password); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+
+	codes, err := h.recoveryService.Generate(c.Context(), *rc.UserID, rc.IP)
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+
+	shared.GetLogger().InfoMap("Recovery codes regenerated", map[string]any{"user_id": rc.UserID})
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(http.RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
 // #region REGISTER
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	log := shared.GetLogger()
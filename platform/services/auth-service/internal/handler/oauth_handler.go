@@ -0,0 +1,124 @@
+package handler
+
+import (
+	"context"
+	"encoding/base64"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	service "github.com/zerodayz7/platform/services/auth-service/internal/service"
+)
+
+type OAuthHandler struct {
+	oauthService service.OAuthService
+}
+
+func NewOAuthHandler(oauthService service.OAuthService) *OAuthHandler {
+	return &OAuthHandler{oauthService: oauthService}
+}
+
+// #region INTROSPECT (RFC 7662)
+func (h *OAuthHandler) Introspect(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
+	defer cancel()
+
+	client, err := h.authenticateClient(ctx, c, model.OAuthScopeIntrospect)
+	if err != nil {
+		return sendClientError(c, err)
+	}
+
+	token := c.FormValue("token")
+	if token == "" {
+		return apperr.SendAppError(c, apperr.ErrInvalidRequest)
+	}
+
+	resp, err := h.oauthService.Introspect(ctx, token, c.FormValue("token_type_hint"))
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+
+	shared.GetLogger().DebugInfo("OAuth introspection", map[string]any{
+		"client_id": client.ClientID,
+		"active":    resp.Active,
+	})
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(resp)
+}
+
+// #region REVOKE (RFC 7009)
+func (h *OAuthHandler) Revoke(c *fiber.Ctx) error {
+	ctx, cancel := context.WithTimeout(c.UserContext(), 3*time.Second)
+	defer cancel()
+
+	client, err := h.authenticateClient(ctx, c, model.OAuthScopeRevoke)
+	if err != nil {
+		return sendClientError(c, err)
+	}
+
+	token := c.FormValue("token")
+	if token == "" {
+		return apperr.SendAppError(c, apperr.ErrInvalidRequest)
+	}
+
+	if err := h.oauthService.Revoke(ctx, token, c.FormValue("token_type_hint")); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+
+	shared.GetLogger().InfoMap("OAuth token revocation", map[string]any{"client_id": client.ClientID})
+	return c.SendStatus(fiber.StatusOK)
+}
+
+// authenticateClient obsługuje client_secret_basic oraz client_secret_post.
+func (h *OAuthHandler) authenticateClient(ctx context.Context, c *fiber.Ctx, scope string) (*model.OAuthClient, error) {
+	clientID, secret, ok := parseBasicAuth(c.Get(constants.HeaderAuth))
+	if !ok {
+		clientID = c.FormValue("client_id")
+		secret = c.FormValue("client_secret")
+	}
+
+	client, err := h.oauthService.AuthenticateClient(ctx, clientID, []byte(secret))
+	if err != nil {
+		return nil, err
+	}
+	if !client.HasScope(scope) {
+		return nil, apperr.ErrClientNotAllowed
+	}
+	return client, nil
+}
+
+// parseBasicAuth dekoduje nagłówek Basic; id i sekret są URL-encoded (RFC 6749 §2.3.1).
+func parseBasicAuth(header string) (string, string, bool) {
+	const prefix = "Basic "
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", "", false
+	}
+	raw, err := base64.StdEncoding.DecodeString(header[len(prefix):])
+	if err != nil {
+		return "", "", false
+	}
+	id, secret, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return "", "", false
+	}
+	if id, err = url.QueryUnescape(id); err != nil {
+		return "", "", false
+	}
+	if secret, err = url.QueryUnescape(secret); err != nil {
+		return "", "", false
+	}
+	return id, secret, true
+}
+
+func sendClientError(c *fiber.Ctx, err error) error {
+	if err == apperr.ErrInvalidClient {
+		c.Set(fiber.HeaderWWWAuthenticate, `Basic realm="oauth"`)
+	}
+	return apperr.SendAppError(c, err)
+}
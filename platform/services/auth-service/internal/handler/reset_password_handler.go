@@ -2,6 +2,7 @@ package handler
 
 import (
 	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/errors"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/schemas"
@@ -85,7 +86,7 @@ func (h *ResetHandler) FinalizeReset(c *fiber.Ctx) error {
 		PublicKey:   req.PublicKey,
 		DeviceName:  req.DeviceName,
 		Platform:    req.Platform,
-		IP:          c.IP(), // Pobieramy IP bezpośrednio z kontekstu Fiber
+		IP:          reqctx.ClientIP(c),
 	}
 
 	// 3. Wywołanie logiki biznesowej w serwisie
@@ -94,7 +95,7 @@ func (h *ResetHandler) FinalizeReset(c *fiber.Ctx) error {
 		c.Context(),
 		req.Token,
 		req.Password,
-		req.Signature,
+		service.ResetProof{Signature: req.Signature, RecoveryCode: req.RecoveryCode},
 		device,
 	)
 	// 4. Obsługa błędów z serwisu
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"time"
+
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+)
+
+// AdminUserSummary is a single row in the admin user search results.
+type AdminUserSummary struct {
+	ID               string    `json:"id"`
+	Username         string    `json:"username"`
+	Email            string    `json:"email"`
+	Role             string    `json:"role"`
+	Status           string    `json:"status"`
+	TwoFactorEnabled bool      `json:"two_factor_enabled"`
+	LastLogin        time.Time `json:"last_login"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// AdminUserListResponse is a paginated list of users matching an admin search.
+type AdminUserListResponse struct {
+	Items    []AdminUserSummary `json:"items"`
+	Page     int                `json:"page"`
+	PageSize int                `json:"page_size"`
+	Total    int64              `json:"total"`
+}
+
+// AdminUserDetailsResponse aggregates account state, devices and sessions for support staff.
+type AdminUserDetailsResponse struct {
+	User                AdminUserSummary       `json:"user"`
+	FailedLoginAttempts int8                   `json:"failed_login_attempts"`
+	LockedUntil         *time.Time             `json:"locked_until,omitempty"`
+	MustResetPassword   bool                   `json:"must_reset_password"`
+	LastIP              string                 `json:"last_ip,omitempty"`
+	Devices             []AdminDevice          `json:"devices"`
+	Sessions            []model.UserSessionDTO `json:"sessions"`
+	ActiveSessions      []AdminActiveSession   `json:"active_sessions"`
+}
+
+// AdminDevice describes a paired device without exposing its public key.
+type AdminDevice struct {
+	ID         string    `json:"id"`
+	Platform   string    `json:"platform"`
+	IsActive   bool      `json:"is_active"`
+	IsVerified bool      `json:"is_verified"`
+	LastIP     string    `json:"last_ip,omitempty"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// AdminActiveSession is a live Redis session of the user.
+type AdminActiveSession struct {
+	SessionID  string `json:"sid"`
+	CreatedAt  int64  `json:"created_at"`
+	LastSeenAt int64  `json:"last_seen_at"`
+	IP         string `json:"ip,omitempty"`
+}
@@ -12,6 +12,19 @@ type LoginResponse struct {
 	Challenge     string `json:"challenge,omitempty"`
 	IsTrusted     bool   `json:"is_trusted,omitempty"`
 	ExpiresAt     int64  `json:"expires_at,omitempty"`
+
+	BotChallenge *BotChallenge `json:"bot_challenge,omitempty"`
+}
+
+// BotChallenge describes the anti-automation challenge the client must solve before resubmitting the login.
+// For "pow": find a solution such that sha256(nonce + ":" + solution) has `difficulty` leading zero bits.
+type BotChallenge struct {
+	Kind       string `json:"kind"`
+	Token      string `json:"token"`
+	Nonce      string `json:"nonce,omitempty"`
+	Difficulty int    `json:"difficulty,omitempty"`
+	Provider   string `json:"provider,omitempty"`
+	ExpiresAt  int64  `json:"expires_at"`
 }
 
 // Verify2FAResponse defines the challenge and access data returned after successful 2FA verification.
@@ -31,6 +44,13 @@ type RegisterDeviceResponse struct {
 	IsTrusted    bool           `json:"is_trusted"`
 	User         DeviceUserData `json:"user"`
 	Rbac         map[string]any `json:"rbac"`
+	// RecoveryCodes are present only when a fresh set was generated; they are never returned again.
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+}
+
+// RecoveryCodesResponse returns a newly generated set of one-time recovery codes.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 // DeviceUserData represents a subset of user information included in device registration.
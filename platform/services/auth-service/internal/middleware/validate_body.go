@@ -3,10 +3,13 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	"github.com/zerodayz7/platform/pkg/validator"
 )
 
 func ValidateBody[T any]() fiber.Handler {
+	openapi.ExpectBody[T]()
+
 	return func(c *fiber.Ctx) error {
 		var body T
 		if err := c.BodyParser(&body); err != nil {
@@ -3,10 +3,13 @@ package middleware
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	"github.com/zerodayz7/platform/pkg/validator"
 )
 
 func ValidateQuery[T any]() fiber.Handler {
+	openapi.ExpectQuery[T]()
+
 	return func(c *fiber.Ctx) error {
 		query := new(T)
 		if err := c.QueryParser(query); err != nil {
@@ -0,0 +1,45 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"gorm.io/gorm"
+)
+
+// Uprawnienia klienta OAuth do endpointów /oauth/*
+const (
+	OAuthScopeIntrospect = "introspect"
+	OAuthScopeRevoke     = "revoke"
+)
+
+// OAuthClient to serwis lub aplikacja partnerska uwierzytelniana przez client credentials
+type OAuthClient struct {
+	ID         uuid.UUID `gorm:"type:uuid;primaryKey"`
+	ClientID   string    `gorm:"size:64;not null;uniqueIndex"`
+	SecretHash string    `gorm:"size:255;not null"` // Argon2id, jak hasła użytkowników
+	Name       string    `gorm:"size:128;not null"`
+	Scopes     string    `gorm:"size:255;not null"` // rozdzielone spacją, np. "introspect revoke"
+	Active     bool      `gorm:"default:true;index"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+	UpdatedAt  time.Time `gorm:"autoUpdateTime"`
+}
+
+// HasScope sprawdza, czy klient ma przyznane dane uprawnienie
+func (c *OAuthClient) HasScope(scope string) bool {
+	for _, s := range strings.Fields(c.Scopes) {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// Hook do automatycznego generowania UUID v7
+func (c *OAuthClient) BeforeCreate(tx *gorm.DB) (err error) {
+	idStr := shared.GenerateUuidV7()
+	c.ID, err = uuid.Parse(idStr)
+	return err
+}
@@ -0,0 +1,25 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"gorm.io/gorm"
+)
+
+// RecoveryCode to jednorazowy kod awaryjny (zastępuje 2FA / weryfikację urządzenia)
+type RecoveryCode struct {
+	ID        uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null;index"`
+	CodeHash  string     `gorm:"size:64;not null;uniqueIndex"` // HMAC-SHA256 (hex) solony INTERNAL_HASH_SALT
+	UsedAt    *time.Time `gorm:"index"`
+	CreatedAt time.Time  `gorm:"autoCreateTime"`
+}
+
+// Hook do automatycznego generowania UUID v7
+func (rc *RecoveryCode) BeforeCreate(tx *gorm.DB) (err error) {
+	idStr := shared.GenerateUuidV7()
+	rc.ID, err = uuid.Parse(idStr)
+	return err
+}
@@ -13,6 +13,7 @@ type RefreshToken struct {
 	UserID            uuid.UUID `gorm:"type:uuid;not null;index"`
 	Token             string    `gorm:"size:64;not null;uniqueIndex"`
 	DeviceFingerprint string    `gorm:"size:128;not null"`
+	SessionID         string    `gorm:"size:64;index"` // bieżąca sesja Redis wydana tym tokenem
 	ExpiresAt         time.Time `gorm:"not null"`
 	CreatedAt         time.Time `gorm:"autoCreateTime"`
 	UpdatedAt         time.Time `gorm:"autoUpdateTime"`
@@ -47,6 +47,7 @@ type User struct {
 	LockedUntil         *time.Time `gorm:"index"`
 	LastLogin           time.Time
 	PasswordChangedAt   *time.Time
+	MustResetPassword   bool             `gorm:"not null;default:false"` // wymuszona zmiana hasła (admin)
 	LastIP              string           `gorm:"size:45"`
 	TwoFactorEnabled    bool             `gorm:"not null;default:false"`
 	TwoFactorSecret     string           `gorm:"size:64"`
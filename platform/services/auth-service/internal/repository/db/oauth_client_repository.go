@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	repository "github.com/zerodayz7/platform/services/auth-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.OAuthClientRepository = (*OAuthClientRepository)(nil)
+
+type OAuthClientRepository struct {
+	DB *gorm.DB
+}
+
+func NewOAuthClientRepository(db *gorm.DB) *OAuthClientRepository {
+	return &OAuthClientRepository{DB: db}
+}
+
+// GetByClientID zwraca aktywnego klienta OAuth
+func (r *OAuthClientRepository) GetByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error) {
+	var client model.OAuthClient
+	err := r.DB.WithContext(ctx).
+		Where("client_id = ? AND active = ?", clientID, true).
+		First(&client).Error
+	if err != nil {
+		return nil, err
+	}
+	return &client, nil
+}
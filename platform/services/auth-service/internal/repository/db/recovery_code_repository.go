@@ -0,0 +1,64 @@
+package db
+
+import (
+	"context"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	repository "github.com/zerodayz7/platform/services/auth-service/internal/repository"
+	"gorm.io/gorm"
+)
+
+var _ repository.RecoveryCodeRepository = (*RecoveryCodeRepository)(nil)
+
+type RecoveryCodeRepository struct {
+	DB *gorm.DB
+}
+
+func NewRecoveryCodeRepository(db *gorm.DB) *RecoveryCodeRepository {
+	return &RecoveryCodeRepository{DB: db}
+}
+
+// ReplaceForUser usuwa poprzedni zestaw kodów i zapisuje nowy w jednej transakcji
+func (r *RecoveryCodeRepository) ReplaceForUser(ctx context.Context, userID uuid.UUID, hashes []string) error {
+	return r.DB.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codes := make([]model.RecoveryCode, len(hashes))
+		for i, h := range hashes {
+			codes[i] = model.RecoveryCode{UserID: userID, CodeHash: h}
+		}
+		return tx.Create(&codes).Error
+	})
+}
+
+// Consume oznacza kod jako użyty. Warunek "used_at IS NULL" w UPDATE gwarantuje jednorazowość
+// nawet przy równoległych żądaniach.
+func (r *RecoveryCodeRepository) Consume(ctx context.Context, userID uuid.UUID, hash string) (bool, error) {
+	res := r.DB.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, hash).
+		Update("used_at", time.Now())
+	if res.Error != nil {
+		return false, res.Error
+	}
+	return res.RowsAffected == 1, nil
+}
+
+// CountRemaining zwraca liczbę niewykorzystanych kodów użytkownika
+func (r *RecoveryCodeRepository) CountRemaining(ctx context.Context, userID uuid.UUID) (int64, error) {
+	var count int64
+	err := r.DB.WithContext(ctx).
+		Model(&model.RecoveryCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+// DeleteForUser usuwa wszystkie kody użytkownika (np. przy resecie 2FA przez administratora)
+func (r *RecoveryCodeRepository) DeleteForUser(ctx context.Context, userID uuid.UUID) error {
+	return r.DB.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.RecoveryCode{}).Error
+}
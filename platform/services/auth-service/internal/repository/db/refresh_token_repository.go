@@ -87,6 +87,17 @@ func (r *RefreshTokenRepository) RevokeSession(ctx context.Context, userID uuid.
 		Update("revoked", true).Error
 }
 
+// RevokeBySessionIDs unieważnia tokeny odświeżania sesji Redis (np. wyrzuconych przez limit sesji)
+func (r *RefreshTokenRepository) RevokeBySessionIDs(ctx context.Context, sessionIDs []string) error {
+	if len(sessionIDs) == 0 {
+		return nil
+	}
+	return r.DB.WithContext(ctx).
+		Model(&model.RefreshToken{}).
+		Where("session_id IN ? AND revoked = ?", sessionIDs, false).
+		Update("revoked", true).Error
+}
+
 func (r *RefreshTokenRepository) RevokeByFingerprint(ctx context.Context, userID uuid.UUID, fingerprint string) error {
 	return r.DB.Model(&model.RefreshToken{}).
 		Where("user_id = ? AND device_fingerprint = ? AND revoked = ?", userID, fingerprint, false).
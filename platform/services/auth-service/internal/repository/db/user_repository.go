@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -165,3 +166,48 @@ func (r *UserRepo) ResetFailedLoginAttempts(userID uuid.UUID) error {
 		Where("id = ?", userID).
 		Update("failed_login_attempts", 0).Error
 }
+
+// SearchUsers wyszukuje po fragmencie e-maila lub nazwy użytkownika (bez rozróżniania wielkości liter)
+func (r *UserRepo) SearchUsers(ctx context.Context, query string, offset, limit int) ([]model.User, int64, error) {
+	q := r.db.WithContext(ctx).Model(&model.User{})
+	if query != "" {
+		like := "%" + escapeLike(query) + "%"
+		q = q.Where("email ILIKE ? OR username ILIKE ?", like, like)
+	}
+
+	var total int64
+	if err := q.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var users []model.User
+	err := q.Order("created_at DESC").
+		Offset(offset).
+		Limit(limit).
+		Find(&users).Error
+	return users, total, err
+}
+
+func (r *UserRepo) GetDevices(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error) {
+	var devices []model.UserDevice
+	err := r.db.WithContext(ctx).
+		Where("user_id = ?", userID).
+		Order("last_used_at DESC").
+		Find(&devices).Error
+	return devices, err
+}
+
+// DeactivateDevices odbiera zaufanie wszystkim urządzeniom (wymusza ponowne 2FA i parowanie)
+func (r *UserRepo) DeactivateDevices(ctx context.Context, userID uuid.UUID) error {
+	return r.db.WithContext(ctx).
+		Model(&model.UserDevice{}).
+		Where("user_id = ?", userID).
+		Updates(map[string]any{
+			"is_active":   false,
+			"is_verified": false,
+		}).Error
+}
+
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
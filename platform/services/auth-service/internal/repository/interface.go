@@ -18,6 +18,18 @@ type RefreshTokenRepository interface {
 	RevokeAllUserTokens(ctx context.Context, userID uuid.UUID) error
 	GetSessions(ctx context.Context, userID uuid.UUID) ([]model.UserSessionDTO, error)
 	RevokeSession(ctx context.Context, userID uuid.UUID, sessionID uint) error
+	RevokeBySessionIDs(ctx context.Context, sessionIDs []string) error
+}
+
+type OAuthClientRepository interface {
+	GetByClientID(ctx context.Context, clientID string) (*model.OAuthClient, error)
+}
+
+type RecoveryCodeRepository interface {
+	ReplaceForUser(ctx context.Context, userID uuid.UUID, hashes []string) error
+	Consume(ctx context.Context, userID uuid.UUID, hash string) (bool, error)
+	CountRemaining(ctx context.Context, userID uuid.UUID) (int64, error)
+	DeleteForUser(ctx context.Context, userID uuid.UUID) error
 }
 
 type UserRepository interface {
@@ -39,4 +51,9 @@ type UserRepository interface {
 	PermanentLock(userID uuid.UUID) error
 
 	GetDeviceByFingerprint(ctx context.Context, userID uuid.UUID, fingerprint string) (*model.UserDevice, error)
+
+	// Panel administracyjny
+	SearchUsers(ctx context.Context, query string, offset, limit int) ([]model.User, int64, error)
+	GetDevices(ctx context.Context, userID uuid.UUID) ([]model.UserDevice, error)
+	DeactivateDevices(ctx context.Context, userID uuid.UUID) error
 }
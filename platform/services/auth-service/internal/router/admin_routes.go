@@ -0,0 +1,48 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	pkgmiddleware "github.com/zerodayz7/platform/pkg/middleware"
+	"github.com/zerodayz7/platform/pkg/schemas"
+	"github.com/zerodayz7/platform/pkg/shared"
+
+	handler "github.com/zerodayz7/platform/services/auth-service/internal/handler"
+	"github.com/zerodayz7/platform/services/auth-service/internal/middleware"
+)
+
+func SetupAdminRoutes(app *fiber.App, h *handler.AdminHandler) {
+	users := app.Group("/admin/users")
+	users.Use(shared.GetLimiter(shared.LimitUsers, nil))
+	users.Use(pkgmiddleware.RequireRoles("ADMIN", "CLERK"))
+
+	users.Get("",
+		middleware.ValidateQuery[schemas.AdminUserSearchQuery](),
+		h.SearchUsers,
+	)
+	users.Get("/:id",
+		middleware.ValidateParams[schemas.AdminUserParams](),
+		h.GetUser,
+	)
+
+	// Każda akcja modyfikująca wymaga uzasadnienia, trafia do audit_stream
+	// i jest zastrzeżona dla administratorów (CLERK ma tylko podgląd)
+	adminOnly := pkgmiddleware.RequireRoles("ADMIN")
+	users.Patch("/:id/status",
+		adminOnly,
+		middleware.ValidateParams[schemas.AdminUserParams](),
+		middleware.ValidateBody[schemas.AdminChangeStatusRequest](),
+		h.ChangeStatus,
+	)
+	users.Post("/:id/2fa-reset",
+		adminOnly,
+		middleware.ValidateParams[schemas.AdminUserParams](),
+		middleware.ValidateBody[schemas.AdminActionRequest](),
+		h.Reset2FA,
+	)
+	users.Post("/:id/password-reset",
+		adminOnly,
+		middleware.ValidateParams[schemas.AdminUserParams](),
+		middleware.ValidateBody[schemas.AdminActionRequest](),
+		h.ForcePasswordReset,
+	)
+}
@@ -31,6 +31,12 @@ func SetupAuthRoutes(
 		h.Verify2FA,
 	)
 
+	// Utracone urządzenie: jednorazowy kod odzyskiwania zamiast kodu 2FA
+	auth.Post("/2fa-recover",
+		middleware.ValidateBody[schemas.TwoFARecoverRequest](),
+		h.Recover2FA,
+	)
+
 	auth.Post("/register",
 		middleware.ValidateBody[schemas.RegisterRequest](),
 		h.Register,
@@ -42,7 +48,7 @@ func SetupAuthRoutes(
 	)
 
 	auth.Post("/logout",
-		middleware.ValidateBody[schemas.RefreshTokenRequest](),
+		middleware.ValidateBody[schemas.LogoutRequest](),
 		h.Logout,
 	)
 
@@ -60,6 +66,12 @@ func SetupAuthRoutes(
 		h.VerifyDevice,
 	)
 
+	// Nowy zestaw kodów odzyskiwania (poprzedni przestaje działać) – po ponownym podaniu hasła
+	auth.Post("/recovery-codes/regenerate",
+		middleware.ValidateBody[schemas.RegenerateRecoveryCodesRequest](),
+		h.RegenerateRecoveryCodes,
+	)
+
 	// ==========================
 	// RESET PASSWORD
 	// ==========================
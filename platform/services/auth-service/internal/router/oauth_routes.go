@@ -0,0 +1,17 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/shared"
+
+	handler "github.com/zerodayz7/platform/services/auth-service/internal/handler"
+)
+
+func SetupOAuthRoutes(app *fiber.App, h *handler.OAuthHandler) {
+	oauth := app.Group("/oauth")
+	oauth.Use(shared.GetLimiter(shared.LimitOAuth, nil))
+
+	// Uwierzytelnienie: client credentials (Basic lub client_id/client_secret w formularzu)
+	oauth.Post("/introspect", h.Introspect)
+	oauth.Post("/revoke", h.Revoke)
+}
@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	"github.com/zerodayz7/platform/pkg/router"
 	"github.com/zerodayz7/platform/pkg/router/health"
 	"github.com/zerodayz7/platform/services/auth-service/config"
@@ -17,8 +18,18 @@ func SetupRoutes(app *fiber.App, container *di.Container) {
 
 	health.RegisterRoutes(app, checker)
 
+	// Dokument OpenAPI opisuje trasy rejestrowane od tego miejsca do docs.RegisterRoutes
+	docs := openapi.New(app, openapi.Info{
+		Title:   config.AppConfig.Server.AppName,
+		Version: config.AppConfig.Server.AppVersion,
+	})
+
 	SetupAuthRoutes(app, container.Handlers.AuthHandler, container.Handlers.ResetHandler)
 	SetupUserRoutes(app, container.Handlers.UserHandler)
+	SetupOAuthRoutes(app, container.Handlers.OAuthHandler)
+	SetupAdminRoutes(app, container.Handlers.AdminHandler)
+
+	docs.RegisterRoutes()
 
 	router.SetupFallbackHandlers(app)
 }
@@ -0,0 +1,356 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/schemas"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/auth-service/internal/http"
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	repo "github.com/zerodayz7/platform/services/auth-service/internal/repository"
+)
+
+const (
+	adminDefaultPageSize = 20
+
+	AuditAdminUserViewed    = "ADMIN_USER_VIEWED"
+	AuditAdminStatusChanged = "ADMIN_USER_STATUS_CHANGED"
+	AuditAdmin2FAReset      = "ADMIN_2FA_RESET"
+	AuditAdminPasswordReset = "ADMIN_PASSWORD_RESET_FORCED"
+)
+
+// AdminActor identyfikuje pracownika wykonującego akcję (trafia do audytu)
+type AdminActor struct {
+	UserID uuid.UUID
+	IP     string
+}
+
+// AdminService udostępnia operacje panelu wsparcia na kontach użytkowników.
+// region interface
+type AdminService interface {
+	SearchUsers(ctx context.Context, query schemas.AdminUserSearchQuery) (*http.AdminUserListResponse, error)
+	GetUserDetails(ctx context.Context, actor AdminActor, userID uuid.UUID) (*http.AdminUserDetailsResponse, error)
+	ChangeStatus(ctx context.Context, actor AdminActor, userID uuid.UUID, req schemas.AdminChangeStatusRequest) error
+	Reset2FA(ctx context.Context, actor AdminActor, userID uuid.UUID, justification string) error
+	ForcePasswordReset(ctx context.Context, actor AdminActor, userID uuid.UUID, justification string) error
+}
+
+// region struct
+type adminService struct {
+	userRepo     repo.UserRepository
+	refreshRepo  repo.RefreshTokenRepository
+	recoveryRepo repo.RecoveryCodeRepository
+	cache        *redis.Cache
+	audit        *events.AuditPublisher
+}
+
+func NewAdminService(
+	userRepo repo.UserRepository,
+	refreshRepo repo.RefreshTokenRepository,
+	recoveryRepo repo.RecoveryCodeRepository,
+	cache *redis.Cache,
+	audit *events.AuditPublisher,
+) AdminService {
+	return &adminService{
+		userRepo: userRepo, refreshRepo: refreshRepo, recoveryRepo: recoveryRepo, cache: cache, audit: audit,
+	}
+}
+
+// region SearchUsers
+func (s *adminService) SearchUsers(ctx context.Context, query schemas.AdminUserSearchQuery) (*http.AdminUserListResponse, error) {
+	page := max(query.Page, 1)
+	pageSize := query.PageSize
+	if pageSize == 0 {
+		pageSize = adminDefaultPageSize
+	}
+
+	users, total, err := s.userRepo.SearchUsers(ctx, strings.TrimSpace(query.Query), (page-1)*pageSize, pageSize)
+	if err != nil {
+		shared.GetLogger().ErrorObj("Admin: user search failed", err)
+		return nil, errors.ErrInternal
+	}
+
+	items := make([]http.AdminUserSummary, len(users))
+	for i := range users {
+		items[i] = toAdminSummary(&users[i])
+	}
+
+	return &http.AdminUserListResponse{
+		Items:    items,
+		Page:     page,
+		PageSize: pageSize,
+		Total:    total,
+	}, nil
+}
+
+// region GetUserDetails
+func (s *adminService) GetUserDetails(ctx context.Context, actor AdminActor, userID uuid.UUID) (*http.AdminUserDetailsResponse, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	devices, err := s.userRepo.GetDevices(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	sessions, err := s.refreshRepo.GetSessions(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+
+	resp := &http.AdminUserDetailsResponse{
+		User:                toAdminSummary(user),
+		FailedLoginAttempts: user.FailedLoginAttempts,
+		LockedUntil:         user.LockedUntil,
+		MustResetPassword:   user.MustResetPassword,
+		LastIP:              user.LastIP,
+		Devices:             make([]http.AdminDevice, len(devices)),
+		Sessions:            sessions,
+		ActiveSessions:      s.activeSessions(ctx, userID),
+	}
+	for i, d := range devices {
+		resp.Devices[i] = http.AdminDevice{
+			ID:         d.ID.String(),
+			Platform:   d.Platform,
+			IsActive:   d.IsActive,
+			IsVerified: d.IsVerified,
+			LastIP:     d.LastIp,
+			LastUsedAt: d.LastUsedAt,
+			CreatedAt:  d.CreatedAt,
+		}
+	}
+
+	// Podgląd danych osobowych też zostawia ślad
+	s.record(ctx, actor, AuditAdminUserViewed, userID, nil)
+	return resp, nil
+}
+
+// activeSessions zwraca żywe sesje z Redis (indeks może zawierać już wygasłe SID-y)
+func (s *adminService) activeSessions(ctx context.Context, userID uuid.UUID) []http.AdminActiveSession {
+	sids, err := s.cache.ListUserSessions(ctx, userID.String())
+	if err != nil {
+		shared.GetLogger().ErrorObj("Admin: failed to list redis sessions", err)
+		return []http.AdminActiveSession{}
+	}
+
+	active := make([]http.AdminActiveSession, 0, len(sids))
+	for _, sid := range sids {
+		sess, err := s.cache.GetSession(ctx, sid)
+		if err != nil {
+			continue
+		}
+		active = append(active, http.AdminActiveSession{
+			SessionID:  sid,
+			CreatedAt:  sess.CreatedAt,
+			LastSeenAt: sess.LastSeenAt,
+			IP:         sess.IP,
+		})
+	}
+	return active
+}
+
+// region ChangeStatus
+func (s *adminService) ChangeStatus(ctx context.Context, actor AdminActor, userID uuid.UUID, req schemas.AdminChangeStatusRequest) error {
+	if err := requireJustification(req.Justification); err != nil {
+		return err
+	}
+	if actor.UserID == userID {
+		return errors.ErrForbidden
+	}
+
+	user, err := s.getTarget(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+	previous := user.Status
+
+	switch model.UserStatus(req.Status) {
+	case model.StatusActive:
+		// Odblokowanie: czyścimy blokadę czasową i licznik błędnych prób
+		user.Status = model.StatusActive
+		user.LockedUntil = nil
+		user.FailedLoginAttempts = 0
+	case model.StatusSuspended:
+		if req.LockedUntil == nil || !req.LockedUntil.After(time.Now()) {
+			return errors.ErrValidationFailed.WithMeta("locked_until", "Must be a future date")
+		}
+		until := req.LockedUntil.UTC()
+		user.Status = model.StatusSuspended
+		user.LockedUntil = &until
+	case model.StatusBanned:
+		user.Status = model.StatusBanned
+		user.LockedUntil = nil
+	default:
+		return errors.ErrInvalidRequest
+	}
+
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		shared.GetLogger().ErrorObj("Admin: failed to update user status", err)
+		return errors.ErrInternal
+	}
+
+	// Zawieszenie / ban kończy natychmiast wszystkie sesje
+	if user.Status != model.StatusActive {
+		s.terminateAccess(ctx, userID)
+	}
+
+	meta := map[string]any{
+		"justification": req.Justification,
+		"from":          previous,
+		"to":            user.Status,
+	}
+	if user.LockedUntil != nil {
+		meta["locked_until"] = user.LockedUntil.Format(time.RFC3339)
+	}
+	s.record(ctx, actor, AuditAdminStatusChanged, userID, meta)
+	return nil
+}
+
+// region Reset2FA
+// Reset2FA odbiera zaufanie wszystkim urządzeniom i unieważnia kody odzyskiwania –
+// kolejne logowanie przejdzie pełną ścieżkę 2FA i parowania urządzenia.
+func (s *adminService) Reset2FA(ctx context.Context, actor AdminActor, userID uuid.UUID, justification string) error {
+	if err := requireJustification(justification); err != nil {
+		return err
+	}
+
+	user, err := s.getTarget(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	user.TwoFactorSecret = ""
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.ErrInternal
+	}
+	if err := s.userRepo.DeactivateDevices(ctx, userID); err != nil {
+		return errors.ErrInternal
+	}
+	if err := s.recoveryRepo.DeleteForUser(ctx, userID); err != nil {
+		return errors.ErrInternal
+	}
+	s.terminateAccess(ctx, userID)
+
+	s.record(ctx, actor, AuditAdmin2FAReset, userID, map[string]any{"justification": justification})
+	return nil
+}
+
+// region ForcePasswordReset
+func (s *adminService) ForcePasswordReset(ctx context.Context, actor AdminActor, userID uuid.UUID, justification string) error {
+	if err := requireJustification(justification); err != nil {
+		return err
+	}
+
+	user, err := s.getTarget(ctx, actor, userID)
+	if err != nil {
+		return err
+	}
+
+	user.MustResetPassword = true
+	if err := s.userRepo.Update(ctx, user); err != nil {
+		return errors.ErrInternal
+	}
+	s.terminateAccess(ctx, userID)
+
+	s.record(ctx, actor, AuditAdminPasswordReset, userID, map[string]any{"justification": justification})
+	return nil
+}
+
+// terminateAccess kończy sesje w Redis i unieważnia refresh tokeny
+func (s *adminService) terminateAccess(ctx context.Context, userID uuid.UUID) {
+	log := shared.GetLogger()
+	if _, err := s.cache.EndUserSessions(ctx, userID.String()); err != nil {
+		log.ErrorObj("Admin: failed to end redis sessions", err)
+	}
+	if err := s.refreshRepo.RevokeAllUserTokens(ctx, userID); err != nil {
+		log.ErrorObj("Admin: failed to revoke refresh tokens", err)
+	}
+}
+
+// getTarget pobiera konto, na którym aktor wykonuje akcję. Rolę aktora czytamy z bazy
+// (nie z kontekstu żądania): konto z równą lub wyższą rolą może zmienić tylko ktoś wyżej.
+func (s *adminService) getTarget(ctx context.Context, actor AdminActor, userID uuid.UUID) (*model.User, error) {
+	user, err := s.getUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	actorUser, err := s.getUser(ctx, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if roleRank(user.Role) >= roleRank(actorUser.Role) {
+		shared.GetLogger().WarnMap("Admin: action on account with equal or higher role rejected", map[string]any{
+			"actor":       actor.UserID,
+			"actor_role":  actorUser.Role,
+			"target":      userID,
+			"target_role": user.Role,
+		})
+		return nil, errors.ErrAdminTargetRole
+	}
+	return user, nil
+}
+
+// roleRank – pozycja roli w hierarchii (pusta rola to zwykły użytkownik)
+func roleRank(role model.UserRole) int {
+	switch model.UserRole(strings.ToLower(string(role))) {
+	case model.RoleAdmin:
+		return 2
+	case model.RoleClerk:
+		return 1
+	}
+	return 0
+}
+
+func (s *adminService) getUser(ctx context.Context, userID uuid.UUID) (*model.User, error) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	if user == nil {
+		return nil, errors.ErrUserNotFound
+	}
+	return user, nil
+}
+
+// record zapisuje akcję w audit_stream; user_id to aktor, cel trafia do metadanych
+func (s *adminService) record(ctx context.Context, actor AdminActor, action string, target uuid.UUID, meta map[string]any) {
+	if meta == nil {
+		meta = map[string]any{}
+	}
+	meta["target_user_id"] = target.String()
+
+	if err := s.audit.Record(ctx, actor.UserID.String(), action, actor.IP, meta); err != nil {
+		shared.GetLogger().ErrorMap("Admin: failed to write audit record", map[string]any{
+			"action": action,
+			"target": target,
+			"err":    err.Error(),
+		})
+	}
+}
+
+func requireJustification(justification string) error {
+	if strings.TrimSpace(justification) == "" {
+		return errors.ErrJustificationRequired
+	}
+	return nil
+}
+
+func toAdminSummary(u *model.User) http.AdminUserSummary {
+	return http.AdminUserSummary{
+		ID:               u.ID.String(),
+		Username:         u.Username,
+		Email:            u.Email,
+		Role:             string(u.Role),
+		Status:           string(u.Status),
+		TwoFactorEnabled: u.TwoFactorEnabled,
+		LastLogin:        u.LastLogin,
+		CreatedAt:        u.CreatedAt,
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/base64"
 	"encoding/hex"
+	stdErrors "errors"
 	"fmt"
 	"strings"
 	"time"
@@ -27,17 +28,20 @@ import (
 // region interface
 type AuthService interface {
 	// Główne procesy BIZNESOWE (zostawiamy tylko to, co ma logikę)
-	AttemptLogin(ctx context.Context, email string, password []byte, fingerprint string) (*http.LoginResponse, error)
+	AttemptLogin(ctx context.Context, email string, password []byte, attempt LoginAttempt) (*http.LoginResponse, error)
 	Register(username, email, rawPassword string) (*model.User, error)
 	UpdatePassword(ctx context.Context, userID uuid.UUID, newPassword string) error
 	Verify2FA(ctx context.Context, token string, code []byte, fingerprint string, ip string) (*http.Verify2FAResponse, error)
+	Recover2FA(ctx context.Context, token string, recoveryCode string, fingerprint string, ip string) (*http.Verify2FAResponse, error)
+	// ConfirmPassword – ponowne uwierzytelnienie zalogowanego użytkownika przed operacją wrażliwą
+	ConfirmPassword(ctx context.Context, userID uuid.UUID, password []byte) error
 	Logout(ctx context.Context, userID uuid.UUID, sessionID string, fingerprint string) error
 	RegisterDevice(ctx context.Context, userID uuid.UUID, sessionID string, clientIP string, req schemas.RegisterDeviceRequest) (*http.RegisterDeviceResponse, error)
 	RefreshToken(ctx context.Context, tokenStr string, fingerprint string) (*http.RefreshResponse, error)
 	VerifyDeviceSignature(ctx context.Context, userID, challenge, signature, fingerprint string) (*http.LoginResponse, error)
 	// Narzędzia JWT
 	CreateAccessToken(userID uuid.UUID, fingerprint string) (string, string, error)
-	CreateRefreshToken(userID uuid.UUID, fingerprint string) (*model.RefreshToken, error)
+	CreateRefreshToken(userID uuid.UUID, fingerprint, sessionID string) (*model.RefreshToken, error)
 	GetRefreshToken(token string) (*model.RefreshToken, error)
 	RevokeRefreshToken(token string) error
 	// Metody specyficzne dla logiki logowania
@@ -51,11 +55,13 @@ type authService struct {
 	refreshRepo repo.RefreshTokenRepository
 	cache       *redis.Cache
 	cfg         *viper.Config
+	guard       *LoginGuard
+	recovery    RecoveryService
 }
 
-func NewAuthService(userRepo repo.UserRepository, refreshRepo repo.RefreshTokenRepository, cache *redis.Cache, cfg *viper.Config) AuthService {
+func NewAuthService(userRepo repo.UserRepository, refreshRepo repo.RefreshTokenRepository, cache *redis.Cache, cfg *viper.Config, guard *LoginGuard, recovery RecoveryService) AuthService {
 	return &authService{
-		userRepo: userRepo, refreshRepo: refreshRepo, cache: cache, cfg: cfg,
+		userRepo: userRepo, refreshRepo: refreshRepo, cache: cache, cfg: cfg, guard: guard, recovery: recovery,
 	}
 }
 
@@ -122,19 +128,19 @@ func (s *authService) VerifyDeviceSignature(ctx context.Context, userIDStr, sess
 		return nil, errors.ErrInternal
 	}
 
-	refreshToken, err := s.CreateRefreshToken(user.ID, fingerprint)
+	refreshToken, err := s.CreateRefreshToken(user.ID, fingerprint, sessionID)
 	if err != nil {
 		return nil, errors.ErrInternal
 	}
 
 	// 4. Zapisujemy sesję w Redis (używając Twojego s.cache)
-	// Pobieramy role - na razie "USER", docelowo z bazy
-	roles := []string{"USER"}
-	err = s.cache.SetSession(ctx, sessionID, redis.UserSession{
+	roles := sessionRoles(user)
+	err = s.startSession(ctx, sessionID, redis.UserSession{
 		UserID:      user.ID.String(),
 		Fingerprint: fingerprint,
 		Roles:       roles,
-	}, s.cfg.Session.TTL)
+		DeviceKey:   device.PublicKey, // gateway wymaga dowodów DPoP podpisanych tym kluczem
+	})
 	if err != nil {
 		return nil, errors.ErrInternal
 	}
@@ -171,6 +177,22 @@ func (s *authService) RefreshToken(ctx context.Context, tokenStr string, fingerp
 		return nil, errors.ErrInvalidToken
 	}
 
+	// Token odświeża tylko żywą sesję – po wylogowaniu przez bezczynność albo wyrzuceniu
+	// przez limit sesji (REDIS_SESSION_MAX_PER_USER) trzeba zalogować się ponownie
+	alive, err := s.cache.SessionExists(ctx, rt.SessionID)
+	if err != nil {
+		log.ErrorObj("Session lookup failed", err)
+		return nil, errors.ErrInternal
+	}
+	if rt.SessionID == "" || !alive {
+		log.InfoMap("Session of refresh token ended, refresh token revoked", map[string]any{"user_id": rt.UserID})
+		rt.Revoked = true
+		if err := s.refreshRepo.Update(rt); err != nil {
+			log.ErrorObj("Failed to revoke refresh token", err)
+		}
+		return nil, errors.ErrSessionExpired
+	}
+
 	// 3. Generowanie nowych poświadczeń
 	// Tworzymy nowy Access Token i nowe SessionID (SID)
 	accessToken, newSessionID, err := s.CreateAccessToken(rt.UserID, fingerprint)
@@ -184,23 +206,40 @@ func (s *authService) RefreshToken(ctx context.Context, tokenStr string, fingerp
 		return nil, errors.ErrInternal
 	}
 
-	// 3. Pobierz role (np. z obiektu user lub dedykowanej tabeli)
-	roles := []string{"USER"}
-	// if user.IsAdmin {
-	// 	roles = append(roles, "ADMIN")
-	// }
+	// 3. Pobierz role (sesja w Redis jest źródłem ról dla gatewaya)
+	roles := sessionRoles(user)
 
-	// 4. Aktualizacja sesji w Redis z ROLAMI
-	err = s.cache.SetSession(ctx, newSessionID, redis.UserSession{
+	// 4. Aktualizacja sesji w Redis z ROLAMI. Nowa sesja dziedziczy CreatedAt logowania
+	// (token odświeżania powstaje razem z pierwszą sesją), więc odświeżanie nie przedłuża
+	// absolutnego czasu życia sesji.
+	err = s.startSession(ctx, newSessionID, redis.UserSession{
 		UserID:      user.ID.String(),
 		Fingerprint: fingerprint,
 		Roles:       roles,
-	}, s.cfg.Session.TTL)
+		DeviceKey:   s.deviceKey(ctx, rt.UserID, fingerprint),
+		CreatedAt:   rt.CreatedAt.Unix(),
+	})
+	if stdErrors.Is(err, redis.ErrSessionLifetimeExceeded) {
+		log.InfoMap("Session absolute lifetime exceeded, refresh token revoked", map[string]any{"user_id": rt.UserID})
+		rt.Revoked = true
+		if err := s.refreshRepo.Update(rt); err != nil {
+			log.ErrorObj("Failed to revoke refresh token", err)
+		}
+		s.endSession(ctx, rt.SessionID, rt.UserID)
+		return nil, errors.ErrSessionExpired
+	}
 	if err != nil {
 		log.ErrorObj("Failed to save session in Redis", err)
 		return nil, errors.ErrInternal
 	}
 
+	// 5. Poprzednia sesja tego tokenu przestaje obowiązywać
+	s.endSession(ctx, rt.SessionID, rt.UserID)
+	rt.SessionID = newSessionID
+	if err := s.refreshRepo.Update(rt); err != nil {
+		log.ErrorObj("Failed to store session of refresh token", err)
+	}
+
 	return &http.RefreshResponse{
 		AccessToken:  accessToken,
 		RefreshToken: rt.Token, // Zwracamy ten sam lub generujemy nowy (Rotation)
@@ -286,7 +325,7 @@ func (s *authService) RegisterDevice(ctx context.Context, userID uuid.UUID, sess
 		return nil, errors.ErrInternal
 	}
 
-	refreshToken, err := s.CreateRefreshToken(userID, req.DeviceFingerprint)
+	refreshToken, err := s.CreateRefreshToken(userID, req.DeviceFingerprint, newSID)
 	if err != nil {
 		return nil, errors.ErrInternal
 	}
@@ -298,32 +337,41 @@ func (s *authService) RegisterDevice(ctx context.Context, userID uuid.UUID, sess
 	}
 
 	// 2. Przygotuj dane do sesji (np. role jako string slice)
-	roles := []string{"USER"} // Tu pobierz role z obiektu user, np. user.Roles
+	roles := sessionRoles(user)
 
 	// 3. Zapisz BOGATĄ sesję w cache (używając struktury UserSession)
 	sessionData := redis.UserSession{
 		UserID:      user.ID.String(),
 		Fingerprint: req.DeviceFingerprint,
 		Roles:       roles,
+		DeviceKey:   req.PublicKey,
 	}
 
-	if err = s.cache.SetSession(ctx, newSID, sessionData, s.cfg.Session.TTL); err != nil {
+	if err = s.startSession(ctx, newSID, sessionData); err != nil {
 		log.ErrorObj("Failed to save session", err)
 		return nil, errors.ErrInternal
 	}
-	// 5. FINALIZACJA
+	// 5. KODY ODZYSKIWANIA – generowane przy pierwszym sparowaniu, zwracane tylko raz
+	recoveryCodes, err := s.recovery.EnsureGenerated(ctx, userID, clientIP)
+	if err != nil {
+		log.ErrorObj("Failed to prepare recovery codes", err)
+	}
+
+	// 6. FINALIZACJA
 
 	return &http.RegisterDeviceResponse{
-		Success:      true,
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken.Token,
-		IsTrusted:    true,
+		Success:       true,
+		AccessToken:   accessToken,
+		RefreshToken:  refreshToken.Token,
+		IsTrusted:     true,
+		RecoveryCodes: recoveryCodes,
 		User: http.DeviceUserData{
 			UserID:      user.ID.String(),
 			Email:       user.Email,
 			DisplayName: user.Username,
 			LastLogin:   time.Now().Format(time.RFC3339),
-			Roles:       []string{"USER"},
+			Role:        string(user.Role),
+			Roles:       roles,
 		},
 	}, nil
 }
@@ -350,8 +398,8 @@ func (s *authService) Logout(ctx context.Context, userID uuid.UUID, sessionID st
 		return errors.ErrUnauthorized
 	}
 
-	// 3. Usuwanie sesji z Redis
-	if err := s.cache.DeleteSession(ctx, sessionID); err != nil {
+	// 3. Usuwanie sesji z Redis (wraz z wpisem w indeksie sesji użytkownika)
+	if err := s.cache.EndSession(ctx, sessionID, session.UserID); err != nil {
 		return errors.ErrInternal
 	}
 
@@ -392,6 +440,82 @@ func (s *authService) Verify2FA(ctx context.Context, token string, code []byte,
 			return nil, errors.ErrInvalid2FACode
 		}
 	}
+	return s.complete2FA(ctx, token, session, fingerprint, ip)
+}
+
+// region Recover2FA
+// Recover2FA zastępuje kod 2FA jednorazowym kodem odzyskiwania (utracone urządzenie).
+func (s *authService) Recover2FA(ctx context.Context, token string, recoveryCode string, fingerprint string, ip string) (*http.Verify2FAResponse, error) {
+	session, err := s.cache.Get2FASession(ctx, token)
+	if err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	uid, err := uuid.Parse(session.UserID)
+	if err != nil {
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	// Ten sam licznik prób co przy kodzie 2FA – brak osobnej ścieżki do zgadywania. Próba
+	// jest liczona (atomowo) przed Redeem: zablokowana sesja nie zużywa już żadnego kodu.
+	status, err := s.cache.Verify2FAAttempt(ctx, token, 5, 5*time.Minute)
+	switch {
+	case err != nil:
+		shared.GetLogger().ErrorObj("2FA attempt counter unavailable", err)
+		return nil, errors.ErrInternal
+	case status == "locked":
+		return nil, errors.Err2FALocked
+	case status == "not_found":
+		return nil, errors.ErrInvalidCredentials
+	}
+
+	if err := s.recovery.Redeem(ctx, uid, recoveryCode, RecoveryPurpose2FA, ip); err != nil {
+		return nil, err
+	}
+
+	return s.complete2FA(ctx, token, session, fingerprint, ip)
+}
+
+// region ConfirmPassword
+// ConfirmPassword sprawdza hasło zalogowanego użytkownika. Błędne hasło liczy się do tego
+// samego limitu co przy logowaniu – przejęta sesja nie daje osobnej ścieżki do zgadywania.
+func (s *authService) ConfirmPassword(ctx context.Context, userID uuid.UUID, password []byte) error {
+	defer func() {
+		for i := range password {
+			password[i] = 0
+		}
+	}()
+	log := shared.GetLogger()
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil || user == nil {
+		return errors.ErrUserNotFound
+	}
+
+	valid, err := security.VerifyPassword(password, user.Password)
+	if err == nil && valid {
+		if user.FailedLoginAttempts > 0 {
+			_ = s.userRepo.ResetFailedLoginAttempts(user.ID)
+		}
+		return nil
+	}
+
+	attempts, incErr := s.userRepo.IncrementUserFailedLogin(user.ID)
+	if incErr != nil {
+		log.Error("Failed to increment failed attempts", incErr)
+	}
+	log.WarnMap("Password confirmation failed", map[string]any{"user_id": userID, "attempts": attempts})
+	if attempts >= 5 {
+		_ = s.userRepo.PermanentLock(user.ID)
+		return errors.ErrAccountLocked
+	}
+	return errors.ErrPasswordMismatch
+}
+
+// region complete2FA
+func (s *authService) complete2FA(ctx context.Context, token string, session *redis.TwoFASession, fingerprint string, ip string) (*http.Verify2FAResponse, error) {
+	log := shared.GetLogger()
+
 	// 3. Czyszczenie sesji 2FA
 	_ = s.cache.Delete2FASession(ctx, token)
 
@@ -452,7 +576,7 @@ func (s *authService) Verify2FA(ctx context.Context, token string, code []byte,
 }
 
 // region AttemptLogin
-func (s *authService) AttemptLogin(ctx context.Context, email string, password []byte, fingerprint string) (*http.LoginResponse, error) {
+func (s *authService) AttemptLogin(ctx context.Context, email string, password []byte, attempt LoginAttempt) (*http.LoginResponse, error) {
 	defer func() {
 		if len(password) > 0 {
 			for i := range password {
@@ -461,8 +585,24 @@ func (s *authService) AttemptLogin(ctx context.Context, email string, password [
 		}
 	}()
 	log := shared.GetLogger()
+	fingerprint := attempt.Fingerprint
+
+	// 0. Anty-bot: po serii porażek (IP / konto / urządzenie) żądamy rozwiązania wyzwania
+	challenge, err := s.guard.Check(ctx, email, attempt)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &http.LoginResponse{
+			Type:         "botChallenge",
+			BotChallenge: challenge,
+			ExpiresAt:    challenge.ExpiresAt,
+		}, nil
+	}
+
 	user, err := s.userRepo.GetUserByEmail(ctx, email)
 	if err != nil {
+		s.guard.RecordFailure(ctx, email, attempt)
 		return nil, errors.ErrInvalidCredentials
 	}
 
@@ -472,6 +612,8 @@ func (s *authService) AttemptLogin(ctx context.Context, email string, password [
 
 	valid, err := security.VerifyPassword(password, user.Password)
 	if err != nil || !valid {
+		s.guard.RecordFailure(ctx, email, attempt)
+
 		// 1. Zwiększ licznik prób
 		attempts, incErr := s.userRepo.IncrementUserFailedLogin(user.ID)
 		if incErr != nil {
@@ -490,6 +632,7 @@ func (s *authService) AttemptLogin(ctx context.Context, email string, password [
 	if user.FailedLoginAttempts > 0 {
 		_ = s.userRepo.ResetFailedLoginAttempts(user.ID)
 	}
+	s.guard.RecordSuccess(ctx, email, attempt)
 
 	device, err := s.userRepo.GetDeviceByFingerprint(ctx, user.ID, fingerprint)
 	log.DebugDB("SCENARIUSZ A", device)
@@ -586,6 +729,54 @@ func (s *authService) prepare2FASession(ctx context.Context, user *model.User, f
 	}, nil
 }
 
+// region startSession
+// startSession zapisuje sesję z oknem bezczynności i limitem sesji per użytkownik.
+func (s *authService) startSession(ctx context.Context, sessionID string, session redis.UserSession) error {
+	evicted, err := s.cache.StartSession(ctx, sessionID, session, s.cfg.Session.TTL, s.cfg.Session.AbsoluteTTL, s.cfg.Session.MaxPerUser)
+	if err != nil {
+		return err
+	}
+	if len(evicted) > 0 {
+		shared.GetLogger().InfoMap("Session limit reached, oldest sessions evicted", map[string]any{
+			"uid":     session.UserID,
+			"evicted": evicted,
+		})
+		// Wyrzucona sesja nie może wrócić przez /auth/refresh
+		if err := s.refreshRepo.RevokeBySessionIDs(ctx, evicted); err != nil {
+			shared.GetLogger().ErrorObj("Failed to revoke refresh tokens of evicted sessions", err)
+		}
+	}
+	return nil
+}
+
+// endSession kończy sesję wydaną wcześniej tokenem odświeżania (best effort)
+func (s *authService) endSession(ctx context.Context, sessionID string, userID uuid.UUID) {
+	if sessionID == "" {
+		return
+	}
+	if err := s.cache.EndSession(ctx, sessionID, userID.String()); err != nil {
+		shared.GetLogger().WarnMap("Failed to end previous session", map[string]any{"sid": sessionID, "error": err.Error()})
+	}
+}
+
+// deviceKey zwraca klucz publiczny zaufanego urządzenia ("" – urządzenie nieznane;
+// sesja bez klucza nie wymaga w gatewayu dowodów DPoP)
+func (s *authService) deviceKey(ctx context.Context, userID uuid.UUID, fingerprint string) string {
+	device, err := s.userRepo.GetDeviceByFingerprint(ctx, userID, fingerprint)
+	if err != nil || device == nil || !device.IsVerified || !device.IsActive {
+		return ""
+	}
+	return device.PublicKey
+}
+
+// sessionRoles mapuje rolę z bazy na role sesji (wielkie litery, np. "ADMIN")
+func sessionRoles(user *model.User) []string {
+	if user.Role == "" {
+		return []string{strings.ToUpper(string(model.RoleUser))}
+	}
+	return []string{strings.ToUpper(string(user.Role))}
+}
+
 // region finalizeLogin
 func (s *authService) finalizeLogin(ctx context.Context, user *model.User, fingerprint string) (*http.LoginResponse, error) {
 	accessToken, sessionID, err := s.CreateAccessToken(user.ID, fingerprint)
@@ -593,16 +784,16 @@ func (s *authService) finalizeLogin(ctx context.Context, user *model.User, finge
 		return nil, errors.ErrInternal
 	}
 
-	err = s.cache.SetSession(ctx, sessionID, redis.UserSession{
+	err = s.startSession(ctx, sessionID, redis.UserSession{
 		UserID:      user.ID.String(),
 		Fingerprint: fingerprint,
-		Roles:       nil,
-	}, s.cfg.Session.TTL)
+		Roles:       sessionRoles(user),
+	})
 	if err != nil {
 		return nil, errors.ErrInternal
 	}
 
-	refreshToken, err := s.CreateRefreshToken(user.ID, fingerprint)
+	refreshToken, err := s.CreateRefreshToken(user.ID, fingerprint, sessionID)
 	if err != nil {
 		return nil, errors.ErrInternal
 	}
@@ -665,7 +856,7 @@ func (s *authService) CreateSetupToken(userID uuid.UUID, fingerprint string) (st
 }
 
 // region CreateRefreshToken
-func (s *authService) CreateRefreshToken(userID uuid.UUID, fingerprint string) (*model.RefreshToken, error) {
+func (s *authService) CreateRefreshToken(userID uuid.UUID, fingerprint, sessionID string) (*model.RefreshToken, error) {
 	rawToken, _ := security.GenerateRefreshToken()
 	hash := sha256.Sum256([]byte(rawToken))
 	hashedTokenHex := hex.EncodeToString(hash[:])
@@ -674,6 +865,7 @@ func (s *authService) CreateRefreshToken(userID uuid.UUID, fingerprint string) (
 		UserID:            userID,
 		Token:             hashedTokenHex,
 		DeviceFingerprint: fingerprint,
+		SessionID:         sessionID,
 		ExpiresAt:         time.Now().Add(s.cfg.JWT.RefreshTTL),
 	}
 
@@ -729,6 +921,10 @@ func (s *authService) CanUserLogin(user *model.User) error {
 		return errors.ErrAccountPending
 	}
 
+	if user.MustResetPassword {
+		return errors.ErrPasswordResetRequired
+	}
+
 	// 2. Obsługa StatusSuspended (Blokada czasowa)
 	if user.Status == model.StatusSuspended {
 		// Sprawdzamy, czy czas blokady już minął
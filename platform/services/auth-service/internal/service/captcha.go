@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/zerodayz7/platform/pkg/viper"
+)
+
+// Dostawcy LOGIN_CAPTCHA_PROVIDER
+const (
+	CaptchaProviderStub      = "stub"
+	CaptchaProviderTurnstile = "turnstile"
+	CaptchaProviderHCaptcha  = "hcaptcha"
+)
+
+// Domyślne adresy siteverify – oba API przyjmują ten sam formularz i zwracają {"success": ...}
+var captchaVerifyURLs = map[string]string{
+	CaptchaProviderTurnstile: "https://challenges.cloudflare.com/turnstile/v0/siteverify",
+	CaptchaProviderHCaptcha:  "https://api.hcaptcha.com/siteverify",
+}
+
+const maxSiteVerifyBody = 64 << 10
+
+// NewCaptchaVerifier wybiera weryfikator wg LOGIN_CAPTCHA_PROVIDER; nil, gdy wyzwaniem
+// jest PoW (LOGIN_CHALLENGE_MODE=pow). Stub akceptuje stały token, więc w produkcji
+// serwis odmawia startu, zamiast wystawić obejście wyzwania.
+func NewCaptchaVerifier(cfg viper.LoginGuardConfig, env string) (CaptchaVerifier, error) {
+	if cfg.Mode != BotChallengeCaptcha {
+		return nil, nil
+	}
+	if cfg.CaptchaProvider == CaptchaProviderStub {
+		if env == "production" {
+			return nil, errors.New("LOGIN_CAPTCHA_PROVIDER=stub is not allowed in production")
+		}
+		return NewStubCaptchaVerifier(cfg.CaptchaStubToken), nil
+	}
+
+	if cfg.CaptchaSecret == "" {
+		return nil, fmt.Errorf("LOGIN_CAPTCHA_SECRET is required for provider %q", cfg.CaptchaProvider)
+	}
+	endpoint := cfg.CaptchaVerifyURL
+	if endpoint == "" {
+		endpoint = captchaVerifyURLs[cfg.CaptchaProvider]
+	}
+	if endpoint == "" {
+		return nil, fmt.Errorf("unknown captcha provider %q", cfg.CaptchaProvider)
+	}
+
+	return &siteVerifyCaptcha{
+		provider: cfg.CaptchaProvider,
+		secret:   cfg.CaptchaSecret,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: cfg.CaptchaTimeout},
+	}, nil
+}
+
+// siteVerifyCaptcha sprawdza odpowiedź widgetu u dostawcy (Turnstile, hCaptcha)
+type siteVerifyCaptcha struct {
+	provider string
+	secret   string
+	endpoint string
+	client   *http.Client
+}
+
+func (v *siteVerifyCaptcha) Provider() string { return v.provider }
+
+func (v *siteVerifyCaptcha) Verify(ctx context.Context, response string, ip string) (bool, error) {
+	if response == "" {
+		return false, nil
+	}
+
+	form := url.Values{"secret": {v.secret}, "response": {response}}
+	if ip != "" {
+		form.Set("remoteip", ip)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("%s siteverify: %w", v.provider, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return false, fmt.Errorf("%s siteverify: unexpected status %d", v.provider, resp.StatusCode)
+	}
+
+	var result struct {
+		Success bool `json:"success"`
+	}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxSiteVerifyBody)).Decode(&result); err != nil {
+		return false, fmt.Errorf("%s siteverify: %w", v.provider, err)
+	}
+	return result.Success, nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/auth-service/internal/http"
+	"github.com/zerodayz7/platform/services/auth-service/internal/shared/security"
+)
+
+const (
+	BotChallengePoW     = "pow"
+	BotChallengeCaptcha = "captcha"
+)
+
+// LoginAttempt grupuje metadane próby logowania potrzebne do oceny ryzyka.
+type LoginAttempt struct {
+	Fingerprint       string
+	IP                string
+	ChallengeToken    string
+	ChallengeSolution string
+}
+
+// CaptchaVerifier pozwala podpiąć dowolnego dostawcę CAPTCHA (hCaptcha, Turnstile...).
+// W trybie PoW LoginGuard nie ma weryfikatora (nil).
+type CaptchaVerifier interface {
+	Provider() string
+	Verify(ctx context.Context, response string, ip string) (bool, error)
+}
+
+// region stub captcha
+type stubCaptchaVerifier struct {
+	token string
+}
+
+// NewStubCaptchaVerifier zwraca lokalny weryfikator do developmentu – akceptuje jeden stały token.
+func NewStubCaptchaVerifier(token string) CaptchaVerifier {
+	return &stubCaptchaVerifier{token: token}
+}
+
+func (v *stubCaptchaVerifier) Provider() string { return "stub" }
+
+func (v *stubCaptchaVerifier) Verify(_ context.Context, response string, _ string) (bool, error) {
+	if v.token == "" || response == "" {
+		return false, nil
+	}
+	return subtle.ConstantTimeCompare([]byte(v.token), []byte(response)) == 1, nil
+}
+
+// region LoginGuard
+// LoginGuard liczy porażki logowania per IP / konto / fingerprint i po przekroczeniu
+// progu wymaga rozwiązania wyzwania, zanim hasło w ogóle zostanie sprawdzone.
+type LoginGuard struct {
+	cache      *redis.Cache
+	cfg        viper.LoginGuardConfig
+	reputation viper.ReputationConfig
+	captcha    CaptchaVerifier
+	audit      *events.AuditPublisher
+}
+
+func NewLoginGuard(cache *redis.Cache, cfg viper.LoginGuardConfig, reputation viper.ReputationConfig, captcha CaptchaVerifier, audit *events.AuditPublisher) *LoginGuard {
+	return &LoginGuard{cache: cache, cfg: cfg, reputation: reputation, captcha: captcha, audit: audit}
+}
+
+// dimensions buduje klucze liczników; email jest hashowany, żeby nie trzymać PII w Redis.
+func (g *LoginGuard) dimensions(email string, a LoginAttempt) []string {
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email))))
+	dims := []string{"acct:" + hex.EncodeToString(sum[:])}
+	if a.IP != "" {
+		dims = append(dims, "ip:"+a.IP)
+	}
+	if a.Fingerprint != "" {
+		dims = append(dims, "fpt:"+a.Fingerprint)
+	}
+	return dims
+}
+
+// Check zwraca wyzwanie do rozwiązania (nil = można kontynuować) albo błąd,
+// jeśli przesłane rozwiązanie jest niepoprawne.
+func (g *LoginGuard) Check(ctx context.Context, email string, a LoginAttempt) (*http.BotChallenge, error) {
+	log := shared.GetLogger()
+
+	fails, err := g.cache.MaxLoginFailures(ctx, g.dimensions(email, a)...)
+	if err != nil {
+		// Fail-open: awaria Redis nie może zablokować logowania (blokada konta w DB nadal działa)
+		log.ErrorObj("Login guard: failed to read counters", err)
+		return nil, nil
+	}
+	if fails < g.cfg.Threshold {
+		return nil, nil
+	}
+
+	if a.ChallengeToken == "" {
+		return g.issue(ctx, email, a)
+	}
+
+	ch, err := g.cache.TakeBotChallenge(ctx, a.ChallengeToken)
+	if err != nil {
+		return nil, errors.ErrBotChallengeFailed
+	}
+
+	if !g.verify(ctx, ch, email, a) {
+		log.WarnMap("Login guard: challenge rejected", map[string]any{"kind": ch.Kind, "ip": a.IP})
+		return nil, errors.ErrBotChallengeFailed
+	}
+	return nil, nil
+}
+
+func (g *LoginGuard) verify(ctx context.Context, ch *redis.BotChallenge, email string, a LoginAttempt) bool {
+	// Wyzwanie jest związane z kontem i urządzeniem, dla którego zostało wydane
+	if !strings.EqualFold(ch.Email, email) || ch.Fingerprint != a.Fingerprint {
+		return false
+	}
+
+	switch ch.Kind {
+	case BotChallengePoW:
+		return security.VerifyProofOfWork(ch.Nonce, a.ChallengeSolution, ch.Difficulty)
+	case BotChallengeCaptcha:
+		// Wyzwanie wydane przed zmianą trybu na PoW – bez weryfikatora nie przejdzie
+		if g.captcha == nil {
+			return false
+		}
+		ok, err := g.captcha.Verify(ctx, a.ChallengeSolution, a.IP)
+		if err != nil {
+			shared.GetLogger().ErrorObj("Login guard: captcha provider error", err)
+			return false
+		}
+		return ok
+	default:
+		return false
+	}
+}
+
+func (g *LoginGuard) issue(ctx context.Context, email string, a LoginAttempt) (*http.BotChallenge, error) {
+	token := shared.GenerateSessionID()
+	ch := redis.BotChallenge{
+		Kind:        g.cfg.Mode,
+		Email:       email,
+		Fingerprint: a.Fingerprint,
+	}
+
+	resp := &http.BotChallenge{
+		Kind:      g.cfg.Mode,
+		Token:     token,
+		ExpiresAt: time.Now().Add(g.cfg.ChallengeTTL).Unix(),
+	}
+
+	if g.cfg.Mode == BotChallengePoW {
+		nonce, err := shared.GenerateRandomChallenge(16)
+		if err != nil {
+			return nil, errors.ErrInternal
+		}
+		ch.Nonce = nonce
+		ch.Difficulty = g.cfg.PoWDifficulty
+		resp.Nonce = nonce
+		resp.Difficulty = g.cfg.PoWDifficulty
+	} else {
+		resp.Provider = g.captcha.Provider()
+	}
+
+	if err := g.cache.SetBotChallenge(ctx, token, ch, g.cfg.ChallengeTTL); err != nil {
+		shared.GetLogger().ErrorObj("Login guard: failed to store challenge", err)
+		return nil, errors.ErrInternal
+	}
+	return resp, nil
+}
+
+// RecordFailure zwiększa liczniki po nieudanej próbie logowania.
+func (g *LoginGuard) RecordFailure(ctx context.Context, email string, a LoginAttempt) {
+	if err := g.cache.IncrLoginFailures(ctx, g.cfg.Window, g.dimensions(email, a)...); err != nil {
+		shared.GetLogger().ErrorObj("Login guard: failed to increment counters", err)
+	}
+	g.penalize(ctx, a.IP)
+}
+
+// penalize dolicza punkty do reputacji IP współdzielonej z gatewayem – po przekroczeniu
+// progu gateway odrzuca adres jeszcze przed auth-service.
+func (g *LoginGuard) penalize(ctx context.Context, ip string) {
+	if !g.reputation.Enabled || g.reputation.FailedLoginPoints == 0 || ip == "" {
+		return
+	}
+	score, banned, err := g.cache.PenalizeIP(ctx, ip, int64(g.reputation.FailedLoginPoints), redis.ReputationPolicy{
+		Window:       g.reputation.Window,
+		BanThreshold: int64(g.reputation.BanThreshold),
+		BanDuration:  g.reputation.BanDuration,
+	})
+	if err != nil {
+		shared.GetLogger().ErrorObj("Login guard: failed to update IP reputation", err)
+		return
+	}
+	if !banned {
+		return
+	}
+	shared.GetLogger().WarnMap("Login guard: IP temporarily banned", map[string]any{"ip": ip, "score": score})
+	if err := g.audit.Record(ctx, uuid.Nil.String(), events.AuditIPBanned, ip, map[string]any{
+		"offense":  "failed_login",
+		"score":    score,
+		"duration": g.reputation.BanDuration.String(),
+	}); err != nil {
+		shared.GetLogger().ErrorObj("Login guard: failed to write audit record", err)
+	}
+}
+
+// RecordSuccess czyści liczniki konta i urządzenia. Licznik IP zostaje –
+// jedno udane logowanie z NAT-u nie może "wyczyścić" ataku na inne konta.
+func (g *LoginGuard) RecordSuccess(ctx context.Context, email string, a LoginAttempt) {
+	dims := g.dimensions(email, LoginAttempt{Fingerprint: a.Fingerprint})
+	if err := g.cache.ResetLoginFailures(ctx, dims...); err != nil {
+		shared.GetLogger().ErrorObj("Login guard: failed to reset counters", err)
+	}
+}
@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/auth-service/internal/http"
+	"github.com/zerodayz7/platform/services/auth-service/internal/model"
+	repo "github.com/zerodayz7/platform/services/auth-service/internal/repository"
+	"github.com/zerodayz7/platform/services/auth-service/internal/shared/security"
+)
+
+// token_type_hint (RFC 7009 / RFC 7662)
+const (
+	TokenHintAccess  = "access_token"
+	TokenHintRefresh = "refresh_token"
+)
+
+// Domyślne zakresy – tokeny nie niosą jeszcze własnego claimu "scope"
+const (
+	scopeAccess  = "api"
+	scopeRefresh = "offline_access"
+)
+
+// OAuthService obsługuje introspekcję i unieważnianie tokenów dla klientów zewnętrznych.
+// region interface
+type OAuthService interface {
+	AuthenticateClient(ctx context.Context, clientID string, secret []byte) (*model.OAuthClient, error)
+	Introspect(ctx context.Context, token string, hint string) (*http.IntrospectionResponse, error)
+	Revoke(ctx context.Context, token string, hint string) error
+}
+
+// region struct
+type oauthService struct {
+	clientRepo  repo.OAuthClientRepository
+	refreshRepo repo.RefreshTokenRepository
+	cache       *redis.Cache
+	cfg         *viper.Config
+}
+
+func NewOAuthService(clientRepo repo.OAuthClientRepository, refreshRepo repo.RefreshTokenRepository, cache *redis.Cache, cfg *viper.Config) OAuthService {
+	return &oauthService{
+		clientRepo: clientRepo, refreshRepo: refreshRepo, cache: cache, cfg: cfg,
+	}
+}
+
+// region AuthenticateClient
+func (s *oauthService) AuthenticateClient(ctx context.Context, clientID string, secret []byte) (*model.OAuthClient, error) {
+	defer func() {
+		for i := range secret {
+			secret[i] = 0
+		}
+	}()
+
+	if clientID == "" || len(secret) == 0 {
+		return nil, errors.ErrInvalidClient
+	}
+
+	client, err := s.clientRepo.GetByClientID(ctx, clientID)
+	if err != nil {
+		return nil, errors.ErrInvalidClient
+	}
+
+	valid, err := security.VerifyPassword(secret, client.SecretHash)
+	if err != nil || !valid {
+		shared.GetLogger().WarnMap("OAuth client authentication failed", map[string]any{"client_id": clientID})
+		return nil, errors.ErrInvalidClient
+	}
+	return client, nil
+}
+
+// region Introspect
+func (s *oauthService) Introspect(ctx context.Context, token string, hint string) (*http.IntrospectionResponse, error) {
+	// Hint to tylko podpowiedź – przy braku trafienia sprawdzamy drugi typ (RFC 7662 §2.1)
+	if hint == TokenHintRefresh {
+		if resp := s.introspectRefresh(token); resp != nil {
+			return resp, nil
+		}
+		if resp := s.introspectAccess(ctx, token); resp != nil {
+			return resp, nil
+		}
+	} else {
+		if resp := s.introspectAccess(ctx, token); resp != nil {
+			return resp, nil
+		}
+		if resp := s.introspectRefresh(token); resp != nil {
+			return resp, nil
+		}
+	}
+	return &http.IntrospectionResponse{Active: false}, nil
+}
+
+// introspectAccess zwraca nil, jeśli token nie jest poprawnym JWT; sesja musi nadal istnieć w Redis.
+func (s *oauthService) introspectAccess(ctx context.Context, token string) *http.IntrospectionResponse {
+	claims, ok := s.parseAccessToken(token)
+	if !ok {
+		return nil
+	}
+
+	uid, _ := claims["uid"].(string)
+	sid, _ := claims["sid"].(string)
+	session, err := s.cache.GetSession(ctx, sid)
+	if err != nil || session.UserID != uid {
+		return &http.IntrospectionResponse{Active: false}
+	}
+
+	scope, _ := claims["scope"].(string)
+	if scope == "" {
+		scope = scopeAccess
+	}
+
+	resp := &http.IntrospectionResponse{
+		Active:    true,
+		Scope:     scope,
+		TokenType: TokenHintAccess,
+		Sub:       uid,
+		SessionID: sid,
+	}
+	if exp, err := claims.GetExpirationTime(); err == nil && exp != nil {
+		resp.Exp = exp.Unix()
+	}
+	if iat, err := claims.GetIssuedAt(); err == nil && iat != nil {
+		resp.Iat = iat.Unix()
+	}
+	return resp
+}
+
+// introspectRefresh zwraca nil, jeśli token nie istnieje w tabeli refresh_tokens.
+func (s *oauthService) introspectRefresh(token string) *http.IntrospectionResponse {
+	rt, err := s.refreshRepo.GetByToken(hashRefreshToken(token))
+	if err != nil {
+		return nil
+	}
+	if rt.Revoked || rt.ExpiresAt.Before(time.Now()) {
+		return &http.IntrospectionResponse{Active: false}
+	}
+	return &http.IntrospectionResponse{
+		Active:    true,
+		Scope:     scopeRefresh,
+		TokenType: TokenHintRefresh,
+		Sub:       rt.UserID.String(),
+		Exp:       rt.ExpiresAt.Unix(),
+		Iat:       rt.CreatedAt.Unix(),
+	}
+}
+
+// region Revoke
+// Revoke unieważnia token. Nieznany lub już nieważny token nie jest błędem (RFC 7009 §2.2).
+func (s *oauthService) Revoke(ctx context.Context, token string, hint string) error {
+	log := shared.GetLogger()
+
+	if hint != TokenHintAccess {
+		if rt, err := s.refreshRepo.GetByToken(hashRefreshToken(token)); err == nil {
+			if err := s.refreshRepo.Revoke(rt.Token); err != nil {
+				log.ErrorObj("OAuth revoke: failed to revoke refresh token", err)
+				return errors.ErrInternal
+			}
+			log.InfoMap("OAuth revoke: refresh token revoked", map[string]any{"uid": rt.UserID})
+			return nil
+		}
+	}
+
+	claims, ok := s.parseAccessToken(token)
+	if !ok {
+		return nil
+	}
+	uid, _ := claims["uid"].(string)
+	sid, _ := claims["sid"].(string)
+	if _, err := uuid.Parse(uid); err != nil || sid == "" {
+		return nil
+	}
+
+	// Access token jest bezstanowy – unieważniamy sesję, którą gateway sprawdza przy każdym żądaniu
+	if err := s.cache.EndSession(ctx, sid, uid); err != nil {
+		log.ErrorObj("OAuth revoke: failed to end session", err)
+		return errors.ErrInternal
+	}
+	log.InfoMap("OAuth revoke: session ended", map[string]any{"uid": uid, "sid": sid})
+	return nil
+}
+
+func (s *oauthService) parseAccessToken(token string) (jwt.MapClaims, bool) {
+	parsed, err := security.ValidateJWT(token, s.cfg.JWT.AccessSecret)
+	if err != nil || !parsed.Valid {
+		return nil, false
+	}
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	return claims, ok
+}
+
+func hashRefreshToken(raw string) string {
+	hash := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(hash[:])
+}
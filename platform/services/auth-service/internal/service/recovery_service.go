@@ -0,0 +1,135 @@
+package service
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	repo "github.com/zerodayz7/platform/services/auth-service/internal/repository"
+	"github.com/zerodayz7/platform/services/auth-service/internal/shared/security"
+)
+
+const recoveryCodeCount = 10
+
+// Cel użycia kodu – trafia do metadanych eventu i treści powiadomienia
+const (
+	RecoveryPurpose2FA   = "2fa"
+	RecoveryPurposeReset = "password_reset"
+)
+
+// RecoveryService zarządza jednorazowymi kodami awaryjnymi.
+// region interface
+type RecoveryService interface {
+	// Generate unieważnia poprzedni zestaw i zwraca nowe kody (jawne – pokazywane tylko raz).
+	Generate(ctx context.Context, userID uuid.UUID, ip string) ([]string, error)
+	// EnsureGenerated tworzy zestaw tylko, jeśli użytkownik nie ma żadnego ważnego kodu.
+	EnsureGenerated(ctx context.Context, userID uuid.UUID, ip string) ([]string, error)
+	// Redeem zużywa kod; zwraca ErrInvalidRecoveryCode, jeśli kod nie istnieje lub był użyty.
+	Redeem(ctx context.Context, userID uuid.UUID, code string, purpose string, ip string) error
+}
+
+// region struct
+type recoveryService struct {
+	codeRepo repo.RecoveryCodeRepository
+	cache    *redis.Cache
+	emitter  *events.Emitter
+	cfg      *viper.Config
+}
+
+func NewRecoveryService(codeRepo repo.RecoveryCodeRepository, cache *redis.Cache, emitter *events.Emitter, cfg *viper.Config) RecoveryService {
+	return &recoveryService{
+		codeRepo: codeRepo, cache: cache, emitter: emitter, cfg: cfg,
+	}
+}
+
+// region Generate
+func (s *recoveryService) Generate(ctx context.Context, userID uuid.UUID, ip string) ([]string, error) {
+	log := shared.GetLogger()
+
+	codes, err := security.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		log.ErrorObj("Failed to generate recovery codes", err)
+		return nil, errors.ErrInternal
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = security.HashRecoveryCode(code, s.cfg.Internal.HashSalt)
+	}
+
+	if err := s.codeRepo.ReplaceForUser(ctx, userID, hashes); err != nil {
+		log.ErrorObj("Failed to store recovery codes", err)
+		return nil, errors.ErrInternal
+	}
+
+	if err := s.emitter.Emit(ctx, events.RecoveryCodesRegenerated, userID.String(),
+		events.WithIP(ip),
+		events.WithMetadata(map[string]any{"count": len(codes)}),
+	); err != nil {
+		log.ErrorObj("Failed to emit recovery codes event", err)
+	}
+
+	return codes, nil
+}
+
+// region EnsureGenerated
+func (s *recoveryService) EnsureGenerated(ctx context.Context, userID uuid.UUID, ip string) ([]string, error) {
+	remaining, err := s.codeRepo.CountRemaining(ctx, userID)
+	if err != nil {
+		return nil, errors.ErrInternal
+	}
+	if remaining > 0 {
+		return nil, nil
+	}
+	return s.Generate(ctx, userID, ip)
+}
+
+// region Redeem
+func (s *recoveryService) Redeem(ctx context.Context, userID uuid.UUID, code string, purpose string, ip string) error {
+	log := shared.GetLogger()
+
+	hash := security.HashRecoveryCode(code, s.cfg.Internal.HashSalt)
+	ok, err := s.codeRepo.Consume(ctx, userID, hash)
+	if err != nil {
+		log.ErrorObj("Failed to consume recovery code", err)
+		return errors.ErrInternal
+	}
+	if !ok {
+		log.WarnMap("Invalid or already used recovery code", map[string]any{"uid": userID, "purpose": purpose})
+		return errors.ErrInvalidRecoveryCode
+	}
+
+	remaining, _ := s.codeRepo.CountRemaining(ctx, userID)
+
+	// Event bezpieczeństwa (audit + notify)
+	if err := s.emitter.Emit(ctx, events.RecoveryCodeUsed, userID.String(),
+		events.WithIP(ip),
+		events.WithFlags(events.EventFlags{Audit: true, Notify: true}),
+		events.WithMetadata(map[string]any{"purpose": purpose, "remaining": remaining}),
+	); err != nil {
+		log.ErrorObj("Failed to emit recovery code event", err)
+	}
+
+	// Powiadomienie dla użytkownika (notification_stream)
+	if err := s.cache.SendNotification(ctx, map[string]any{
+		"user_id":  userID.String(),
+		"title":    "Użyto kodu odzyskiwania",
+		"content":  "Do Twojego konta zalogowano się przy użyciu jednorazowego kodu odzyskiwania. Jeśli to nie Ty, natychmiast skontaktuj się z obsługą.",
+		"priority": "high",
+		"category": "security",
+		"metadata": map[string]any{"purpose": purpose, "remaining": remaining, "ip": ip},
+	}); err != nil {
+		log.ErrorObj("Failed to send recovery code notification", err)
+	}
+
+	log.InfoMap("Recovery code redeemed", map[string]any{
+		"uid":       userID,
+		"purpose":   purpose,
+		"remaining": remaining,
+	})
+	return nil
+}
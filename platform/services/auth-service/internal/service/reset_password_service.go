@@ -33,10 +33,16 @@ type DeviceInfo struct {
 	IP          string
 }
 
+// ResetProof to dowód posiadania: podpis zaufanego urządzenia albo kod odzyskiwania
+type ResetProof struct {
+	Signature    string
+	RecoveryCode string
+}
+
 type PasswordResetService interface {
 	StartResetProcess(ctx context.Context, email string) (string, error)
 	VerifyCode(ctx context.Context, token, code string) (*ResetSession, error)
-	FinalizeReset(ctx context.Context, token, newPassword, signature string, device DeviceInfo) error
+	FinalizeReset(ctx context.Context, token, newPassword string, proof ResetProof, device DeviceInfo) error
 }
 
 type resetRepository interface {
@@ -51,17 +57,20 @@ type passwordResetService struct {
 	userRepo         resetRepository
 	refreshTokenRepo repository.RefreshTokenRepository
 	cache            *redis.Cache
+	recovery         RecoveryService
 }
 
 func NewPasswordResetService(
 	userRepo repository.UserRepository,
 	refreshTokenRepo repository.RefreshTokenRepository,
 	cache *redis.Cache, // 3. Cache
+	recovery RecoveryService,
 ) PasswordResetService {
 	return &passwordResetService{
 		userRepo:         userRepo,
 		refreshTokenRepo: refreshTokenRepo,
 		cache:            cache,
+		recovery:         recovery,
 	}
 }
 
@@ -116,7 +125,7 @@ func (s *passwordResetService) VerifyCode(ctx context.Context, token, code strin
 	return session, nil
 }
 
-func (s *passwordResetService) FinalizeReset(ctx context.Context, token, newPassword, signature string, device DeviceInfo) error {
+func (s *passwordResetService) FinalizeReset(ctx context.Context, token, newPassword string, proof ResetProof, device DeviceInfo) error {
 	session, err := s.getSession(ctx, token)
 	if err != nil {
 		return errors.ErrResetSessionNotFound
@@ -128,6 +137,17 @@ func (s *passwordResetService) FinalizeReset(ctx context.Context, token, newPass
 
 	userUUID, _ := uuid.Parse(session.UserID)
 
+	// Utracone urządzenie: kod odzyskiwania zastępuje podpis urządzenia
+	if proof.Signature == "" {
+		if proof.RecoveryCode == "" {
+			return errors.ErrUntrustedDevice
+		}
+		if err := s.recovery.Redeem(ctx, userUUID, proof.RecoveryCode, RecoveryPurposeReset, device.IP); err != nil {
+			return err
+		}
+		return s.applyNewPassword(ctx, token, userUUID, newPassword)
+	}
+
 	var pubKeyToVerify string
 	existingDevice, err := s.userRepo.GetDeviceByFingerprint(ctx, userUUID, device.Fingerprint)
 
@@ -155,10 +175,14 @@ func (s *passwordResetService) FinalizeReset(ctx context.Context, token, newPass
 	}
 
 	challenge := fmt.Sprintf("%s|%s", session.Challenge, token)
-	if !shared.VerifyEd25519Signature(pubKeyToVerify, challenge, signature) {
+	if !shared.VerifyEd25519Signature(pubKeyToVerify, challenge, proof.Signature) {
 		return errors.ErrVerificationFailed
 	}
 
+	return s.applyNewPassword(ctx, token, userUUID, newPassword)
+}
+
+func (s *passwordResetService) applyNewPassword(ctx context.Context, token string, userUUID uuid.UUID, newPassword string) error {
 	user, err := s.userRepo.GetByID(ctx, userUUID)
 	if err != nil {
 		return errors.ErrUserNotFound
@@ -166,6 +190,9 @@ func (s *passwordResetService) FinalizeReset(ctx context.Context, token, newPass
 
 	hashedPassword, _ := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	user.Password = string(hashedPassword)
+	user.MustResetPassword = false
+	now := time.Now()
+	user.PasswordChangedAt = &now
 
 	if err := s.userRepo.Update(ctx, user); err != nil {
 		return err
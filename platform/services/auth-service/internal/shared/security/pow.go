@@ -0,0 +1,28 @@
+package security
+
+import (
+	"crypto/sha256"
+	"math/bits"
+)
+
+// VerifyProofOfWork checks a hashcash-style solution: sha256(nonce ":" solution)
+// must start with at least `difficulty` zero bits.
+func VerifyProofOfWork(nonce, solution string, difficulty int) bool {
+	if solution == "" || difficulty <= 0 {
+		return false
+	}
+	sum := sha256.Sum256([]byte(nonce + ":" + solution))
+	return leadingZeroBits(sum[:]) >= difficulty
+}
+
+func leadingZeroBits(b []byte) int {
+	n := 0
+	for _, v := range b {
+		if v == 0 {
+			n += 8
+			continue
+		}
+		return n + bits.LeadingZeros8(v)
+	}
+	return n
+}
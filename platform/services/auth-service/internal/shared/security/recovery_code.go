@@ -0,0 +1,41 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// Alfabet bez znaków mylących przy przepisywaniu (0/O, 1/I/L)
+const recoveryAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+// GenerateRecoveryCodes returns n codes in the form XXXXX-XXXXX.
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, n)
+	buf := make([]byte, 10)
+	for i := range codes {
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		var sb strings.Builder
+		for j, b := range buf {
+			if j == 5 {
+				sb.WriteByte('-')
+			}
+			// 256 % 31 != 0 – minimalne obciążenie rozkładu jest akceptowalne przy 50 bitach entropii
+			sb.WriteByte(recoveryAlphabet[int(b)%len(recoveryAlphabet)])
+		}
+		codes[i] = sb.String()
+	}
+	return codes, nil
+}
+
+// HashRecoveryCode normalizes the code (case, separators) and returns hex HMAC-SHA256 keyed with salt.
+func HashRecoveryCode(code, salt string) string {
+	normalized := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	mac := hmac.New(sha256.New, []byte(salt))
+	mac.Write([]byte(normalized))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -23,12 +23,21 @@ func main() {
 	db, closeDB := config.MustInitDB(config.AppConfig.Database)
 	defer closeDB()
 
-	container := di.NewContainer(db, log, &config.AppConfig)
+	container, err := di.NewContainer(db, log, &config.AppConfig)
+	if err != nil {
+		log.Fatal("Container setup failed", "error", err)
+	}
 
 	app := config.NewDocsApp(container)
 
 	router.SetupDocsRoutes(app, container.UserDocumentSvc)
 
+	// MTLS_ENABLED – serwis przyjmuje tylko połączenia z certyfikatem klienta (gateway)
+	tlsConfig, certs, err := server.MutualTLS(config.AppConfig.MTLS)
+	if err != nil {
+		log.Fatal("mTLS setup failed", "error", err)
+	}
+
 	server.Run(
 		app,
 		server.Config{
@@ -37,10 +46,12 @@ func main() {
 			AppVersion: config.AppConfig.Server.AppVersion,
 			Env:        config.AppConfig.Server.Env,
 			Shutdown:   config.AppConfig.Shutdown,
+			TLS:        tlsConfig,
 		},
 		*log,
 		func() {
 			closeDB()
+			certs.Close()
 		},
 	)
 }
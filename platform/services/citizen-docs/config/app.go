@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/zerodayz7/platform/pkg/middleware"
 	"github.com/zerodayz7/platform/pkg/server"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/citizen-docs/internal/di"
@@ -28,7 +29,7 @@ func NewDocsApp(container *di.Container) *fiber.App {
 		EnableIPValidation:      true,
 		ProxyHeader:             fiber.HeaderXForwardedFor,
 		EnableTrustedProxyCheck: true,
-		TrustedProxies:          []string{"127.0.0.1", "::1"},
+		TrustedProxies:          cfg.TrustedProxies, // adres gatewaya (TRUSTED_PROXIES)
 		ErrorHandler:            server.ErrorHandler(),
 	})
 
@@ -40,8 +41,13 @@ func NewDocsApp(container *di.Container) *fiber.App {
 	app.Use(shared.GetLimiter(shared.LimitGlobal, nil))
 	app.Use(shared.RequestLoggerMiddleware())
 
-	// Jeśli potrzebujesz autoryzacji wewnętrznej (HMAC) jak w auth-service:
-	// app.Use(middleware.InternalAuthMiddleware(container.Config.Internal.HMACSecret))
+	// Podpisany kontekst gatewaya. Serwis nie korzysta z Redis, więc bez wykrywania
+	// powtórzonego nonce – chronią go krótka ważność koperty i odbiorca (INTERNAL_AUDIENCE).
+	app.Use(middleware.InternalAuthMiddleware(middleware.InternalAuthConfig{
+		Keys:     container.InternalKeys,
+		Audience: container.Config.Internal.Audience,
+		Strict:   container.Config.Internal.Strict,
+	}))
 
 	return app
 }
@@ -1,6 +1,9 @@
 package di
 
 import (
+	"fmt"
+
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/pkg/viper"
 	"github.com/zerodayz7/platform/services/citizen-docs/internal/repository"
@@ -14,9 +17,10 @@ type Container struct {
 	Logger          *shared.Logger
 	UserDocumentSvc *service.UserDocumentService
 	CitizenSvc      *service.CitizenService
+	InternalKeys    *reqctx.Keyring
 }
 
-func NewContainer(db *gorm.DB, logger *shared.Logger, cfg *viper.Config) *Container {
+func NewContainer(db *gorm.DB, logger *shared.Logger, cfg *viper.Config) (*Container, error) {
 	// Repozytoria
 	docRepo := repository.NewUserDocumentRepository(db)
 	citizenRepo := repository.NewCitizenRepository(db)
@@ -24,11 +28,17 @@ func NewContainer(db *gorm.DB, logger *shared.Logger, cfg *viper.Config) *Contai
 	docSvc := service.NewUserDocumentService(docRepo, cfg, logger)
 	citizenSvc := service.NewCitizenService(citizenRepo, cfg, logger)
 
+	internalKeys, err := reqctx.NewKeyring(cfg.Internal.KeyID, []byte(cfg.Internal.HMACSecret), cfg.Internal.AcceptedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("INTERNAL_HMAC_ACCEPTED_KEYS: %w", err)
+	}
+
 	return &Container{
 		DB:              db,
 		Config:          cfg,
 		Logger:          logger,
 		UserDocumentSvc: docSvc,
 		CitizenSvc:      citizenSvc,
-	}
+		InternalKeys:    internalKeys,
+	}, nil
 }
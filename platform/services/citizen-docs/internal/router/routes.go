@@ -2,6 +2,8 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/openapi"
+	"github.com/zerodayz7/platform/services/citizen-docs/config"
 	"github.com/zerodayz7/platform/services/citizen-docs/internal/handler"
 	"github.com/zerodayz7/platform/services/citizen-docs/internal/service"
 )
@@ -12,6 +14,11 @@ func SetupDocsRoutes(app *fiber.App, userDocService *service.UserDocumentService
 
 	SetupHealthRoutes(app) // np. /health
 
+	spec := openapi.New(app, openapi.Info{
+		Title:   config.AppConfig.Server.AppName,
+		Version: config.AppConfig.Server.AppVersion,
+	})
+
 	docs := app.Group("/documents")
 
 	docs.Post("/", h.CreateDocument)
@@ -21,5 +28,8 @@ func SetupDocsRoutes(app *fiber.App, userDocService *service.UserDocumentService
 	// docs.Put("/:id", h.UpdateDocument)
 	// docs.Delete("/:id", h.DeleteDocument)
 
+	// GET /openapi.json – trasy zarejestrowane powyżej (pkg/openapi)
+	spec.RegisterRoutes()
+
 	SetupFallbackHandlers(app)
 }
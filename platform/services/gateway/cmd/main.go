@@ -42,10 +42,38 @@ func main() {
 	defer redisClient.Close()
 
 	// 5. DI & App Setup
-	container := di.NewContainer(redisClient, &config.AppConfig)
+	container, err := di.NewContainer(redisClient, &config.AppConfig)
+	if err != nil {
+		log.Fatal("Gateway setup failed (routes table / OpenAPI specs)", "file", config.AppConfig.Routes.File, "error", err)
+	}
+	if config.AppConfig.Routes.Watch {
+		if err := container.Routes.Watch(); err != nil {
+			log.ErrorObj("Routes watcher failed, hot reload disabled", err)
+		}
+	}
+	container.Upstreams.StartHealthChecks()
+	container.Streams.Start()
+	container.ResponseCache.StartPurgeConsumer()
+	container.AppVersions.Start()
+	container.Overrides.Start()
+	container.Maintenance.Start()
+	log.InfoMap("Routes loaded", map[string]any{
+		"file":    config.AppConfig.Routes.File,
+		"routes":  container.Routes.Table().Len(),
+		"openapi": container.OpenAPI.Services(),
+	})
+
 	app := config.NewGatewayApp(container)
 	router.SetupRoutes(app, container)
 
+	// Admin API na osobnym adresie – nie przechodzi przez ingress
+	var extra []server.Listener
+	if config.AppConfig.Admin.Enabled {
+		adminApp := config.NewAdminApp(container)
+		router.SetupAdminRoutes(adminApp, container)
+		extra = append(extra, server.Listener{Name: "admin", App: adminApp, Address: config.AppConfig.Admin.Address})
+	}
+
 	// 6. Run server with unified shutdown handler
 	server.Run(
 		app,
@@ -55,10 +83,20 @@ func main() {
 			AppVersion: config.AppConfig.Server.AppVersion,
 			Env:        config.AppConfig.Server.Env,
 			Shutdown:   config.AppConfig.Shutdown,
+			Extra:      extra,
 		},
 		*log,
 		func() {
+			// Połączenia WebSocket / SSE nie są objęte graceful shutdown Fibera – zrywamy je jawnie
+			container.Streams.Close()
 			_ = redisClient.Close()
+			_ = container.Routes.Close()
+			container.Upstreams.Close()
+			container.ResponseCache.Close()
+			container.AppVersions.Close()
+			container.Overrides.Close()
+			container.Maintenance.Close()
+			container.UpstreamTLS.Close()
 			// Additional resource cleanup (e.g., database) can be added here in the future.
 		},
 	)
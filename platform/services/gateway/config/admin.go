@@ -0,0 +1,42 @@
+package config
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/zerodayz7/platform/pkg/server"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+)
+
+// NewAdminApp – aplikacja admin API (ADMIN_ADDRESS). Bez tras publicznych: każde
+// żądanie przechodzi przez JWT, sesję Redis i kontekst jak trasy chronione.
+func NewAdminApp(container *di.Container) *fiber.App {
+	cfg := container.Config.Server
+
+	app := fiber.New(fiber.Config{
+		AppName:               cfg.AppName + "-admin",
+		ReadTimeout:           cfg.ReadTimeout,
+		WriteTimeout:          cfg.WriteTimeout,
+		IdleTimeout:           cfg.IdleTimeout,
+		DisableStartupMessage: true,
+		ErrorHandler:          server.ErrorHandler(),
+	})
+
+	app.Use(container.Proxies.Middleware())
+	app.Use(requestid.New())
+	app.Use(recover.New())
+	app.Use(shared.RequestLoggerMiddleware())
+	app.Use(container.Network.AdminOnly())
+	// Tylko tokeny bearer – ciasteczko portalu (ten sam host) nie otwiera admin API
+	app.Use(JWTMiddlewareWithExclusions(noPublicEndpoints{}, container.Network, nil))
+	app.Use(middleware.AuthRedisMiddleware(container.Cache, container.Config.Session, noPublicEndpoints{}, nil, container.DeviceProofs))
+	app.Use(middleware.ContextBuilder(noPublicEndpoints{}))
+
+	return app
+}
+
+type noPublicEndpoints struct{}
+
+func (noPublicEndpoints) IsPublic(*fiber.Ctx) bool { return false }
@@ -8,6 +8,7 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/helmet"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	"github.com/zerodayz7/platform/pkg/server"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/gateway/internal/di"
@@ -27,20 +28,27 @@ func NewGatewayApp(container *di.Container) *fiber.App {
 		ReadTimeout:   cfg.ReadTimeout,
 		WriteTimeout:  cfg.WriteTimeout,
 
-		ProxyHeader:             fiber.HeaderXForwardedFor,
-		EnableTrustedProxyCheck: true,
-		TrustedProxies:          []string{"127.0.0.1", "::1"},
-		BodyLimit:               cfg.BodyLimitMB * 1024 * 1024,
-		DisableStartupMessage:   true,
-		EnableIPValidation:      true,
+		BodyLimit: cfg.BodyLimitMB * 1024 * 1024,
+		// Body większe niż BodyLimit nie są odrzucane przez fasthttp, tylko strumieniowane –
+		// limity egzekwuje dispatcher per trasa (body_limit_mb w routes.yaml)
+		StreamRequestBody:            true,
+		DisablePreParseMultipartForm: true,
+		DisableStartupMessage:        true,
+		EnableIPValidation:           true,
 
 		ErrorHandler: server.ErrorHandler(),
 	}
 
 	app := fiber.New(cfgFiber)
 
+	// Trasy publiczne z routes.yaml + własne endpointy gatewaya
+	public := middleware.PublicRoutes(fiber.MethodGet + " " + openapi.DocsPath)
+
 	// Middleware
 	app.Use(otelfiber.Middleware())
+	// Adres klienta za zaufanymi proxy (TRUSTED_PROXIES) – bez ProxyHeader w konfiguracji Fiber
+	// c.IP() to adres połączenia; wszyscy dalej czytają reqctx.ClientIP / RequestContext.IP
+	app.Use(container.Proxies.Middleware())
 	app.Use(requestid.New())
 	app.Use(recover.New())
 	app.Use(helmet.New(HelmetConfig()))
@@ -48,9 +56,13 @@ func NewGatewayApp(container *di.Container) *fiber.App {
 	app.Use(shared.GetLimiter(shared.LimitGlobal, container.Redis.AsFiberStorage()))
 	app.Use(compress.New(CompressConfig()))
 	app.Use(shared.RequestLoggerMiddleware())
-	app.Use(JWTMiddlewareWithExclusions())
-	app.Use(middleware.AuthRedisMiddleware(container.Redis.Client))
-	app.Use(middleware.ContextBuilder())
+	// Listy sieci, kraje i blokady IP – przed JWT, więc zablokowany adres nie zdobywa punktów za tokeny
+	app.Use(container.Network.Middleware())
+	// Przed JWT – usuwa prefiks wersji (/v2/...), więc trasy publiczne są rozpoznawane bez niego
+	app.Use(middleware.APIVersion(container.Routes, container.AppVersions))
+	app.Use(JWTMiddlewareWithExclusions(public, container.Network, container.WebSessions))
+	app.Use(middleware.AuthRedisMiddleware(container.Cache, container.Config.Session, public, container.WebSessions, container.DeviceProofs))
+	app.Use(middleware.ContextBuilder(public))
 
 	return app
 }
@@ -7,9 +7,11 @@ import (
 func CorsConfig() cors.Config {
 	allowOrigins := AppConfig.CORSAllow
 	return cors.Config{
-		AllowOrigins:     allowOrigins,
-		AllowMethods:     "GET,POST,PUT,DELETE",
-		AllowHeaders:     "Origin, Content-Type, Accept, Authorization, X-CSRF-TOKEN",
-		AllowCredentials: false,
+		AllowOrigins:  allowOrigins,
+		AllowMethods:  "GET,POST,PUT,DELETE",
+		AllowHeaders:  "Origin, Content-Type, Accept, Authorization, X-CSRF-TOKEN, X-Session-Mode, DPoP, Idempotency-Key, API-Version, X-App-Version, X-Canary",
+		ExposeHeaders: "API-Version, Deprecation, Sunset, Link, X-Upstream-Variant",
+		// Ciasteczko sesji portalu (WEB_SESSION_ENABLED); wymaga jawnej listy originów
+		AllowCredentials: AppConfig.WebSession.Enabled,
 	}
 }
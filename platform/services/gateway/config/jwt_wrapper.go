@@ -1,25 +1,42 @@
 package config
 
 import (
-	"slices"
+	"errors"
 
 	jwtware "github.com/gofiber/contrib/jwt"
 	"github.com/gofiber/fiber/v2"
-	"github.com/zerodayz7/platform/pkg/constants"
+	jwt "github.com/golang-jwt/jwt/v5"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"github.com/zerodayz7/platform/services/gateway/internal/websession"
 )
 
 var SkipJWT = false
 
-func JWTMiddlewareWithExclusions() fiber.Handler {
+// JWTMiddlewareWithExclusions weryfikuje token bearer na trasach chronionych. Żądanie portalu
+// bez Authorization, ale z ciasteczkiem sesji (web != nil) sprawdza dalej AuthRedisMiddleware.
+func JWTMiddlewareWithExclusions(public middleware.PublicMatcher, network *netguard.Guard, web *websession.Manager) fiber.Handler {
 	if SkipJWT {
 		return func(c *fiber.Ctx) error {
 			return c.Next()
 		}
 	}
 
-	jwtHandler := jwtware.New(NewJWTConfig())
+	cfg := NewJWTConfig()
+	// Token z błędnym podpisem to próba fałszerstwa (wygasły czy brakujący – nie), więc liczy się do reputacji IP
+	cfg.ErrorHandler = func(c *fiber.Ctx, err error) error {
+		if errors.Is(err, jwt.ErrTokenSignatureInvalid) {
+			network.Penalize(c.UserContext(), reqctx.ClientIP(c), netguard.OffenseInvalidSignature, nil)
+		}
+		return jwtErrorHandler(c, err)
+	}
+	jwtHandler := jwtware.New(cfg)
 	return func(c *fiber.Ctx) error {
-		if slices.Contains(constants.PublicPaths, c.Path()) {
+		if public.IsPublic(c) {
+			return c.Next()
+		}
+		if web != nil && c.Get(fiber.HeaderAuthorization) == "" && web.SessionID(c) != "" {
 			return c.Next()
 		}
 		return jwtHandler(c)
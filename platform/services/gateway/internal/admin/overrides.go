@@ -0,0 +1,197 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"github.com/zerodayz7/platform/services/gateway/internal/upstream"
+)
+
+// Rodzaje zmian operatora (redis.GatewayOverride.Kind)
+const (
+	KindDrain       = "drain"       // Target: upstream albo "upstream instancja"
+	KindBreaker     = "breaker"     // Target: upstream, Value: open | closed
+	KindMaintenance = "maintenance" // Target: klucz trasy (routing.Route.Key)
+	KindWindow      = "window"      // Target: ID okna, Value: routing.MaintenanceSpec (JSON)
+)
+
+// Overrides stosuje zmiany operatorów z admin API. Zmiany są zapisywane w Redis,
+// więc obowiązują wszystkie instancje gatewaya: każda odczytuje je co SyncInterval
+// (instancja, która przyjęła żądanie – od razu). Przy niedostępnym Redis obowiązuje
+// ostatni odczytany stan.
+type Overrides struct {
+	cache     *redis.Cache
+	upstreams *upstream.Client
+	routes    *routing.Store
+	interval  time.Duration
+
+	current atomic.Pointer[snapshot]
+	mu      sync.Mutex // jedno stosowanie naraz (tło + żądanie admin API)
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+type snapshot struct {
+	all         []redis.GatewayOverride
+	maintenance map[string]*redis.GatewayOverride
+	windows     []*routing.MaintenanceWindow
+}
+
+func NewOverrides(cache *redis.Cache, upstreams *upstream.Client, routes *routing.Store, interval time.Duration) *Overrides {
+	o := &Overrides{cache: cache, upstreams: upstreams, routes: routes, interval: interval, stop: make(chan struct{})}
+	o.current.Store(&snapshot{})
+	return o
+}
+
+// Start uruchamia okresowe stosowanie zmian
+func (o *Overrides) Start() {
+	go func() {
+		ticker := time.NewTicker(o.interval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), o.interval)
+			if err := o.Sync(ctx); err != nil {
+				shared.GetLogger().WarnMap("Admin overrides unavailable, keeping previous", map[string]any{"error": err.Error()})
+			}
+			cancel()
+
+			select {
+			case <-o.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close zatrzymuje stosowanie zmian
+func (o *Overrides) Close() {
+	o.stopOnce.Do(func() { close(o.stop) })
+}
+
+// Sync odczytuje zmiany z Redis i stosuje je do upstreamów i tras
+func (o *Overrides) Sync(ctx context.Context) error {
+	list, err := o.cache.GatewayOverrides(ctx)
+	if err != nil {
+		return err
+	}
+	o.apply(list)
+	return nil
+}
+
+func (o *Overrides) apply(list []redis.GatewayOverride) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	snap := &snapshot{all: list, maintenance: make(map[string]*redis.GatewayOverride)}
+	breakers := make(map[string]upstream.State)
+	drains := make(map[string]bool)
+	for i, ov := range list {
+		switch ov.Kind {
+		case KindMaintenance:
+			snap.maintenance[ov.Target] = &list[i]
+		case KindBreaker:
+			if state, ok := ParseBreakerState(ov.Value); ok {
+				breakers[ov.Target] = state
+			}
+		case KindDrain:
+			drains[ov.Target] = true
+		case KindWindow:
+			if w := o.compileWindow(ov); w != nil {
+				snap.windows = append(snap.windows, w)
+			}
+		}
+	}
+
+	// Upstream bez wpisu wraca do automatu – tak działa cofnięcie zmiany na innych instancjach
+	for _, up := range o.upstreams.All() {
+		if state, ok := breakers[up.Name]; ok {
+			up.Breaker.Force(&state)
+		} else {
+			up.Breaker.Force(nil)
+		}
+		for _, inst := range up.Pool.Instances() {
+			up.Pool.SetDraining(inst.URL, drains[up.Name] || drains[DrainTarget(up.Name, inst.URL)])
+		}
+	}
+
+	o.current.Store(snap)
+}
+
+// Set zapisuje zmianę operatora i od razu stosuje ją na tej instancji
+func (o *Overrides) Set(ctx context.Context, ov redis.GatewayOverride) error {
+	ov.At = time.Now().Unix()
+	if err := o.cache.SetGatewayOverride(ctx, ov); err != nil {
+		return err
+	}
+	return o.Sync(ctx)
+}
+
+// Delete cofa zmianę operatora i od razu stosuje stan bez niej
+func (o *Overrides) Delete(ctx context.Context, kind, target string) error {
+	if err := o.cache.DeleteGatewayOverride(ctx, redis.GatewayOverride{Kind: kind, Target: target}.Field()); err != nil {
+		return err
+	}
+	return o.Sync(ctx)
+}
+
+// List zwraca obowiązujące zmiany (stan z ostatniej synchronizacji)
+func (o *Overrides) List() []redis.GatewayOverride {
+	return o.current.Load().all
+}
+
+// Maintenance zwraca wpis trybu serwisowego trasy albo nil
+func (o *Overrides) Maintenance(route *routing.Route) *redis.GatewayOverride {
+	return o.current.Load().maintenance[route.Key()]
+}
+
+// Windows zwraca okna serwisowe zaplanowane przez admin API
+func (o *Overrides) Windows() []*routing.MaintenanceWindow {
+	return o.current.Load().windows
+}
+
+// compileWindow – okno zapisane w Redis; nieprawidłowe (np. upstream usunięty z konfiguracji) jest pomijane
+func (o *Overrides) compileWindow(ov redis.GatewayOverride) *routing.MaintenanceWindow {
+	var spec routing.MaintenanceSpec
+	err := json.Unmarshal([]byte(ov.Value), &spec)
+	var w *routing.MaintenanceWindow
+	if err == nil {
+		w, err = o.routes.CompileMaintenance(spec)
+	}
+	if err != nil {
+		shared.GetLogger().WarnMap("Admin maintenance window ignored", map[string]any{
+			"window": ov.Target,
+			"error":  err.Error(),
+		})
+		return nil
+	}
+	return w
+}
+
+// DrainTarget – cel drain pojedynczej instancji
+func DrainTarget(upstreamName, instance string) string {
+	if instance == "" {
+		return upstreamName
+	}
+	return upstreamName + " " + instance
+}
+
+// ParseBreakerState – stan, który operator może wymusić (open | closed)
+func ParseBreakerState(value string) (upstream.State, bool) {
+	switch strings.ToLower(value) {
+	case upstream.StateOpen.String():
+		return upstream.StateOpen, true
+	case upstream.StateClosed.String():
+		return upstream.StateClosed, true
+	}
+	return 0, false
+}
@@ -0,0 +1,35 @@
+package canary
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metryki OTEL – eksportowane, gdy w procesie zarejestrowany jest MeterProvider
+var (
+	meter = otel.Meter("github.com/zerodayz7/platform/services/gateway/canary")
+
+	requests, _  = meter.Int64Counter("gateway.canary.requests", metric.WithDescription("Żądania tras z canary per wariant i wynik (error = 5xx / błąd transportu)"))
+	rollbacks, _ = meter.Int64Counter("gateway.canary.rollbacks", metric.WithDescription("Automatyczne wycofania canary"))
+)
+
+// RecordRequest zlicza żądanie w wariancie trasy
+func RecordRequest(route, variant string, failed bool) {
+	outcome := "success"
+	if failed {
+		outcome = "error"
+	}
+	requests.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("route", route),
+		attribute.String("variant", variant),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// RecordRollback zlicza wycofanie canary trasy
+func RecordRollback(route string) {
+	rollbacks.Add(context.Background(), 1, metric.WithAttributes(attribute.String("route", route)))
+}
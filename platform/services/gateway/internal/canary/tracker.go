@@ -0,0 +1,172 @@
+package canary
+
+import (
+	"hash/fnv"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// Warianty trasy z canary
+const (
+	VariantStable = "stable"
+	VariantCanary = "canary"
+)
+
+// Powód przydziału do wariantu (logi)
+const (
+	ReasonWeight     = "weight"
+	ReasonHeader     = "header"
+	ReasonRolledBack = "rolled_back"
+)
+
+// Tracker przydziela żądania do wariantów i porównuje ich odsetek błędów. Stan jest
+// w pamięci instancji gatewaya – każda instancja wycofuje canary samodzielnie.
+// Klucz stanu obejmuje upstream i wagę canary, więc nowe wydanie w routes.yaml
+// (lub zmiana wagi) zaczyna od czystych liczników, a przeładowanie innych tras ich nie zeruje.
+type Tracker struct {
+	mu     sync.Mutex
+	states map[string]*state
+}
+
+type state struct {
+	started    time.Time
+	stable     counts
+	canary     counts
+	rolledBack bool
+}
+
+type counts struct {
+	total  int
+	failed int
+}
+
+func (c counts) rate() float64 {
+	if c.total == 0 {
+		return 0
+	}
+	return float64(c.failed) / float64(c.total)
+}
+
+func NewTracker() *Tracker {
+	return &Tracker{states: make(map[string]*state)}
+}
+
+// Decision – przydział żądania do wariantu; Record zapisuje jego wynik
+type Decision struct {
+	Variant string
+	Reason  string
+
+	tracker *Tracker
+	route   *routing.Route
+}
+
+// Choose wybiera wariant dla żądania. key – identyfikator klienta dla sticky: user
+// (użytkownik albo IP), override – wartość nagłówka X-Canary, jeśli klient może go użyć.
+func (t *Tracker) Choose(route *routing.Route, key, override string) *Decision {
+	d := &Decision{Variant: VariantStable, Reason: ReasonWeight, tracker: t, route: route}
+
+	if t.RolledBack(route) {
+		d.Reason = ReasonRolledBack
+		return d
+	}
+
+	switch strings.ToLower(strings.TrimSpace(override)) {
+	case VariantCanary, "1", "true":
+		d.Variant, d.Reason = VariantCanary, ReasonHeader
+		return d
+	case VariantStable, "0", "false":
+		d.Reason = ReasonHeader
+		return d
+	}
+
+	var bucket int
+	if route.Canary.Sticky == routing.CanaryStickyRequest {
+		bucket = rand.IntN(100)
+	} else {
+		// Sól z upstreamu canary – kolejne wydania trafiają do innych użytkowników
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(route.CanaryTarget + "|" + key))
+		bucket = int(h.Sum32() % 100)
+	}
+	if bucket < route.Canary.Weight {
+		d.Variant = VariantCanary
+	}
+	return d
+}
+
+// RolledBack – czy canary trasy zostało wycofane na tej instancji
+func (t *Tracker) RolledBack(route *routing.Route) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.states[stateKey(route)]
+	return s != nil && s.rolledBack
+}
+
+// Record zapisuje wynik żądania (failed = 5xx albo błąd transportu). Przy regule
+// rollback sprawdza, czy canary nie wypada gorzej od wersji bazowej.
+func (d *Decision) Record(failed bool) {
+	RecordRequest(d.route.Path, d.Variant, failed)
+
+	rb := d.route.Canary.Rollback
+	if rb == nil {
+		return
+	}
+
+	t := d.tracker
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := stateKey(d.route)
+	s := t.states[key]
+	now := time.Now()
+	if s == nil {
+		s = &state{started: now}
+		t.states[key] = s
+	}
+	if s.rolledBack {
+		return
+	}
+	// Okno stałe – po jego upływie obie strony zaczynają od zera
+	if now.Sub(s.started) >= rb.Window {
+		s.started, s.stable, s.canary = now, counts{}, counts{}
+	}
+
+	c := &s.stable
+	if d.Variant == VariantCanary {
+		c = &s.canary
+	}
+	c.total++
+	if failed {
+		c.failed++
+	}
+
+	if d.Variant != VariantCanary || s.canary.total < rb.MinRequests {
+		return
+	}
+	canaryRate, stableRate := s.canary.rate(), s.stable.rate()
+	if canaryRate-stableRate <= rb.MaxErrorRateDelta {
+		return
+	}
+
+	s.rolledBack = true
+	RecordRollback(d.route.Path)
+	shared.GetLogger().ErrorMap("Canary rolled back: error rate above baseline", map[string]any{
+		"route":             d.route.Path,
+		"canary":            d.route.CanaryTarget,
+		"canary_rate":       canaryRate,
+		"canary_requests":   s.canary.total,
+		"baseline_rate":     stableRate,
+		"baseline_requests": s.stable.total,
+		"max_delta":         rb.MaxErrorRateDelta,
+	})
+}
+
+func stateKey(route *routing.Route) string {
+	return route.Key() + " " + route.CanaryTarget + " " + strconv.Itoa(route.Canary.Weight)
+}
@@ -0,0 +1,23 @@
+package deviceproof
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+var (
+	meter = otel.Meter("github.com/zerodayz7/platform/services/gateway/deviceproof")
+
+	checks, _ = meter.Int64Counter("gateway.device_proof.checks", metric.WithDescription("Weryfikacje dowodów urządzenia (result: ok, missing, invalid_signature, mismatch, expired, replay, ...)"))
+)
+
+// RecordCheck zlicza weryfikację dowodu; w trybie report pokazuje, ilu klientów odrzuciłby enforce
+func RecordCheck(mode, result string) {
+	checks.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("mode", mode),
+		attribute.String("result", result),
+	))
+}
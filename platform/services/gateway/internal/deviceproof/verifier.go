@@ -0,0 +1,183 @@
+package deviceproof
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/constants"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"go.uber.org/zap"
+)
+
+// Tryby DEVICE_PROOF_MODE
+const (
+	ModeOff     = "off"
+	ModeReport  = "report"
+	ModeEnforce = "enforce"
+)
+
+// Wyniki weryfikacji (log żądania, metryki)
+const (
+	ResultOK        = "ok"
+	ReasonMissing   = "missing"
+	ReasonMalformed = "malformed"
+	ReasonSignature = "invalid_signature"
+	ReasonMismatch  = "mismatch" // metoda, URL albo skrót tokenu inne niż w żądaniu
+	ReasonExpired   = "expired"
+	ReasonReplay    = "replay"
+	ReasonDeviceKey = "device_key" // uszkodzony klucz w sesji
+)
+
+// ProofType – nagłówek "typ" dowodu (RFC 9449)
+const ProofType = "dpop+jwt"
+
+// Zegary telefonów się rozjeżdżają – dowód "z przyszłości" przyjmujemy z tym zapasem
+const clockSkew = 30 * time.Second
+
+const maxNonceLength = 128
+
+// Verifier sprawdza dowody posiadania klucza urządzenia. Sesja z kluczem urządzenia
+// (UserDevice.PublicKey, zapisany w sesji przez auth-service) wymaga w każdym żądaniu
+// z tokenem bearer nagłówka DPoP: JWT (EdDSA) podpisanego tym kluczem, z metodą (htm),
+// URL (htu), czasem (iat), nonce (jti) i skrótem tokenu dostępu (ath). Nonce trafia do
+// Redis, więc przechwyconego dowodu nie da się powtórzyć, a sam token bez klucza nic nie daje.
+type Verifier struct {
+	cache   *redis.Cache
+	network *netguard.Guard
+	mode    string
+	maxAge  time.Duration
+	parser  *jwt.Parser
+}
+
+// NewVerifier zwraca nil, gdy dowody są wyłączone (DEVICE_PROOF_MODE=off)
+func NewVerifier(cache *redis.Cache, network *netguard.Guard, cfg viper.DeviceProofConfig) *Verifier {
+	if cfg.Mode == ModeOff {
+		return nil
+	}
+	return &Verifier{
+		cache:   cache,
+		network: network,
+		mode:    cfg.Mode,
+		maxAge:  cfg.MaxAge,
+		// Czas sprawdzamy sami (iat + maxAge), exp w dowodach nie występuje
+		parser: jwt.NewParser(jwt.WithValidMethods([]string{jwt.SigningMethodEdDSA.Alg()}), jwt.WithoutClaimsValidation()),
+	}
+}
+
+// Check weryfikuje dowód żądania dla sesji powiązanej z urządzeniem. path to ścieżka
+// w postaci wysłanej przez klienta. W trybie report błędny dowód jest tylko odnotowany.
+func (v *Verifier) Check(c *fiber.Ctx, sessionID string, session *redis.UserSession, accessToken, path string) *apperr.AppError {
+	reason := v.verify(c, sessionID, session, accessToken, path)
+	RecordCheck(v.mode, reason)
+	if reason == ResultOK {
+		return nil
+	}
+
+	shared.AddLogField(c, zap.String("device_proof", reason))
+	if reason != ReasonMissing {
+		shared.GetLogger().WarnMap("Invalid device proof", map[string]any{
+			"reason": reason,
+			"sid":    sessionID,
+			"uid":    session.UserID,
+			"path":   path,
+			"mode":   v.mode,
+		})
+	}
+	if v.mode != ModeEnforce {
+		return nil
+	}
+
+	if reason == ReasonSignature && v.network != nil {
+		var uid *uuid.UUID
+		if id, err := uuid.Parse(session.UserID); err == nil {
+			uid = &id
+		}
+		v.network.Penalize(c.UserContext(), reqctx.ClientIP(c), netguard.OffenseInvalidSignature, uid)
+	}
+
+	c.Set(fiber.HeaderWWWAuthenticate, `DPoP error="invalid_dpop_proof"`)
+	if reason == ReasonMissing {
+		return apperr.ErrDeviceProofRequired
+	}
+	return apperr.ErrInvalidDeviceProof
+}
+
+func (v *Verifier) verify(c *fiber.Ctx, sessionID string, session *redis.UserSession, accessToken, path string) string {
+	header := c.Get(constants.HeaderDPoP)
+	if header == "" {
+		return ReasonMissing
+	}
+
+	key, err := base64.StdEncoding.DecodeString(session.DeviceKey)
+	if err != nil || len(key) != ed25519.PublicKeySize {
+		shared.GetLogger().ErrorMap("Session device key is not a valid Ed25519 key", map[string]any{"sid": sessionID})
+		return ReasonDeviceKey
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := v.parser.ParseWithClaims(header, claims, func(*jwt.Token) (any, error) {
+		return ed25519.PublicKey(key), nil
+	})
+	switch {
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid):
+		return ReasonSignature
+	case err != nil:
+		return ReasonMalformed
+	}
+	if typ, _ := token.Header["typ"].(string); typ != ProofType {
+		return ReasonMalformed
+	}
+
+	htm, _ := claims["htm"].(string)
+	htu, _ := claims["htu"].(string)
+	ath, _ := claims["ath"].(string)
+	jti, _ := claims["jti"].(string)
+	if jti == "" || len(jti) > maxNonceLength {
+		return ReasonMalformed
+	}
+
+	// htu porównujemy po ścieżce: TLS kończy się na ingressie, więc schemat i host
+	// widziane przez gateway nie muszą być tymi, które podpisał klient
+	u, err := url.Parse(htu)
+	if err != nil || htm != c.Method() || u.Path != path || ath != TokenHash(accessToken) {
+		return ReasonMismatch
+	}
+
+	iat, err := claims.GetIssuedAt()
+	if err != nil || iat == nil {
+		return ReasonMalformed
+	}
+	age := time.Since(iat.Time)
+	if age > v.maxAge || age < -clockSkew {
+		return ReasonExpired
+	}
+
+	fresh, err := v.cache.ClaimProofNonce(c.UserContext(), sessionID, jti, v.maxAge+clockSkew)
+	if err != nil {
+		// Bez Redis nie wykryjemy powtórzenia; sesja i tak jest w Redis, więc to rzadki przypadek
+		shared.GetLogger().WarnMap("Device proof nonce not stored", map[string]any{"sid": sessionID, "error": err.Error()})
+		return ResultOK
+	}
+	if !fresh {
+		return ReasonReplay
+	}
+	return ResultOK
+}
+
+// TokenHash – wartość ath: base64url(SHA-256(token dostępu))
+func TokenHash(accessToken string) string {
+	sum := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
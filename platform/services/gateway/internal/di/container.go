@@ -1,35 +1,219 @@
 package di
 
 import (
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"os"
+	"slices"
+	"strings"
 
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	"github.com/zerodayz7/platform/pkg/events"
+	pkgmiddleware "github.com/zerodayz7/platform/pkg/middleware"
+	docs "github.com/zerodayz7/platform/pkg/openapi"
 	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/server"
+	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/gateway/internal/admin"
+	"github.com/zerodayz7/platform/services/gateway/internal/canary"
+	"github.com/zerodayz7/platform/services/gateway/internal/deviceproof"
+	"github.com/zerodayz7/platform/services/gateway/internal/httpcache"
+	"github.com/zerodayz7/platform/services/gateway/internal/maintenance"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"github.com/zerodayz7/platform/services/gateway/internal/openapi"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"github.com/zerodayz7/platform/services/gateway/internal/stream"
+	"github.com/zerodayz7/platform/services/gateway/internal/upstream"
+	"github.com/zerodayz7/platform/services/gateway/internal/versioning"
+	"github.com/zerodayz7/platform/services/gateway/internal/websession"
 )
 
 type Container struct {
 	Redis          *redis.Client
 	Cache          *redis.Cache
 	HTTPClient     *http.Client
-	InternalSecret []byte
+	Upstreams      *upstream.Client
+	Routes         *routing.Store
+	Streams        *stream.Watcher
+	ResponseCache  *httpcache.Cache
+	OpenAPI        *openapi.Validator
+	Docs           *openapi.Aggregator
+	AppVersions    *versioning.AppPolicy
+	Canary         *canary.Tracker
+	Overrides      *admin.Overrides // zmiany operatorów z admin API (drain, breaker, maintenance)
+	Maintenance    *maintenance.Schedule
+	Audit          *events.AuditPublisher
+	Network        *netguard.Guard
+	Proxies        *pkgmiddleware.TrustedProxies
+	WebSessions    *websession.Manager   // nil – tryb przeglądarkowy wyłączony
+	DeviceProofs   *deviceproof.Verifier // nil – dowody urządzenia wyłączone
+	UpstreamTLS    *server.Certificates  // nil – mTLS wyłączone
+	InternalSigner *reqctx.Keyring
 	Config         *viper.Config
 }
 
-func NewContainer(redisClient *redis.Client, cfg *viper.Config) *Container {
+func NewContainer(redisClient *redis.Client, cfg *viper.Config) (*Container, error) {
 	cache := redis.NewCache(redisClient, cfg.Session.TTL)
 
+	services := namedServices(cfg)
+
+	routes, err := routing.NewStore(cfg.Routes.File, RouteCatalog(cfg, services))
+	if err != nil {
+		return nil, err
+	}
+
+	// Poza produkcją sprawdzamy też odpowiedzi – rozjazd kontraktu wychodzi przed wdrożeniem
+	specs, err := openapi.Load(cfg.Routes.OpenAPIDir, slices.Sorted(maps.Keys(services)), cfg.Server.Env != "production")
+	if err != nil {
+		return nil, err
+	}
+
+	transport := &http.Transport{
+		MaxIdleConns:        cfg.Proxy.MaxIdleConns,
+		MaxIdleConnsPerHost: cfg.Proxy.MaxIdleConnsPerHost,
+		IdleConnTimeout:     cfg.Proxy.IdleConnTimeout,
+	}
+	certs, err := upstreamTLS(transport, cfg, services)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bez globalnego Timeout – limit czasu ustawia dispatcher per trasa (routes.yaml)
+	httpClient := &http.Client{Transport: transport}
+
+	upstreams, err := upstream.NewClient(httpClient, cfg.Resilience, cfg.Services, services)
+	if err != nil {
+		return nil, err
+	}
+
+	// GET /openapi.json – dokumenty serwisów przefiltrowane przez tablicę tras
+	aggregator := openapi.NewAggregator(
+		openapi.ServiceFetcher(func(service string, req *http.Request) (*http.Response, error) {
+			return upstreams.Do(service, req, "", cfg.Proxy.RequestTimeout)
+		}),
+		slices.Sorted(maps.Keys(services)),
+		routes,
+		docs.Info{Title: cfg.Server.AppName, Version: cfg.Server.AppVersion},
+	)
+
+	// Minimalna wersja aplikacji (version-service) – egzekwowana przy forceUpdate
+	appVersions := versioning.NewAppPolicy(func(req *http.Request) (*http.Response, error) {
+		return upstreams.Do("version", req, "", cfg.Proxy.RequestTimeout)
+	})
+
+	// Nazwa konsumenta w grupie cache_purge_stream – unikalna per instancja
+	consumer, err := os.Hostname()
+	if err != nil {
+		consumer = cfg.Server.AppName
+	}
+
+	overrides := admin.NewOverrides(cache, upstreams, routes, cfg.Admin.SyncInterval)
+	audit := events.NewAuditPublisher(cache, cfg.Server.AppName)
+
+	network, err := netguard.NewGuard(cache, audit, cfg.Network, cfg.Reputation, cfg.Admin.AllowCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	proxies, err := pkgmiddleware.NewTrustedProxies(cfg.Server.TrustedProxies, cfg.Server.ProxyHeader)
+	if err != nil {
+		return nil, fmt.Errorf("TRUSTED_PROXIES: %w", err)
+	}
+
+	webSessions, err := websession.NewManager(cache, cfg.WebSession, cfg.JWT.AccessSecret)
+	if err != nil {
+		return nil, err
+	}
+	// Ciasteczka między originami wymagają CORS z credentials, a ten – jawnej listy originów
+	if webSessions != nil && strings.Contains(cfg.CORSAllow, "*") {
+		return nil, errors.New("WEB_SESSION_ENABLED requires explicit CORS_ALLOW_ORIGINS")
+	}
+
+	internalKeys, err := reqctx.NewKeyring(cfg.Internal.KeyID, []byte(cfg.Internal.HMACSecret), cfg.Internal.AcceptedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("INTERNAL_HMAC_ACCEPTED_KEYS: %w", err)
+	}
+
 	return &Container{
-		Redis: redisClient,
-		Cache: cache,
-		HTTPClient: &http.Client{
-			Timeout: cfg.Proxy.RequestTimeout,
-			Transport: &http.Transport{
-				MaxIdleConns:        cfg.Proxy.MaxIdleConns,
-				MaxIdleConnsPerHost: cfg.Proxy.MaxIdleConnsPerHost,
-				IdleConnTimeout:     cfg.Proxy.IdleConnTimeout,
-			},
-		},
-		InternalSecret: []byte(cfg.Internal.HMACSecret),
+		Redis:          redisClient,
+		Cache:          cache,
+		HTTPClient:     httpClient,
+		Upstreams:      upstreams,
+		Routes:         routes,
+		Streams:        stream.NewWatcher(cache, cfg.Session, cfg.Proxy.StreamSessionCheck),
+		ResponseCache:  httpcache.New(cache, redisClient, consumer),
+		OpenAPI:        specs,
+		Docs:           aggregator,
+		AppVersions:    appVersions,
+		Canary:         canary.NewTracker(),
+		Overrides:      overrides,
+		Maintenance:    maintenance.NewSchedule(routes, overrides, cache),
+		Audit:          audit,
+		Network:        network,
+		Proxies:        proxies,
+		WebSessions:    webSessions,
+		DeviceProofs:   deviceproof.NewVerifier(cache, network, cfg.DeviceProof),
+		UpstreamTLS:    certs,
+		InternalSigner: internalKeys,
 		Config:         cfg,
+	}, nil
+}
+
+// upstreamTLS włącza mTLS w ruchu do serwisów (MTLS_ENABLED). Serwisy z adresem http
+// (np. w trakcie wdrażania certyfikatów) działają dalej bez TLS – z ostrzeżeniem przy starcie.
+func upstreamTLS(transport *http.Transport, cfg *viper.Config, services map[string][]string) (*server.Certificates, error) {
+	if !cfg.MTLS.Enabled {
+		return nil, nil
+	}
+	pins, err := upstream.PinIdentities(cfg.MTLS.UpstreamIdentities, services)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := server.LoadCertificates(cfg.MTLS)
+	if err != nil {
+		return nil, err
+	}
+	upstream.UseMutualTLS(transport, certs, pins)
+
+	for name, urls := range services {
+		for _, raw := range urls {
+			if strings.HasPrefix(raw, "http://") {
+				shared.GetLogger().WarnMap("mTLS enabled, but upstream uses plain HTTP", map[string]any{
+					"upstream": name,
+					"url":      raw,
+				})
+			}
+		}
+	}
+	return certs, nil
+}
+
+// namedServices – serwisy, do których routes.yaml odwołuje się po nazwie
+func namedServices(cfg *viper.Config) map[string][]string {
+	return map[string][]string{
+		"auth":      cfg.Services.Auth,
+		"documents": cfg.Services.Documents,
+		"notify":    cfg.Services.Notify,
+		"users":     cfg.Services.Users,
+		"version":   cfg.Services.Version,
+	}
+}
+
+// RouteCatalog opisuje upstreamy, schematy i limity, do których może odwołać się plik tras
+func RouteCatalog(cfg *viper.Config, services map[string][]string) routing.Catalog {
+	return routing.Catalog{
+		Upstreams: services,
+		HasSchema: middleware.HasBodySchema,
+		HasLimiter: func(name string) bool {
+			_, _, ok := shared.LimitPreset(shared.LimitGroup(name))
+			return ok
+		},
+		DefaultTimeout:     cfg.Proxy.RequestTimeout,
+		DefaultBodyLimitMB: cfg.Server.BodyLimitMB,
+		GeoIP:              cfg.Network.GeoIPDatabase != "",
 	}
 }
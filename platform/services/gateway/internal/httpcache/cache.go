@@ -0,0 +1,216 @@
+package httpcache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/zerodayz7/platform/pkg/constants"
+	"github.com/zerodayz7/platform/pkg/redis"
+)
+
+// Zakresy wpisów: dane użytkownika nigdy nie trafiają do wspólnego zakresu
+const (
+	scopeUser   = "user:"
+	scopePublic = "public"
+)
+
+// Nagłówki obsługiwane przez sam gateway – nie różnicują wpisów
+var ignoredVary = []string{"Accept-Encoding", "Origin"}
+
+// Cache – cache odpowiedzi GET w Redis, włączany per trasa (routes.yaml: cache).
+// Wpisy są kluczowane zakresem (ID użytkownika albo "public"), ścieżką z query
+// oraz wartościami nagłówków z Vary; unieważniane tagami (Cache-Tag / cache.tags).
+type Cache struct {
+	store    *redis.Cache
+	client   *redis.Client
+	consumer string
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func New(store *redis.Cache, client *redis.Client, consumer string) *Cache {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Cache{
+		store:    store,
+		client:   client,
+		consumer: consumer,
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Key identyfikuje zasób w zakresie użytkownika (albo publicznym)
+type Key struct {
+	scope string
+	base  string
+}
+
+// UserKey – zasób prywatny użytkownika
+func UserKey(userID, path, query string) Key {
+	return newKey(scopeUser+userID, path, query)
+}
+
+// PublicKey – zasób wspólny dla wszystkich (zapisywany tylko przy Cache-Control: public)
+func PublicKey(path, query string) Key {
+	return newKey(scopePublic, path, query)
+}
+
+func newKey(scope, path, query string) Key {
+	return Key{scope: scope, base: hash(scope, path, query)}
+}
+
+// Shared – czy wpis jest widoczny dla wielu użytkowników
+func (k Key) Shared() bool {
+	return k.scope == scopePublic
+}
+
+func (k Key) variant(vary []string, header func(string) string) string {
+	parts := []string{k.base}
+	for _, h := range vary {
+		parts = append(parts, h, header(h))
+	}
+	return hash(parts...)
+}
+
+// Lookup zwraca wpis pasujący do żądania albo nil (miss)
+func (c *Cache) Lookup(ctx context.Context, key Key, header func(string) string) (*redis.CachedResponse, error) {
+	vary, ok, err := c.store.ResponseVary(ctx, key.base)
+	if err != nil || !ok {
+		return nil, err
+	}
+
+	resp, err := c.store.GetResponse(ctx, key.variant(vary, header))
+	if err == goredis.Nil {
+		return nil, nil
+	}
+	return resp, err
+}
+
+// Store zapisuje odpowiedź pod wariantem wynikającym z jej nagłówka Vary
+func (c *Cache) Store(
+	ctx context.Context,
+	key Key,
+	header func(string) string,
+	status int,
+	respHeader http.Header,
+	body []byte,
+	ttl time.Duration,
+	tags []string,
+) error {
+	vary := varyHeaders(respHeader)
+
+	scoped := make([]string, 0, len(tags)*2)
+	for _, tag := range tags {
+		scoped = append(scoped, allTag(tag))
+		if !key.Shared() {
+			scoped = append(scoped, key.scope+":"+tag)
+		}
+	}
+
+	return c.store.StoreResponse(ctx, key.base, key.variant(vary, header), vary, redis.CachedResponse{
+		Status:   status,
+		Header:   respHeader,
+		Body:     body,
+		StoredAt: time.Now().Unix(),
+	}, ttl, scoped)
+}
+
+// Purge unieważnia tagi użytkownika; pusty userID – wpisy wszystkich użytkowników
+func (c *Cache) Purge(ctx context.Context, userID string, tags ...string) (int, error) {
+	scoped := make([]string, len(tags))
+	for i, tag := range tags {
+		if userID == "" {
+			scoped[i] = allTag(tag)
+		} else {
+			scoped[i] = scopeUser + userID + ":" + tag
+		}
+	}
+	return c.store.PurgeResponseTags(ctx, scoped...)
+}
+
+// Policy decyduje na podstawie Cache-Control upstreamu, czy i jak długo trzymać
+// odpowiedź. maxTTL (z trasy) jest górnym limitem; wpis wspólny wymaga "public".
+func Policy(h http.Header, maxTTL time.Duration, shared bool) (time.Duration, bool) {
+	if h.Get("Set-Cookie") != "" || slices.Contains(varyHeaders(h), "*") {
+		return 0, false
+	}
+
+	directives := parseCacheControl(h.Get("Cache-Control"))
+	if _, ok := directives["no-store"]; ok {
+		return 0, false
+	}
+	if _, ok := directives["no-cache"]; ok {
+		return 0, false
+	}
+	if shared {
+		_, public := directives["public"]
+		_, private := directives["private"]
+		if !public || private {
+			return 0, false
+		}
+	}
+
+	ttl := maxTTL
+	for _, name := range []string{"s-maxage", "max-age"} {
+		if v, ok := directives[name]; ok {
+			secs, err := strconv.Atoi(v)
+			if err != nil || secs <= 0 {
+				return 0, false
+			}
+			ttl = min(ttl, time.Duration(secs)*time.Second)
+			break
+		}
+	}
+	return ttl, true
+}
+
+// Tags zwraca tagi z nagłówka Cache-Tag (po przecinku lub spacji)
+func Tags(h http.Header) []string {
+	var tags []string
+	for _, v := range h.Values(constants.HeaderCacheTag) {
+		tags = append(tags, strings.FieldsFunc(v, func(r rune) bool { return r == ',' || r == ' ' })...)
+	}
+	return tags
+}
+
+func varyHeaders(h http.Header) []string {
+	var vary []string
+	for _, v := range h.Values("Vary") {
+		for _, name := range strings.Split(v, ",") {
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			if name != "" && !slices.Contains(ignoredVary, name) && !slices.Contains(vary, name) {
+				vary = append(vary, name)
+			}
+		}
+	}
+	slices.Sort(vary)
+	return vary
+}
+
+func parseCacheControl(v string) map[string]string {
+	directives := make(map[string]string)
+	for _, part := range strings.Split(v, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(part), "=")
+		if name != "" {
+			directives[strings.ToLower(name)] = strings.Trim(value, `"`)
+		}
+	}
+	return directives
+}
+
+func allTag(tag string) string {
+	return "all:" + tag
+}
+
+func hash(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
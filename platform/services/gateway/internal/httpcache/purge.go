@@ -0,0 +1,86 @@
+package httpcache
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/constants"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+// StartPurgeConsumer przetwarza komunikaty z cache_purge_stream. Instancje gatewaya
+// dzielą grupę konsumentów – każdy komunikat obsługuje jedna z nich (Redis jest wspólny).
+func (c *Cache) StartPurgeConsumer() {
+	go func() {
+		log := shared.GetLogger()
+
+		for {
+			err := c.client.EnsureGroup(c.ctx, constants.StreamCachePurge, constants.GroupGatewayCache)
+			if err == nil {
+				break
+			}
+			log.ErrorObj("Cache purge: failed to create consumer group", err)
+			if !c.sleep(5 * time.Second) {
+				return
+			}
+		}
+
+		for c.ctx.Err() == nil {
+			entries, err := c.client.ReadStream(c.ctx, constants.StreamCachePurge, constants.GroupGatewayCache, c.consumer)
+			if err != nil {
+				if c.ctx.Err() != nil {
+					return
+				}
+				log.ErrorObj("Cache purge: stream read failed", err)
+				c.sleep(5 * time.Second)
+				continue
+			}
+
+			for _, entry := range entries {
+				c.handlePurge(entry.Values)
+				// Potwierdzamy także nieudane – wpisy i tak wygasną po TTL trasy
+				_ = c.client.AckStream(c.ctx, constants.StreamCachePurge, constants.GroupGatewayCache, entry.ID)
+			}
+		}
+	}()
+}
+
+func (c *Cache) handlePurge(values map[string]any) {
+	log := shared.GetLogger()
+
+	raw, ok := values["payload"].(string)
+	if !ok {
+		return
+	}
+	var msg events.CachePurge
+	if err := json.Unmarshal([]byte(raw), &msg); err != nil {
+		log.ErrorObj("Cache purge: invalid payload", err)
+		return
+	}
+
+	purged, err := c.Purge(c.ctx, msg.UserID, msg.Tags...)
+	if err != nil {
+		log.ErrorObj("Cache purge failed", err)
+		return
+	}
+	log.DebugMap("Cache purged", map[string]any{
+		"uid":    msg.UserID,
+		"tags":   msg.Tags,
+		"purged": purged,
+	})
+}
+
+// Close zatrzymuje konsumenta
+func (c *Cache) Close() {
+	c.cancel()
+}
+
+func (c *Cache) sleep(d time.Duration) bool {
+	select {
+	case <-c.ctx.Done():
+		return false
+	case <-time.After(d):
+		return true
+	}
+}
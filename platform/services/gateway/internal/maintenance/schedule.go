@@ -0,0 +1,149 @@
+package maintenance
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/admin"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// Skąd pochodzi okno serwisowe
+const (
+	SourceConfig = "config" // routes.yaml: maintenance
+	SourceAdmin  = "admin"  // admin API
+)
+
+const announceInterval = time.Minute
+
+// Window – okno serwisowe z pochodzeniem
+type Window struct {
+	*routing.MaintenanceWindow
+	Source string
+}
+
+// Schedule łączy okna z pliku tras i z admin API (okno z admin API zastępuje okno
+// z pliku o tym samym ID) i wysyła zapowiedzi okien do wszystkich użytkowników.
+type Schedule struct {
+	routes    *routing.Store
+	overrides *admin.Overrides
+	cache     *redis.Cache
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewSchedule(routes *routing.Store, overrides *admin.Overrides, cache *redis.Cache) *Schedule {
+	return &Schedule{routes: routes, overrides: overrides, cache: cache, stop: make(chan struct{})}
+}
+
+// Windows zwraca wszystkie okna w kolejności startu
+func (s *Schedule) Windows() []Window {
+	fromAdmin := s.overrides.Windows()
+	out := make([]Window, 0, len(fromAdmin))
+	for _, w := range fromAdmin {
+		out = append(out, Window{MaintenanceWindow: w, Source: SourceAdmin})
+	}
+	for _, w := range s.routes.Table().Maintenance() {
+		if !slices.ContainsFunc(fromAdmin, func(a *routing.MaintenanceWindow) bool { return a.ID == w.ID }) {
+			out = append(out, Window{MaintenanceWindow: w, Source: SourceConfig})
+		}
+	}
+	slices.SortFunc(out, func(a, b Window) int {
+		return cmp.Or(a.Start.Compare(b.Start), cmp.Compare(a.ID, b.ID))
+	})
+	return out
+}
+
+// Active zwraca trwające okno obejmujące trasę albo nil. Przy kilku nakładających się
+// oknach – to, które kończy się najpóźniej (Retry-After nie może obiecać za wcześnie).
+func (s *Schedule) Active(route *routing.Route, now time.Time) *routing.MaintenanceWindow {
+	var found *routing.MaintenanceWindow
+	for _, w := range s.Windows() {
+		if w.Active(now) && w.Covers(route) && (found == nil || w.End.After(found.End)) {
+			found = w.MaintenanceWindow
+		}
+	}
+	return found
+}
+
+// Start uruchamia wysyłanie zapowiedzi
+func (s *Schedule) Start() {
+	go func() {
+		ticker := time.NewTicker(announceInterval)
+		defer ticker.Stop()
+
+		for {
+			ctx, cancel := context.WithTimeout(context.Background(), announceInterval)
+			s.announce(ctx, time.Now())
+			cancel()
+
+			select {
+			case <-s.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close zatrzymuje wysyłanie zapowiedzi
+func (s *Schedule) Close() {
+	s.stopOnce.Do(func() { close(s.stop) })
+}
+
+// announce wysyła powiadomienie (broadcast) o oknach, dla których nadszedł czas zapowiedzi.
+// Okno zaplanowane później niż Announce przed startem jest ogłaszane od razu.
+func (s *Schedule) announce(ctx context.Context, now time.Time) {
+	log := shared.GetLogger()
+	for _, w := range s.Windows() {
+		if w.Announce == 0 || now.Before(w.Start.Add(-w.Announce)) || !now.Before(w.End) {
+			continue
+		}
+
+		claimed, err := s.cache.ClaimMaintenanceAnnouncement(ctx, fmt.Sprintf("%s:%d", w.ID, w.Start.Unix()), w.End.Sub(now))
+		if err != nil {
+			log.WarnMap("Maintenance announcement skipped: Redis unavailable", map[string]any{"window": w.ID, "error": err.Error()})
+			continue
+		}
+		if !claimed {
+			continue
+		}
+
+		if err := s.cache.SendNotification(ctx, announcement(w.MaintenanceWindow)); err != nil {
+			log.ErrorMap("Maintenance announcement not sent", map[string]any{"window": w.ID, "error": err.Error()})
+			continue
+		}
+		log.InfoMap("Maintenance window announced", map[string]any{
+			"window": w.ID,
+			"source": w.Source,
+			"start":  w.Start,
+			"end":    w.End,
+		})
+	}
+}
+
+// announcement – powiadomienie dla wszystkich użytkowników (notification_stream, broadcast)
+func announcement(w *routing.MaintenanceWindow) map[string]any {
+	const layout = "2006-01-02 15:04"
+	return map[string]any{
+		"broadcast": true,
+		"title":     "Planowane prace serwisowe",
+		"content": fmt.Sprintf("%s Przerwa: od %s do %s (UTC).",
+			w.Message[routing.DefaultMaintenanceLanguage], w.Start.UTC().Format(layout), w.End.UTC().Format(layout)),
+		"priority": "high",
+		"category": "system",
+		"metadata": map[string]any{
+			"window":   w.ID,
+			"start":    w.Start.UTC(),
+			"end":      w.End.UTC(),
+			"messages": w.Message,
+		},
+	}
+}
@@ -0,0 +1,151 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"github.com/zerodayz7/platform/services/gateway/internal/versioning"
+)
+
+const (
+	apiVersionKey = "apiVersion"
+	clientPathKey = "clientPath"
+	routeKey      = "route"
+)
+
+// APIVersion wybiera wersję API żądania i usuwa jej prefiks ze ścieżki, zanim ścieżkę
+// zobaczą JWT, sesja i dispatcher (trasy w routes.yaml są bez wersji).
+// Kolejność: prefiks /vN, nagłówek API-Version, wersja domyślna.
+// Sprawdza też politykę minimalnej wersji aplikacji (X-App-Version).
+// Trasa żądania jest ustalana tu, raz, z jednej wersji tablicy tras – JWT, sesja,
+// ContextBuilder i dispatcher czytają ją przez RouteOf.
+func APIVersion(routes *routing.Store, policy *versioning.AppPolicy) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		table := routes.Table()
+		versions := table.Versions()
+
+		var version *routing.APIVersion
+		if versions != nil {
+			var err error
+			if version, err = resolveVersion(c, versions); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+		}
+
+		route := table.Match(c.Method(), c.Path())
+		if route != nil {
+			c.Locals(routeKey, route)
+		}
+
+		// Zbyt stara aplikacja nadal pobiera politykę – stamtąd bierze adres aktualizacji
+		if c.Path() != versioning.PolicyPath && policy.RequiresUpdate(c.Get(constants.HeaderAppVersion)) {
+			versioning.RecordUpdateRequired()
+			return apperr.SendAppError(c, apperr.ErrAppUpdateRequired)
+		}
+		if version == nil {
+			return c.Next()
+		}
+
+		now := time.Now()
+		describeVersion(c, version)
+
+		outcome := versioning.OutcomeActive
+		switch {
+		case version.Retired(now):
+			outcome = versioning.OutcomeRetired
+		case !version.Deprecated.IsZero() && !now.Before(version.Deprecated):
+			outcome = versioning.OutcomeDeprecated
+		}
+		routePath := "unmatched"
+		if route != nil {
+			routePath = route.Path
+		}
+		versioning.RecordRequest(version.Name, routePath, outcome)
+
+		if outcome == versioning.OutcomeRetired {
+			return apperr.SendAppError(c, apperr.ErrAPIVersionSunset)
+		}
+
+		c.Locals(apiVersionKey, version)
+		return c.Next()
+	}
+}
+
+// ClientPath – ścieżka żądania w postaci wysłanej przez klienta (z prefiksem wersji,
+// który APIVersion usuwa); według niej klient podpisuje dowód urządzenia
+func ClientPath(c *fiber.Ctx) string {
+	if path, ok := c.Locals(clientPathKey).(string); ok {
+		return path
+	}
+	return c.Path()
+}
+
+// RouteOf zwraca trasę z routes.yaml ustaloną dla żądania przez APIVersion albo nil
+// (brak pasującej trasy albo aplikacja bez APIVersion, np. admin API)
+func RouteOf(c *fiber.Ctx) *routing.Route {
+	route, _ := c.Locals(routeKey).(*routing.Route)
+	return route
+}
+
+// APIVersionOf zwraca wersję API żądania albo nil (routes.yaml bez sekcji versions)
+func APIVersionOf(c *fiber.Ctx) *routing.APIVersion {
+	v, _ := c.Locals(apiVersionKey).(*routing.APIVersion)
+	return v
+}
+
+func resolveVersion(c *fiber.Ctx, versions *routing.Versions) (*routing.APIVersion, error) {
+	if name, rest, ok := routing.SplitVersion(c.Path()); ok {
+		version := versions.Lookup(name)
+		if version == nil {
+			return nil, apperr.ErrAPIVersionUnsupported
+		}
+		// Upstream dostaje ścieżkę bez prefiksu (OriginalURL), razem z query
+		uri := rest
+		if query := c.Request().URI().QueryString(); len(query) > 0 {
+			uri += "?" + string(query)
+		}
+		// Kopia – bez Immutable ciąg wskazuje na bufor nadpisywany przez SetRequestURI
+		c.Locals(clientPathKey, strings.Clone(c.Path()))
+		c.Request().Header.SetRequestURI(uri)
+		c.Path(rest)
+		return version, nil
+	}
+
+	if header := strings.TrimSpace(c.Get(constants.HeaderAPIVersion)); header != "" {
+		if !strings.HasPrefix(header, "v") {
+			header = "v" + header
+		}
+		version := versions.Lookup(header)
+		if version == nil {
+			return nil, apperr.ErrAPIVersionUnsupported
+		}
+		return version, nil
+	}
+
+	return versions.Default, nil
+}
+
+// describeVersion – nagłówki cyklu życia wersji (RFC 9745 Deprecation, RFC 8594 Sunset)
+func describeVersion(c *fiber.Ctx, version *routing.APIVersion) {
+	c.Set(constants.HeaderAPIVersion, version.Name)
+
+	if !version.Deprecated.IsZero() {
+		c.Set(constants.HeaderDeprecation, "@"+strconv.FormatInt(version.Deprecated.Unix(), 10))
+	}
+	if !version.Sunset.IsZero() {
+		c.Set(constants.HeaderSunset, version.Sunset.UTC().Format(http.TimeFormat))
+	}
+	if version.Link != "" && (!version.Deprecated.IsZero() || !version.Sunset.IsZero()) {
+		rel := "deprecation"
+		if version.Deprecated.IsZero() {
+			rel = "sunset"
+		}
+		c.Set(fiber.HeaderLink, "<"+version.Link+`>; rel="`+rel+`"`)
+	}
+}
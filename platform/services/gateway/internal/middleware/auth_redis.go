@@ -1,29 +1,32 @@
 package middleware
 
 import (
-	"encoding/json"
 	"errors"
-	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	jwt "github.com/golang-jwt/jwt/v5"
-	"github.com/redis/go-redis/v9"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/zerodayz7/platform/pkg/constants"
 	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/gateway/internal/deviceproof"
+	"github.com/zerodayz7/platform/services/gateway/internal/websession"
 )
 
-type UserSession struct {
-	UserID      string `json:"user_id"`
-	Fingerprint string `json:"fingerprint"`
-}
-
-func AuthRedisMiddleware(rdb *redis.Client) fiber.Handler {
+// AuthRedisMiddleware weryfikuje sesję w Redis i przy każdym żądaniu przesuwa okno
+// bezczynności (nie dalej niż absolutny czas życia sesji). Sesję wskazuje claim "sid"
+// tokenu albo – w trybie przeglądarkowym (web != nil) – ciasteczko sesji z tokenem CSRF.
+// Sesja powiązana z kluczem urządzenia wymaga przy tokenie bearer dowodu DPoP (proofs != nil),
+// a ciasteczko przyjmujemy tylko dla sesji przeglądarkowych bez klucza urządzenia – inaczej
+// skradziony token (claim "sid") w ciasteczku omijałby dowód.
+func AuthRedisMiddleware(cache *redis.Cache, cfg viper.SessionConfig, public PublicMatcher, web *websession.Manager, proofs *deviceproof.Verifier) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		log := shared.GetLogger()
 		path := c.Path()
 
-		if slices.Contains(constants.PublicPaths, path) {
+		if public.IsPublic(c) {
 			return c.Next()
 		}
 
@@ -33,32 +36,42 @@ func AuthRedisMiddleware(rdb *redis.Client) fiber.Handler {
 			return apperr.SendAppError(c, apperr.ErrInvalidDeviceFingerprint)
 		}
 
-		jwtPayload := c.Locals("user")
-		if jwtPayload == nil {
+		var sessionID string
+		jwtToken, bearer := c.Locals("user").(*jwt.Token)
+		if bearer {
+			claims := jwtToken.Claims.(jwt.MapClaims)
+			sessionID, _ = claims["sid"].(string)
+		} else if web != nil && web.SessionID(c) != "" {
+			sessionID = web.SessionID(c)
+			if !web.VerifyCSRF(c, sessionID) {
+				log.WarnMap("CSRF token invalid", map[string]any{"path": path, "method": c.Method()})
+				return apperr.SendAppError(c, apperr.ErrCSRFInvalid)
+			}
+			c.Locals(websession.LocalCookieAuth, true)
+		} else {
 			return apperr.SendAppError(c, apperr.ErrUnauthorized)
 		}
-		jwtToken := jwtPayload.(*jwt.Token)
-		claims := jwtToken.Claims.(jwt.MapClaims)
-		sessionID, _ := claims["sid"].(string)
 
-		redisPrefix := "session:"
+		ctx := c.Context()
+
+		var session *redis.UserSession
+		var err error
 		if path == "/auth/register-device" {
-			redisPrefix = "setup:session:"
+			// Sesja setup ma własny, krótki TTL – nie przesuwamy jej
+			session, err = cache.GetSetupSession(ctx, sessionID)
+		} else {
+			session, err = cache.TouchSession(ctx, sessionID, cfg.TTL, cfg.AbsoluteTTL)
 		}
-
-		ctx := c.Context()
-		jsonData, err := rdb.Get(ctx, redisPrefix+sessionID).Result()
 		if err != nil {
-			if errors.Is(err, redis.Nil) {
-
+			if errors.Is(err, goredis.Nil) {
 				log.WarnMap("Session not found", map[string]any{"sid": sessionID, "path": path})
 				return apperr.SendAppError(c, apperr.ErrSessionExpired)
 			}
-			return apperr.SendAppError(c, apperr.ErrInternal)
-		}
-
-		var session UserSession
-		if err := json.Unmarshal([]byte(jsonData), &session); err != nil {
+			if errors.Is(err, redis.ErrSessionLifetimeExceeded) {
+				log.InfoMap("Session absolute lifetime exceeded", map[string]any{"sid": sessionID})
+				return apperr.SendAppError(c, apperr.ErrSessionExpired)
+			}
+			log.ErrorObj("Session lookup failed", err)
 			return apperr.SendAppError(c, apperr.ErrInternal)
 		}
 
@@ -71,9 +84,21 @@ func AuthRedisMiddleware(rdb *redis.Client) fiber.Handler {
 			return apperr.SendAppError(c, apperr.ErrUntrustedDevice)
 		}
 
+		if !bearer && (!session.Web || session.DeviceKey != "") {
+			log.WarnMap("Session cookie used for a non-web session", map[string]any{"sid": sessionID, "path": path})
+			return apperr.SendAppError(c, apperr.ErrUnauthorized)
+		}
+
+		if proofs != nil && bearer && session.DeviceKey != "" {
+			if err := proofs.Check(c, sessionID, session, jwtToken.Raw, ClientPath(c)); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+		}
+
 		c.Locals("userID", session.UserID)
 		c.Locals("sessionID", sessionID)
 		c.Locals("deviceID", session.Fingerprint)
+		c.Locals("roles", session.Roles)
 
 		return c.Next()
 	}
@@ -2,29 +2,31 @@ package middleware
 
 import (
 	"fmt"
-	"slices"
 
 	"github.com/gofiber/fiber/v2"
 	jwt "github.com/golang-jwt/jwt/v5"
 	"github.com/google/uuid"
 	"github.com/zerodayz7/platform/pkg/constants"
 	"github.com/zerodayz7/platform/pkg/context"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"github.com/zerodayz7/platform/services/gateway/internal/websession"
 )
 
-func ContextBuilder() fiber.Handler {
+func ContextBuilder(public PublicMatcher) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		path := c.Path()
-
 		// 1. Podstawowe dane dostępne dla KAŻDEGO requestu (nawet publicznego)
 		ctx := &context.RequestContext{
 			// Używamy Locals("requestid"), bo fiber middleware 'requestid' tam go wrzuca
 			RequestID: fmt.Sprintf("%v", c.Locals("requestid")),
-			IP:        c.IP(),
+			IP:        context.ClientIP(c),
 			DeviceID:  c.Get(constants.HeaderDeviceFingerprint),
 		}
+		// Decyzja netguard (kraj, punkty reputacji adresu)
+		ctx.Country, _ = c.Locals(netguard.LocalCountry).(string)
+		ctx.RiskScore, _ = c.Locals(netguard.LocalRiskScore).(int)
 
 		// 2. Jeśli ścieżka jest publiczna, pomijamy wyciąganie danych usera
-		if slices.Contains(constants.PublicPaths, path) {
+		if public.IsPublic(c) {
 			c.Locals("requestContext", ctx)
 			return c.Next()
 		}
@@ -46,8 +48,10 @@ func ContextBuilder() fiber.Handler {
 					ctx.SessionID = sid
 				}
 
-				// Pobieranie Ról
-				if roles, ok := claims["roles"].([]any); ok {
+				// Pobieranie Ról – sesja w Redis (AuthRedisMiddleware) ma pierwszeństwo przed JWT
+				if roles, ok := c.Locals("roles").([]string); ok && len(roles) > 0 {
+					ctx.Roles = roles
+				} else if roles, ok := claims["roles"].([]any); ok {
 					for _, r := range roles {
 						if roleStr, ok := r.(string); ok {
 							ctx.Roles = append(ctx.Roles, roleStr)
@@ -55,6 +59,13 @@ func ContextBuilder() fiber.Handler {
 					}
 				}
 			}
+		} else if cookieAuth, _ := c.Locals(websession.LocalCookieAuth).(bool); cookieAuth {
+			// Sesja z ciasteczka portalu – bez tokenu wszystko pochodzi z sesji w Redis
+			if uid, err := uuid.Parse(fmt.Sprint(c.Locals("userID"))); err == nil {
+				ctx.UserID = &uid
+			}
+			ctx.SessionID, _ = c.Locals("sessionID").(string)
+			ctx.Roles, _ = c.Locals("roles").([]string)
 		}
 
 		// 4. Zapisujemy gotowy obiekt w Locals
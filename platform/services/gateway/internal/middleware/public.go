@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Ścieżki obsługiwane natywnie przez gateway (poza plikiem tras)
+var nativePublicPaths = []string{"/health", "/favicon.ico"}
+
+// PublicMatcher rozstrzyga, czy żądanie trafia w trasę publiczną (bez JWT i sesji).
+type PublicMatcher interface {
+	IsPublic(c *fiber.Ctx) bool
+}
+
+// PublicRoutes – trasy publiczne z routes.yaml (źródłem prawdy jest plik tras; trasę
+// żądania ustala APIVersion) oraz własne endpointy gatewaya spoza tablicy tras
+// (np. "GET /openapi.json"), dostępne bez JWT i sesji
+func PublicRoutes(endpoints ...string) PublicMatcher {
+	set := make(map[string]bool, len(endpoints))
+	for _, e := range endpoints {
+		set[e] = true
+	}
+	return publicRoutes{endpoints: set}
+}
+
+type publicRoutes struct {
+	endpoints map[string]bool
+}
+
+func (p publicRoutes) IsPublic(c *fiber.Ctx) bool {
+	if slices.Contains(nativePublicPaths, c.Path()) || p.endpoints[c.Method()+" "+c.Path()] {
+		return true
+	}
+	route := RouteOf(c)
+	return route != nil && route.Public()
+}
@@ -0,0 +1,37 @@
+package middleware
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/schemas"
+)
+
+// bodySchemas mapuje nazwy używane w routes.yaml (pole "schema") na walidatory body
+var bodySchemas = map[string]func(*fiber.Ctx) error{
+	"LoginRequest":                   BindBody[schemas.LoginRequest],
+	"RegisterRequest":                BindBody[schemas.RegisterRequest],
+	"VerifyDeviceRequest":            BindBody[schemas.VerifyDeviceRequest],
+	"TwoFARequest":                   BindBody[schemas.TwoFARequest],
+	"TwoFARecoverRequest":            BindBody[schemas.TwoFARecoverRequest],
+	"RegenerateRecoveryCodesRequest": BindBody[schemas.RegenerateRecoveryCodesRequest],
+	"RefreshTokenRequest":            BindBody[schemas.RefreshTokenRequest],
+	"LogoutRequest":                  BindBody[schemas.LogoutRequest],
+	"ResetPasswordRequest":           BindBody[schemas.ResetPasswordRequest],
+	"ResetCodeVerifyRequest":         BindBody[schemas.ResetCodeVerifyRequest],
+	"ResetPasswordFinalRequest":      BindBody[schemas.ResetPasswordFinalRequest],
+	"RegisterDeviceRequest":          BindBody[schemas.RegisterDeviceRequest],
+	"FinalizeResetRequest":           BindBody[schemas.FinalizeResetRequest],
+	"AdminChangeStatusRequest":       BindBody[schemas.AdminChangeStatusRequest],
+	"AdminActionRequest":             BindBody[schemas.AdminActionRequest],
+}
+
+// BodySchema zwraca walidator body o podanej nazwie
+func BodySchema(name string) (func(*fiber.Ctx) error, bool) {
+	bind, ok := bodySchemas[name]
+	return bind, ok
+}
+
+// HasBodySchema – używane przy walidacji pliku tras
+func HasBodySchema(name string) bool {
+	_, ok := bodySchemas[name]
+	return ok
+}
@@ -8,23 +8,32 @@ import (
 
 func ValidateBody[T any]() fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var body T
-		if err := c.BodyParser(&body); err != nil {
-			return errors.SendAppError(c, errors.ErrInvalidJSON)
+		if err := BindBody[T](c); err != nil {
+			return errors.SendAppError(c, err)
 		}
+		return c.Next()
+	}
+}
 
-		if errs := validator.Validate(body); len(errs) > 0 {
-			meta := make(map[string]any)
-			for k, v := range errs {
-				meta[k] = v
-			}
+// BindBody parsuje i waliduje body, zapisując wynik w Locals("validatedBody").
+// Zwraca *AppError – używane bezpośrednio przez dispatcher tras z pliku konfiguracyjnego.
+func BindBody[T any](c *fiber.Ctx) error {
+	var body T
+	if err := c.BodyParser(&body); err != nil {
+		return errors.ErrInvalidJSON
+	}
 
-			appErr := errors.ErrValidationFailed
-			appErr.Meta = meta
-			return errors.SendAppError(c, appErr)
+	if errs := validator.Validate(body); len(errs) > 0 {
+		meta := make(map[string]any)
+		for k, v := range errs {
+			meta[k] = v
 		}
 
-		c.Locals("validatedBody", body)
-		return c.Next()
+		appErr := *errors.ErrValidationFailed
+		appErr.Meta = meta
+		return &appErr
 	}
+
+	c.Locals("validatedBody", body)
+	return nil
 }
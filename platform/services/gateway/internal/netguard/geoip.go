@@ -0,0 +1,45 @@
+package netguard
+
+import (
+	"fmt"
+	"net/netip"
+
+	"github.com/oschwald/maxminddb-golang"
+)
+
+// GeoDB – baza krajów w formacie MaxMind DB (GeoLite2-Country, GeoIP2-Country i zgodne).
+// Odczyt jest bezpieczny współbieżnie.
+type GeoDB struct {
+	reader *maxminddb.Reader
+}
+
+// countryRecord – pola rekordu potrzebne do reguł krajów
+type countryRecord struct {
+	Country struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"country"`
+	RegisteredCountry struct {
+		ISOCode string `maxminddb:"iso_code"`
+	} `maxminddb:"registered_country"`
+}
+
+// OpenGeoDB otwiera plik MMDB
+func OpenGeoDB(path string) (*GeoDB, error) {
+	reader, err := maxminddb.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open geoip database: %w", err)
+	}
+	return &GeoDB{reader: reader}, nil
+}
+
+// Country zwraca kod ISO 3166-1 alpha-2 kraju adresu ("" – adres spoza bazy)
+func (db *GeoDB) Country(addr netip.Addr) string {
+	var rec countryRecord
+	if err := db.reader.Lookup(addr.Unmap().AsSlice(), &rec); err != nil {
+		return ""
+	}
+	if rec.Country.ISOCode != "" {
+		return rec.Country.ISOCode
+	}
+	return rec.RegisteredCountry.ISOCode
+}
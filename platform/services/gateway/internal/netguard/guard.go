@@ -0,0 +1,261 @@
+package netguard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"go.uber.org/zap"
+)
+
+// Powody odrzucenia żądania (log żądania, metryki, audyt)
+const (
+	ReasonDenyList   = "deny_list"
+	ReasonCountry    = "country"
+	ReasonBanned     = "banned"
+	ReasonNotAllowed = "not_allowed" // spoza listy dozwolonych trasy / admin API
+)
+
+// Przewinienia zwiększające punkty reputacji adresu
+const (
+	OffenseFailedLogin      = "failed_login"
+	OffenseInvalidSignature = "invalid_signature"
+)
+
+// Klucze Locals z decyzją – ContextBuilder przenosi je do RequestContext
+const (
+	LocalCountry   = "country"
+	LocalRiskScore = "riskScore"
+)
+
+// AuditNetworkDenied – odmowa dostępu do trasy z listą dozwolonych sieci / krajów
+const AuditNetworkDenied = "NETWORK_ACCESS_DENIED"
+
+// Guard filtruje ruch po adresie klienta: globalna lista zablokowanych sieci, kraje
+// (baza MMDB) i reputacja IP w Redis z tymczasowymi blokadami.
+type Guard struct {
+	deny          []netip.Prefix
+	admin         []netip.Prefix
+	geo           *GeoDB
+	denyCountries []string
+	cache         *redis.Cache
+	audit         *events.AuditPublisher
+	reputation    viper.ReputationConfig
+}
+
+func NewGuard(cache *redis.Cache, audit *events.AuditPublisher, network viper.NetworkConfig, reputation viper.ReputationConfig, adminAllow []string) (*Guard, error) {
+	deny, err := ParsePrefixes(network.DenyCIDRs)
+	if err != nil {
+		return nil, fmt.Errorf("NETWORK_DENY_CIDRS: %w", err)
+	}
+	admin, err := ParsePrefixes(adminAllow)
+	if err != nil {
+		return nil, fmt.Errorf("ADMIN_ALLOW_CIDRS: %w", err)
+	}
+	countries, err := ParseCountries(network.DenyCountries)
+	if err != nil {
+		return nil, fmt.Errorf("GEOIP_DENY_COUNTRIES: %w", err)
+	}
+
+	g := &Guard{deny: deny, admin: admin, denyCountries: countries, cache: cache, audit: audit, reputation: reputation}
+	if network.GeoIPDatabase != "" {
+		if g.geo, err = OpenGeoDB(network.GeoIPDatabase); err != nil {
+			return nil, err
+		}
+	} else if len(countries) > 0 {
+		return nil, errors.New("GEOIP_DENY_COUNTRIES requires GEOIP_DATABASE")
+	}
+	return g, nil
+}
+
+// GeoIP – czy reguły krajów są dostępne (wczytana baza MMDB)
+func (g *Guard) GeoIP() bool {
+	return g.geo != nil
+}
+
+// Country zwraca kraj adresu ("" bez bazy albo dla adresu spoza niej, np. sieci prywatnej)
+func (g *Guard) Country(addr netip.Addr) string {
+	if g.geo == nil {
+		return ""
+	}
+	return g.geo.Country(addr)
+}
+
+// Middleware odrzuca żądania z zablokowanych sieci, krajów i adresów z aktywną blokadą.
+// Kraj i punkty reputacji trafiają do RequestContext. Przy awarii Redis reputacja jest
+// pomijana (listy i kraje działają dalej).
+func (g *Guard) Middleware() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		addr, ok := ClientAddr(c)
+		if !ok {
+			return c.Next()
+		}
+
+		if Contains(g.deny, addr) {
+			return Reject(c, ReasonDenyList, apperr.ErrIPBlocked)
+		}
+
+		country := g.Country(addr)
+		if country != "" && slices.Contains(g.denyCountries, country) {
+			return Reject(c, ReasonCountry, apperr.ErrGeoBlocked)
+		}
+		c.Locals(LocalCountry, country)
+
+		if g.reputation.Enabled {
+			score, ban, err := g.cache.IPStatus(c.UserContext(), addr.String())
+			switch {
+			case err != nil:
+				shared.GetLogger().WarnMap("IP reputation unavailable", map[string]any{"error": err.Error()})
+			case ban > 0:
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(ban.Seconds())+1))
+				return Reject(c, ReasonBanned, apperr.ErrIPBanned)
+			default:
+				c.Locals(LocalRiskScore, int(score))
+			}
+		}
+		return c.Next()
+	}
+}
+
+// AdminOnly – admin API tylko z sieci ADMIN_ALLOW_CIDRS (pusta lista = bez ograniczeń)
+func (g *Guard) AdminOnly() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if len(g.admin) == 0 {
+			return c.Next()
+		}
+		if addr, ok := ClientAddr(c); ok && Contains(g.admin, addr) {
+			return c.Next()
+		}
+		return Reject(c, ReasonNotAllowed, apperr.ErrIPBlocked)
+	}
+}
+
+// Reject kończy żądanie odmową sieciową (powód w logu żądania i metrykach)
+func Reject(c *fiber.Ctx, reason string, err *apperr.AppError) error {
+	RecordBlocked(reason)
+	shared.AddLogField(c, zap.String("network_block", reason))
+	return apperr.SendAppError(c, err)
+}
+
+// Penalize dolicza adresowi punkty za przewinienie; po przekroczeniu progu adres jest
+// blokowany na REPUTATION_BAN_DURATION, a blokada trafia do audytu
+func (g *Guard) Penalize(ctx context.Context, ip, offense string, userID *uuid.UUID) {
+	var points int
+	switch offense {
+	case OffenseFailedLogin:
+		points = g.reputation.FailedLoginPoints
+	case OffenseInvalidSignature:
+		points = g.reputation.InvalidSignaturePoints
+	}
+	if !g.reputation.Enabled || points == 0 || ip == "" {
+		return
+	}
+
+	score, banned, err := g.cache.PenalizeIP(ctx, ip, int64(points), redis.ReputationPolicy{
+		Window:       g.reputation.Window,
+		BanThreshold: int64(g.reputation.BanThreshold),
+		BanDuration:  g.reputation.BanDuration,
+	})
+	if err != nil {
+		shared.GetLogger().WarnMap("IP reputation not updated", map[string]any{"offense": offense, "error": err.Error()})
+		return
+	}
+	if !banned {
+		return
+	}
+
+	RecordBan(offense)
+	meta := map[string]any{
+		"offense":  offense,
+		"score":    score,
+		"duration": g.reputation.BanDuration.String(),
+	}
+	shared.GetLogger().WarnMap("IP temporarily banned", map[string]any{"ip": ip, "offense": offense, "score": score})
+	g.record(ctx, events.AuditIPBanned, userID, ip, meta)
+}
+
+// RecordDenied zapisuje w audycie odmowę dostępu do trasy z listą dozwolonych sieci / krajów
+func (g *Guard) RecordDenied(ctx context.Context, userID *uuid.UUID, ip string, meta map[string]any) {
+	g.record(ctx, AuditNetworkDenied, userID, ip, meta)
+}
+
+func (g *Guard) record(ctx context.Context, action string, userID *uuid.UUID, ip string, meta map[string]any) {
+	uid := uuid.Nil
+	if userID != nil {
+		uid = *userID
+	}
+	if err := g.audit.Record(ctx, uid.String(), action, ip, meta); err != nil {
+		shared.GetLogger().ErrorMap("Failed to write network audit record", map[string]any{
+			"action": action,
+			"err":    err.Error(),
+		})
+	}
+}
+
+// ClientAddr – adres klienta żądania
+func ClientAddr(c *fiber.Ctx) (netip.Addr, bool) {
+	addr, err := netip.ParseAddr(reqctx.ClientIP(c))
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	return addr.Unmap(), true
+}
+
+// ParsePrefixes przyjmuje sieci CIDR i pojedyncze adresy (jako /32 albo /128)
+func ParsePrefixes(values []string) ([]netip.Prefix, error) {
+	out := make([]netip.Prefix, 0, len(values))
+	for _, v := range values {
+		v = strings.TrimSpace(v)
+		if v == "" {
+			continue
+		}
+		if !strings.Contains(v, "/") {
+			addr, err := netip.ParseAddr(v)
+			if err != nil {
+				return nil, fmt.Errorf("invalid address or CIDR %q", v)
+			}
+			addr = addr.Unmap()
+			out = append(out, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		p, err := netip.ParsePrefix(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid address or CIDR %q", v)
+		}
+		out = append(out, p.Masked())
+	}
+	return out, nil
+}
+
+// ParseCountries normalizuje kody ISO 3166-1 alpha-2 (pl -> PL)
+func ParseCountries(values []string) ([]string, error) {
+	out := make([]string, 0, len(values))
+	for _, v := range values {
+		v = strings.ToUpper(strings.TrimSpace(v))
+		if v == "" {
+			continue
+		}
+		if len(v) != 2 || v[0] < 'A' || v[0] > 'Z' || v[1] < 'A' || v[1] > 'Z' {
+			return nil, fmt.Errorf("invalid country code %q (expected ISO 3166-1 alpha-2)", v)
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+// Contains – czy adres należy do którejś z sieci
+func Contains(prefixes []netip.Prefix, addr netip.Addr) bool {
+	return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+}
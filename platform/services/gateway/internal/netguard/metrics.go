@@ -0,0 +1,27 @@
+package netguard
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metryki OTEL – eksportowane, gdy w procesie zarejestrowany jest MeterProvider
+var (
+	meter = otel.Meter("github.com/zerodayz7/platform/services/gateway/netguard")
+
+	blocked, _ = meter.Int64Counter("gateway.network.blocked", metric.WithDescription("Żądania odrzucone przez filtr sieci (reason: deny_list, country, banned, not_allowed)"))
+	bans, _    = meter.Int64Counter("gateway.network.bans", metric.WithDescription("Tymczasowe blokady adresów IP założone przez gateway"))
+)
+
+// RecordBlocked zlicza odrzucone żądanie
+func RecordBlocked(reason string) {
+	blocked.Add(context.Background(), 1, metric.WithAttributes(attribute.String("reason", reason)))
+}
+
+// RecordBan zlicza blokadę adresu
+func RecordBan(offense string) {
+	bans.Add(context.Background(), 1, metric.WithAttributes(attribute.String("offense", offense)))
+}
@@ -0,0 +1,247 @@
+package openapi
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/constants"
+	docs "github.com/zerodayz7/platform/pkg/openapi"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+const (
+	// Dokumenty serwisów są pobierane ponownie najwyżej raz na docsRefresh
+	docsRefresh = time.Minute
+	// Większy dokument serwisu jest odrzucany
+	maxDocumentSize = 4 << 20
+	// Jak w walidacji nagłówka w dispatcherze
+	maxIdempotencyKeyLen = 255
+)
+
+const bearerScheme = "bearerAuth"
+
+// Fetcher pobiera GET /openapi.json serwisu (nazwa z konfiguracji gatewaya)
+type Fetcher func(ctx context.Context, service string) ([]byte, error)
+
+// Aggregator składa publiczny dokument gatewaya z dokumentów serwisów. Zostają tylko
+// operacje, które gateway faktycznie kieruje do danego serwisu (routes.yaml), z opisem
+// dostępu trasy: secure = Bearer JWT (+ role), public = bez uwierzytelnienia.
+type Aggregator struct {
+	fetch    Fetcher
+	services []string
+	routes   *routing.Store
+	info     docs.Info
+
+	mu        sync.Mutex
+	raw       map[string][]byte // ostatni poprawny dokument serwisu
+	fetchedAt time.Time
+	built     []byte
+	builtFor  *routing.Table
+}
+
+func NewAggregator(fetch Fetcher, services []string, routes *routing.Store, info docs.Info) *Aggregator {
+	return &Aggregator{
+		fetch:    fetch,
+		services: services,
+		routes:   routes,
+		info:     info,
+		raw:      make(map[string][]byte),
+	}
+}
+
+// Document zwraca zserializowany dokument. Przebudowa następuje po przeładowaniu tablicy
+// tras albo po docsRefresh; serwis niedostępny przy odświeżeniu zostaje z poprzednią wersją.
+func (a *Aggregator) Document(ctx context.Context) ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	table := a.routes.Table()
+	stale := time.Since(a.fetchedAt) > docsRefresh
+	if a.built != nil && a.builtFor == table && !stale {
+		return a.built, nil
+	}
+
+	if stale {
+		a.refresh(ctx)
+	}
+
+	doc := a.merge(table)
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	a.built, a.builtFor = out, table
+	return out, nil
+}
+
+func (a *Aggregator) refresh(ctx context.Context) {
+	for _, service := range a.services {
+		body, err := a.fetch(ctx, service)
+		if err == nil && body == nil {
+			// Serwis nie wystawia dokumentu (np. version-service)
+			continue
+		}
+		if err == nil && !json.Valid(body) {
+			err = errors.New("invalid JSON")
+		}
+		if err != nil {
+			shared.GetLogger().WarnMap("OpenAPI document unavailable", map[string]any{
+				"service": service,
+				"error":   err.Error(),
+				"cached":  a.raw[service] != nil,
+			})
+			continue
+		}
+		a.raw[service] = body
+	}
+	a.fetchedAt = time.Now()
+}
+
+func (a *Aggregator) merge(table *routing.Table) *docs.Document {
+	merged := &docs.Document{
+		OpenAPI: docs.Version,
+		Info:    a.info,
+		Paths:   make(map[string]docs.PathItem),
+		Components: &docs.Components{
+			Schemas: make(map[string]*docs.Schema),
+			SecuritySchemes: map[string]*docs.SecurityScheme{
+				bearerScheme: {
+					Type:         "http",
+					Scheme:       "bearer",
+					BearerFormat: "JWT",
+					Description:  "Access token of an active session",
+				},
+			},
+		},
+	}
+
+	for _, service := range a.services {
+		raw, ok := a.raw[service]
+		if !ok {
+			continue
+		}
+		// Świeża kopia przy każdym składaniu – operacje są modyfikowane poniżej
+		var doc docs.Document
+		if err := json.Unmarshal(raw, &doc); err != nil {
+			continue
+		}
+		renames := mergeSchemas(merged, &doc, service)
+
+		for path, item := range doc.Paths {
+			for method, op := range item {
+				// Szablon ścieżki ({id}) pasuje do wzorca trasy (:id, *) jak zwykły segment
+				route := table.Match(strings.ToUpper(method), path)
+				if route == nil || route.Target != service {
+					continue
+				}
+				describeRoute(op, route)
+				renameRefs(op, renames)
+
+				if merged.Paths[path] == nil {
+					merged.Paths[path] = make(docs.PathItem)
+				}
+				merged.Paths[path][method] = op
+			}
+		}
+	}
+	return merged
+}
+
+// mergeSchemas kopiuje schematy serwisu. Ta sama nazwa o innej treści (np. różne wersje
+// pkg/schemas w trakcie wdrożenia) dostaje prefiks serwisu; zwraca mapę zmian nazw.
+func mergeSchemas(merged, doc *docs.Document, service string) map[string]string {
+	if doc.Components == nil {
+		return nil
+	}
+	renames := make(map[string]string)
+	for name, schema := range doc.Components.Schemas {
+		existing, ok := merged.Components.Schemas[name]
+		if ok && !reflect.DeepEqual(existing, schema) {
+			renames[name] = service + "." + name
+			name = renames[name]
+		}
+		merged.Components.Schemas[name] = schema
+	}
+	return renames
+}
+
+func renameRefs(op *docs.Operation, renames map[string]string) {
+	if len(renames) == 0 {
+		return
+	}
+	rename := func(content map[string]docs.MediaType) {
+		for _, media := range content {
+			if media.Schema == nil {
+				continue
+			}
+			name, ok := strings.CutPrefix(media.Schema.Ref, docs.SchemaRefPrefix)
+			if renamed, found := renames[name]; ok && found {
+				media.Schema.Ref = docs.SchemaRefPrefix + renamed
+			}
+		}
+	}
+	if op.RequestBody != nil {
+		rename(op.RequestBody.Content)
+	}
+	for _, resp := range op.Responses {
+		rename(resp.Content)
+	}
+}
+
+// describeRoute uzupełnia operację o to, co dokłada gateway: dostęp, role, strumień, Idempotency-Key
+func describeRoute(op *docs.Operation, route *routing.Route) {
+	if route.Public() {
+		op.Security = nil
+	} else {
+		op.Security = []map[string][]string{{bearerScheme: {}}}
+		op.Roles = slices.Clone(route.Roles)
+	}
+
+	if route.Stream {
+		op.Description = strings.TrimSpace(op.Description + "\n\nLong-lived connection (WebSocket / SSE), closed when the session ends.")
+	}
+
+	if route.Idempotency != nil {
+		maxLen := maxIdempotencyKeyLen
+		op.Parameters = append(op.Parameters, &docs.Parameter{
+			Name:        constants.HeaderIdempotencyKey,
+			In:          docs.InHeader,
+			Description: "A retry with the same key replays the stored response",
+			Required:    route.Idempotency.Required,
+			Schema:      &docs.Schema{Type: "string", MaxLength: &maxLen},
+		})
+	}
+}
+
+// ServiceFetcher – Fetcher dla funkcji wysyłającej żądanie do upstreamu (np. upstream.Client.Do).
+// 404 = serwis bez dokumentu (nil, nil).
+func ServiceFetcher(do func(service string, req *http.Request) (*http.Response, error)) Fetcher {
+	return func(ctx context.Context, service string) ([]byte, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, docs.DocsPath, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := do(service, req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return nil, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxDocumentSize))
+	}
+}
@@ -0,0 +1,253 @@
+package openapi
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/google/uuid"
+)
+
+// Formaty używane w specyfikacjach; kin-openapi domyślnie ich nie sprawdza.
+// uuid – dowolna wersja (serwisy generują v7, wzorzec RFC 4122 z biblioteki zna tylko v1–v5).
+func init() {
+	openapi3.DefineStringFormatCallback("uuid", func(s string) error {
+		if _, err := uuid.Parse(s); err != nil || len(s) != 36 {
+			return errors.New("invalid UUID")
+		}
+		return nil
+	})
+	openapi3.DefineStringFormat("email", openapi3.FormatOfStringForEmail)
+}
+
+// Validator sprawdza żądania (a opcjonalnie odpowiedzi) względem specyfikacji OpenAPI 3
+// serwisów: <dir>/<upstream>.yaml. Serwis bez pliku nie jest walidowany; operacja
+// nieopisana w specyfikacji serwisu przechodzi bez walidacji.
+type Validator struct {
+	specs     map[string]routers.Router // upstream -> router operacji
+	responses bool
+}
+
+// Load wczytuje i weryfikuje specyfikacje serwisów. Pusty dir wyłącza walidację.
+func Load(dir string, services []string, validateResponses bool) (*Validator, error) {
+	v := &Validator{specs: make(map[string]routers.Router), responses: validateResponses}
+	if dir == "" {
+		return v, nil
+	}
+
+	for _, name := range services {
+		path := filepath.Join(dir, name+".yaml")
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+
+		loader := openapi3.NewLoader()
+		doc, err := loader.LoadFromFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("openapi spec %s: %w", path, err)
+		}
+		if err := doc.Validate(loader.Context); err != nil {
+			return nil, fmt.Errorf("openapi spec %s: %w", path, err)
+		}
+		router, err := gorillamux.NewRouter(doc)
+		if err != nil {
+			return nil, fmt.Errorf("openapi spec %s: %w", path, err)
+		}
+		v.specs[name] = router
+	}
+	return v, nil
+}
+
+// Services – upstreamy z wczytaną specyfikacją
+func (v *Validator) Services() []string {
+	return slices.Sorted(maps.Keys(v.specs))
+}
+
+// Covers – czy upstream ma specyfikację
+func (v *Validator) Covers(service string) bool {
+	_, ok := v.specs[service]
+	return ok
+}
+
+// ValidatesResponses – walidacja odpowiedzi (poza produkcją)
+func (v *Validator) ValidatesResponses() bool {
+	return v.responses
+}
+
+// Operation – operacja ze specyfikacji dopasowana do żądania
+type Operation struct {
+	input *openapi3filter.RequestValidationInput
+}
+
+// Match szuka operacji dla żądania (bez body). nil – brak specyfikacji serwisu lub operacji.
+func (v *Validator) Match(service string, req *http.Request) *Operation {
+	router, ok := v.specs[service]
+	if !ok {
+		return nil
+	}
+	route, params, err := router.FindRoute(req)
+	if err != nil {
+		return nil
+	}
+
+	return &Operation{input: &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: params,
+		Route:      route,
+		Options: &openapi3filter.Options{
+			// Uwierzytelnienie sprawdza gateway (JWT, sesja) – spec opisuje tylko kształt żądań
+			AuthenticationFunc:  openapi3filter.NoopAuthenticationFunc,
+			MultiError:          true,
+			SkipSettingDefaults: true,
+		},
+	}}
+}
+
+// BuffersBody – czy body żądania jest walidowane schematem (tylko JSON). Pozostałe
+// (formularze, multipart, binarne) przechodzą bez buforowania – sprawdzany jest Content-Type.
+func (op *Operation) BuffersBody() bool {
+	body := op.input.Route.Operation.RequestBody
+	if body == nil || body.Value == nil {
+		return false
+	}
+	return isJSON(op.input.Request.Header.Get("Content-Type"))
+}
+
+// ValidateRequest waliduje parametry ścieżki, query i nagłówki, a body – gdy BuffersBody.
+// Zwraca naruszenia: pole (np. "query.page", "body.email") -> komunikat.
+func (op *Operation) ValidateRequest(ctx context.Context, body []byte, hasBody bool) map[string]any {
+	req := op.input.Request
+	buffered := op.BuffersBody()
+	op.input.Options.ExcludeRequestBody = !buffered
+	if buffered {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	violations := make(map[string]any)
+	if err := openapi3filter.ValidateRequest(ctx, op.input); err != nil {
+		collect(violations, err)
+	}
+	if !buffered {
+		checkContentType(violations, op.input.Route.Operation.RequestBody, req.Header.Get("Content-Type"), hasBody)
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+	return violations
+}
+
+// ValidateResponse waliduje status, nagłówki i body odpowiedzi upstreamu (body nil – bez body)
+func (op *Operation) ValidateResponse(ctx context.Context, status int, header http.Header, body []byte) map[string]any {
+	input := &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: op.input,
+		Status:                 status,
+		Header:                 header,
+		Options: &openapi3filter.Options{
+			MultiError:            true,
+			IncludeResponseStatus: true,
+			ExcludeResponseBody:   body == nil,
+		},
+	}
+	input.SetBodyBytes(body)
+
+	violations := make(map[string]any)
+	if err := openapi3filter.ValidateResponse(ctx, input); err != nil {
+		collect(violations, err)
+		return violations
+	}
+	return nil
+}
+
+// checkContentType – dla body niebuforowanego sprawdzamy tylko obecność i typ treści
+func checkContentType(violations map[string]any, ref *openapi3.RequestBodyRef, contentType string, hasBody bool) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	if !hasBody {
+		if ref.Value.Required {
+			violations["body"] = "value is required but missing"
+		}
+		return
+	}
+	if len(ref.Value.Content) > 0 && ref.Value.Content.Get(contentType) == nil {
+		violations["body"] = fmt.Sprintf("unsupported content type %q", contentType)
+	}
+}
+
+// collect rozkłada błędy kin-openapi na pola. Typy sprawdzamy wprost, bez errors.As:
+// MultiError i RequestError się rozpakowują, a zgubilibyśmy kontekst (parametr / body).
+func collect(violations map[string]any, err error) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collect(violations, inner)
+		}
+	case *openapi3filter.RequestError:
+		field := "body"
+		if e.Parameter != nil {
+			field = e.Parameter.In + "." + e.Parameter.Name
+		}
+		if e.Err == nil {
+			violations[field] = e.Reason
+			return
+		}
+		collectSchema(violations, field, e.Err)
+	case *openapi3filter.ResponseError:
+		if e.Err == nil {
+			violations["response"] = e.Reason
+			return
+		}
+		collectSchema(violations, "response", e.Err)
+	default:
+		violations["request"] = err.Error()
+	}
+}
+
+func collectSchema(violations map[string]any, field string, err error) {
+	switch e := err.(type) {
+	case openapi3.MultiError:
+		for _, inner := range e {
+			collectSchema(violations, field, inner)
+		}
+	case *openapi3.SchemaError:
+		if path := e.JSONPointer(); len(path) > 0 {
+			field += "." + strings.Join(path, ".")
+		}
+		violations[field] = e.Reason
+	case *openapi3filter.ParseError:
+		// Błąd dekodowania (np. "abc" jako liczba) – bez wartości w komunikacie
+		if e.Reason != "" {
+			violations[field] = e.Reason
+			return
+		}
+		violations[field] = "invalid value"
+	default:
+		violations[field] = err.Error()
+	}
+}
+
+// isJSON – body walidowane schematem. Formularzy nie dekodujemy: kin-openapi zamienia
+// brakujące pola opcjonalne na null i odrzuca poprawne żądania (np. /oauth/introspect).
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
@@ -0,0 +1,480 @@
+package router
+
+import (
+	"encoding/json"
+	"maps"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	pkgmiddleware "github.com/zerodayz7/platform/pkg/middleware"
+	"github.com/zerodayz7/platform/pkg/redis"
+	pkgRouter "github.com/zerodayz7/platform/pkg/router"
+	"github.com/zerodayz7/platform/pkg/schemas"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/admin"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"github.com/zerodayz7/platform/services/gateway/internal/upstream"
+)
+
+// Akcje admin API w audit_stream
+const (
+	AuditGatewayDrain       = "GATEWAY_UPSTREAM_DRAIN"
+	AuditGatewayBreaker     = "GATEWAY_BREAKER_FORCED"
+	AuditGatewayMaintenance = "GATEWAY_ROUTE_MAINTENANCE"
+	AuditGatewayWindowSet   = "GATEWAY_MAINTENANCE_SCHEDULED"
+	AuditGatewayWindowUnset = "GATEWAY_MAINTENANCE_CANCELLED"
+	AuditGatewayIPUnbanned  = "GATEWAY_IP_UNBANNED"
+)
+
+// SetupAdminRoutes – admin API gatewaya (osobny adres ADMIN_ADDRESS). JWT, sesję i kontekst
+// sprawdza aplikacja (config.NewAdminApp); tu – role operatora.
+func SetupAdminRoutes(app *fiber.App, container *di.Container) {
+	api := app.Group("/admin", pkgmiddleware.RequireRoles(container.Config.Admin.Roles...))
+
+	api.Get("/routes", adminRoutes(container))
+	api.Get("/upstreams", adminUpstreams(container))
+	api.Get("/limiters", adminLimiters(container))
+	api.Get("/sessions", adminSessions(container))
+	api.Get("/overrides", adminOverrides(container))
+	api.Get("/maintenance/windows", adminWindows(container))
+	api.Get("/network/ip/:ip", adminIPStatus(container))
+
+	// Każda akcja wymaga uzasadnienia i trafia do audit_stream
+	api.Post("/upstreams/:name/drain", validated[schemas.GatewayDrainRequest], adminDrain(container))
+	api.Post("/upstreams/:name/breaker", validated[schemas.GatewayBreakerRequest], adminBreaker(container))
+	api.Post("/routes/maintenance", validated[schemas.GatewayMaintenanceRequest], adminMaintenance(container))
+	api.Post("/maintenance/windows", validated[schemas.GatewayMaintenanceWindowRequest], adminScheduleWindow(container))
+	api.Post("/maintenance/windows/:id/cancel", validated[schemas.AdminActionRequest], adminCancelWindow(container))
+	api.Post("/network/ip/:ip/unban", validated[schemas.AdminActionRequest], adminUnbanIP(container))
+
+	pkgRouter.SetupFallbackHandlers(app)
+}
+
+func validated[T any](c *fiber.Ctx) error {
+	if err := middleware.BindBody[T](c); err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return c.Next()
+}
+
+type adminRoute struct {
+	Key         string       `json:"key"`
+	Path        string       `json:"path"`
+	Methods     []string     `json:"methods,omitempty"`
+	Mode        string       `json:"mode"`
+	Upstream    string       `json:"upstream,omitempty"`
+	Compose     []string     `json:"compose,omitempty"`
+	Limiter     string       `json:"limiter,omitempty"`
+	Roles       []string     `json:"roles,omitempty"`
+	Timeout     string       `json:"timeout"`
+	Stream      bool         `json:"stream,omitempty"`
+	Cached      bool         `json:"cached,omitempty"`
+	Canary      *adminCanary `json:"canary,omitempty"`
+	Maintenance bool         `json:"maintenance"`
+}
+
+type adminCanary struct {
+	Upstream   string `json:"upstream"`
+	Weight     int    `json:"weight"`
+	RolledBack bool   `json:"rolled_back"`
+}
+
+// adminRoutes – bieżąca tablica tras (po ostatnim przeładowaniu routes.yaml)
+func adminRoutes(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		routes := container.Routes.Table().Routes()
+		out := make([]adminRoute, 0, len(routes))
+		for _, r := range routes {
+			item := adminRoute{
+				Key:         r.Key(),
+				Path:        r.Path,
+				Methods:     r.Methods,
+				Mode:        r.Mode,
+				Upstream:    r.Target,
+				Limiter:     r.Limiter,
+				Roles:       r.Roles,
+				Timeout:     r.Timeout.String(),
+				Stream:      r.Stream,
+				Cached:      r.Cache != nil,
+				Maintenance: container.Overrides.Maintenance(r) != nil,
+			}
+			for _, b := range r.Branches {
+				item.Compose = append(item.Compose, b.Name+" <- "+b.Target)
+			}
+			if r.CanaryTarget != "" {
+				item.Canary = &adminCanary{
+					Upstream:   r.CanaryTarget,
+					Weight:     r.Canary.Weight,
+					RolledBack: container.Canary.RolledBack(r),
+				}
+			}
+			out = append(out, item)
+		}
+		return c.JSON(fiber.Map{"routes": out})
+	}
+}
+
+type adminUpstream struct {
+	Name          string                    `json:"name"`
+	Breaker       string                    `json:"breaker"`
+	BreakerForced bool                      `json:"breaker_forced"`
+	Instances     []upstream.InstanceStatus `json:"instances"`
+}
+
+// adminUpstreams – instancje (zdrowie, drain, wyłączenie pasywne) i breakery tej instancji gatewaya
+func adminUpstreams(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ups := container.Upstreams.All()
+		out := make([]adminUpstream, 0, len(ups))
+		for _, up := range ups {
+			_, forced := up.Breaker.Forced()
+			out = append(out, adminUpstream{
+				Name:          up.Name,
+				Breaker:       up.Breaker.State().String(),
+				BreakerForced: forced,
+				Instances:     up.Pool.Instances(),
+			})
+		}
+		return c.JSON(fiber.Map{"upstreams": out})
+	}
+}
+
+type adminLimiter struct {
+	Name   string   `json:"name"`
+	Max    int      `json:"max"`
+	Window string   `json:"window"`
+	Routes []string `json:"routes,omitempty"` // pusty dla limitu globalnego
+}
+
+// adminLimiters – polityki limitów w użyciu: globalna i te z tras
+func adminLimiters(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		byName := map[string]*adminLimiter{}
+		names := []string{string(shared.LimitGlobal)}
+		limiter := func(name string) *adminLimiter {
+			if l, ok := byName[name]; ok {
+				return l
+			}
+			limit, window, _ := shared.LimitPreset(shared.LimitGroup(name))
+			l := &adminLimiter{Name: name, Max: limit, Window: window.String()}
+			byName[name] = l
+			return l
+		}
+		limiter(string(shared.LimitGlobal))
+
+		for _, r := range container.Routes.Table().Routes() {
+			if r.Limiter == "" {
+				continue
+			}
+			if _, seen := byName[r.Limiter]; !seen {
+				names = append(names, r.Limiter)
+			}
+			l := limiter(r.Limiter)
+			l.Routes = append(l.Routes, r.Key())
+		}
+
+		out := make([]*adminLimiter, 0, len(names))
+		for _, name := range names {
+			out = append(out, byName[name])
+		}
+		return c.JSON(fiber.Map{"limiters": out})
+	}
+}
+
+// adminSessions – liczba aktywnych sesji w Redis (wspólna dla wszystkich instancji)
+func adminSessions(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessions, users, err := container.Cache.CountSessions(c.UserContext())
+		if err != nil {
+			shared.GetLogger().ErrorObj("Admin: session count failed", err)
+			return apperr.SendAppError(c, apperr.ErrInternal)
+		}
+		return c.JSON(fiber.Map{"sessions": sessions, "users": users})
+	}
+}
+
+// adminOverrides – obowiązujące zmiany operatorów (wspólne dla wszystkich instancji)
+func adminOverrides(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"overrides": container.Overrides.List()})
+	}
+}
+
+// adminDrain wyłącza instancję (albo cały upstream) z ruchu lub cofa drain
+func adminDrain(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.GatewayDrainRequest)
+		up := findUpstream(container, c.Params("name"))
+		if up == nil || (body.Instance != "" && !up.Pool.HasInstance(body.Instance)) {
+			return apperr.SendAppError(c, apperr.ErrUnknownUpstream)
+		}
+
+		target := admin.DrainTarget(up.Name, body.Instance)
+		err := applyOverride(c, container, admin.KindDrain, target, "", body.Drain)
+		if err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		recordAdminAction(c, container, AuditGatewayDrain, map[string]any{
+			"upstream":      up.Name,
+			"instance":      body.Instance,
+			"drain":         body.Drain,
+			"justification": body.Justification,
+		})
+		return c.JSON(fiber.Map{"upstream": up.Name, "instances": up.Pool.Instances()})
+	}
+}
+
+// adminBreaker wymusza stan breakera upstreamu (open / closed) albo przywraca automat
+func adminBreaker(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.GatewayBreakerRequest)
+		up := findUpstream(container, c.Params("name"))
+		if up == nil {
+			return apperr.SendAppError(c, apperr.ErrUnknownUpstream)
+		}
+
+		_, forced := admin.ParseBreakerState(body.State)
+		if err := applyOverride(c, container, admin.KindBreaker, up.Name, body.State, forced); err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		recordAdminAction(c, container, AuditGatewayBreaker, map[string]any{
+			"upstream":      up.Name,
+			"state":         body.State,
+			"justification": body.Justification,
+		})
+		return c.JSON(fiber.Map{"upstream": up.Name, "breaker": up.Breaker.State().String()})
+	}
+}
+
+// adminMaintenance włącza / wyłącza tryb serwisowy trasy (503 dla klientów)
+func adminMaintenance(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.GatewayMaintenanceRequest)
+		route := container.Routes.Table().Lookup(body.Route)
+		if route == nil {
+			return apperr.SendAppError(c, apperr.ErrUnknownRoute)
+		}
+
+		if err := applyOverride(c, container, admin.KindMaintenance, route.Key(), "", body.Enabled); err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		recordAdminAction(c, container, AuditGatewayMaintenance, map[string]any{
+			"route":         route.Key(),
+			"enabled":       body.Enabled,
+			"justification": body.Justification,
+		})
+		return c.JSON(fiber.Map{"route": route.Key(), "maintenance": body.Enabled})
+	}
+}
+
+type adminWindow struct {
+	ID        string    `json:"id"`
+	Source    string    `json:"source"`
+	State     string    `json:"state"` // scheduled | active | ended
+	Upstreams []string  `json:"upstreams,omitempty"`
+	Paths     []string  `json:"paths,omitempty"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Languages []string  `json:"languages"`
+	Testers   int       `json:"testers"`
+	Announce  string    `json:"announce,omitempty"`
+}
+
+// adminWindows – okna serwisowe z routes.yaml i z admin API
+func adminWindows(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		now := time.Now()
+		windows := container.Maintenance.Windows()
+		out := make([]adminWindow, 0, len(windows))
+		for _, w := range windows {
+			item := adminWindow{
+				ID:        w.ID,
+				Source:    w.Source,
+				State:     "scheduled",
+				Upstreams: w.Upstreams,
+				Paths:     w.Paths,
+				Start:     w.Start,
+				End:       w.End,
+				Languages: slices.Sorted(maps.Keys(w.Message)),
+				Testers:   len(w.Testers),
+			}
+			switch {
+			case w.Active(now):
+				item.State = "active"
+			case !now.Before(w.End):
+				item.State = "ended"
+			}
+			if w.Announce > 0 {
+				item.Announce = w.Announce.String()
+			}
+			out = append(out, item)
+		}
+		return c.JSON(fiber.Map{"windows": out})
+	}
+}
+
+// adminScheduleWindow planuje okno serwisowe (albo zmienia okno o tym samym ID)
+func adminScheduleWindow(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.GatewayMaintenanceWindowRequest)
+		spec := routing.MaintenanceSpec{
+			ID:        body.ID,
+			Upstreams: body.Upstreams,
+			Paths:     body.Paths,
+			Start:     body.Start,
+			End:       body.End,
+			Message:   body.Message,
+			Testers:   body.Testers,
+			Announce:  time.Duration(body.AnnounceMinutes) * time.Minute,
+		}
+		if _, err := container.Routes.CompileMaintenance(spec); err != nil {
+			return apperr.SendAppError(c, apperr.ErrValidationFailed.WithMeta("window", err.Error()))
+		}
+		if !body.End.After(time.Now()) {
+			return apperr.SendAppError(c, apperr.ErrValidationFailed.WithMeta("end", "Must be a future date"))
+		}
+
+		data, err := json.Marshal(spec)
+		if err != nil {
+			return apperr.SendAppError(c, err)
+		}
+		if err := applyOverride(c, container, admin.KindWindow, spec.ID, string(data), true); err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		recordAdminAction(c, container, AuditGatewayWindowSet, map[string]any{
+			"window":        spec.ID,
+			"upstreams":     spec.Upstreams,
+			"paths":         spec.Paths,
+			"start":         spec.Start,
+			"end":           spec.End,
+			"testers":       len(spec.Testers),
+			"justification": body.Justification,
+		})
+		return c.Status(fiber.StatusCreated).JSON(fiber.Map{"window": spec.ID, "start": spec.Start, "end": spec.End})
+	}
+}
+
+// adminCancelWindow odwołuje okno zaplanowane przez admin API (okna z routes.yaml – tylko w pliku)
+func adminCancelWindow(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.AdminActionRequest)
+		id := c.Params("id")
+		if !slices.ContainsFunc(container.Overrides.Windows(), func(w *routing.MaintenanceWindow) bool { return w.ID == id }) {
+			return apperr.SendAppError(c, apperr.ErrUnknownWindow)
+		}
+
+		if err := applyOverride(c, container, admin.KindWindow, id, "", false); err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		recordAdminAction(c, container, AuditGatewayWindowUnset, map[string]any{
+			"window":        id,
+			"justification": body.Justification,
+		})
+		return c.JSON(fiber.Map{"window": id, "cancelled": true})
+	}
+}
+
+// adminIPStatus – reputacja adresu: punkty, pozostały czas blokady i kraj
+func adminIPStatus(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		addr, err := netip.ParseAddr(c.Params("ip"))
+		if err != nil {
+			return apperr.SendAppError(c, apperr.ErrValidationFailed.WithMeta("ip", "Invalid IP address"))
+		}
+		addr = addr.Unmap()
+
+		score, ban, err := container.Cache.IPStatus(c.UserContext(), addr.String())
+		if err != nil {
+			shared.GetLogger().ErrorObj("Admin: IP reputation lookup failed", err)
+			return apperr.SendAppError(c, apperr.ErrInternal)
+		}
+		out := fiber.Map{
+			"ip":      addr.String(),
+			"score":   score,
+			"banned":  ban > 0,
+			"country": container.Network.Country(addr),
+		}
+		if ban > 0 {
+			out["ban_until"] = time.Now().Add(ban).UTC()
+		}
+		return c.JSON(out)
+	}
+}
+
+// adminUnbanIP zdejmuje blokadę adresu i zeruje jego punkty
+func adminUnbanIP(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		body := c.Locals("validatedBody").(schemas.AdminActionRequest)
+		addr, err := netip.ParseAddr(c.Params("ip"))
+		if err != nil {
+			return apperr.SendAppError(c, apperr.ErrValidationFailed.WithMeta("ip", "Invalid IP address"))
+		}
+		ip := addr.Unmap().String()
+
+		if err := container.Cache.ClearIPReputation(c.UserContext(), ip); err != nil {
+			shared.GetLogger().ErrorObj("Admin: IP unban failed", err)
+			return apperr.SendAppError(c, apperr.ErrInternal)
+		}
+
+		recordAdminAction(c, container, AuditGatewayIPUnbanned, map[string]any{
+			"ip":            ip,
+			"justification": body.Justification,
+		})
+		return c.JSON(fiber.Map{"ip": ip, "unbanned": true})
+	}
+}
+
+// applyOverride zapisuje (enabled) albo cofa zmianę operatora
+func applyOverride(c *fiber.Ctx, container *di.Container, kind, target, value string, enabled bool) error {
+	var err error
+	if enabled {
+		rc, _ := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext)
+		err = container.Overrides.Set(c.UserContext(), redis.GatewayOverride{
+			Kind:   kind,
+			Target: target,
+			Value:  value,
+			By:     rc.UserID.String(),
+		})
+	} else {
+		err = container.Overrides.Delete(c.UserContext(), kind, target)
+	}
+	if err != nil {
+		shared.GetLogger().ErrorMap("Admin: override not saved", map[string]any{
+			"kind":   kind,
+			"target": target,
+			"error":  err.Error(),
+		})
+		return apperr.ErrInternal
+	}
+	return nil
+}
+
+// findUpstream – upstream po nazwie (jak w SERVICES_*)
+func findUpstream(container *di.Container, name string) *upstream.Upstream {
+	ups := container.Upstreams.All()
+	if i := slices.IndexFunc(ups, func(up *upstream.Upstream) bool { return up.Name == name }); i >= 0 {
+		return ups[i]
+	}
+	return nil
+}
+
+func recordAdminAction(c *fiber.Ctx, container *di.Container, action string, meta map[string]any) {
+	rc, _ := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext)
+	if err := container.Audit.Record(c.UserContext(), rc.UserID.String(), action, rc.IP, meta); err != nil {
+		shared.GetLogger().ErrorMap("Admin: failed to write audit record", map[string]any{
+			"action": action,
+			"err":    err.Error(),
+		})
+	}
+}
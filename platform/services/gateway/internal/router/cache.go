@@ -0,0 +1,182 @@
+package router
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/httpcache"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// Większe odpowiedzi są przekazywane strumieniowo, bez zapisu w cache
+const maxCachedBody = 1 << 20
+
+// cacheTarget – gdzie i na jakich zasadach zapisać odpowiedź trasy z `cache:`
+type cacheTarget struct {
+	Key  httpcache.Key
+	Spec *routing.CacheSpec
+}
+
+// cacheKey wyznacza zakres wpisu: zalogowany użytkownik => jego prywatny zakres,
+// anonimowe żądanie trasy publicznej => zakres wspólny. Żądania z poświadczeniami
+// bez kontekstu użytkownika (np. client credentials) nie są cache'owane.
+func cacheKey(c *fiber.Ctx, route *routing.Route) (httpcache.Key, bool) {
+	path, query := versionedPath(c, c.Path()), sortedQuery(c)
+
+	rc, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	if rc != nil && rc.UserID != nil {
+		return httpcache.UserKey(rc.UserID.String(), path, query), true
+	}
+	if route.Mode == routing.ModePublic && c.Get(constants.HeaderAuth) == "" && c.Get(constants.HeaderCookie) == "" {
+		return httpcache.PublicKey(path, query), true
+	}
+	return httpcache.Key{}, false
+}
+
+// serveCached odpowiada z cache; false = miss (lub awaria Redis) i żądanie idzie do upstreamu
+func serveCached(c *fiber.Ctx, container *di.Container, key httpcache.Key) bool {
+	// Klient wymusza świeże dane – pomijamy odczyt, ale odpowiedź odświeży wpis
+	if strings.Contains(c.Get(fiber.HeaderCacheControl), "no-cache") {
+		return false
+	}
+
+	cached, err := container.ResponseCache.Lookup(c.UserContext(), key, requestHeader(c))
+	if err != nil {
+		shared.GetLogger().ErrorObj("Response cache lookup failed", err)
+		return false
+	}
+	if cached == nil {
+		return false
+	}
+
+	for k, values := range cached.Header {
+		for _, v := range values {
+			c.Response().Header.Add(k, v)
+		}
+	}
+	age := max(time.Now().Unix()-cached.StoredAt, 0)
+	c.Set(fiber.HeaderAge, strconv.FormatInt(age, 10))
+	c.Set("X-Cache", "HIT")
+	c.Status(cached.Status)
+	_ = c.Send(cached.Body)
+	return true
+}
+
+// storeResponse zapisuje odpowiedź 200 zgodnie z polityką trasy i Cache-Control upstreamu.
+// Zwraca odpowiedź do dalszego przekazania (body odczytane do bufora zostaje podmienione).
+func storeResponse(c *fiber.Ctx, container *di.Container, target *cacheTarget, resp *http.Response) (*http.Response, error) {
+	c.Set("X-Cache", "MISS")
+	if resp.StatusCode != fiber.StatusOK {
+		return resp, nil
+	}
+	ttl, ok := httpcache.Policy(resp.Header, target.Spec.TTL, target.Key.Shared())
+	if !ok {
+		return resp, nil
+	}
+
+	resp, body, buffered, err := bufferResponseBody(resp, maxCachedBody)
+	if err != nil || !buffered {
+		return resp, err
+	}
+
+	header := storedHeader(resp.Header)
+	tags := append(slices.Clone(target.Spec.Tags), httpcache.Tags(resp.Header)...)
+
+	err = container.ResponseCache.Store(c.UserContext(), target.Key, requestHeader(c), resp.StatusCode, header, body, ttl, tags)
+	if err != nil {
+		shared.GetLogger().ErrorObj("Response cache store failed", err)
+	}
+	return resp, nil
+}
+
+// bufferResponseBody wczytuje body odpowiedzi, jeśli mieści się w limicie. Przy większym
+// (buffered=false) zwraca odpowiedź, która odda całość strumieniowo: bufor + reszta body.
+func bufferResponseBody(resp *http.Response, limit int64) (_ *http.Response, body []byte, buffered bool, err error) {
+	if resp.ContentLength > limit {
+		return resp, nil, false, nil
+	}
+
+	body, err = io.ReadAll(io.LimitReader(resp.Body, limit+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, nil, false, err
+	}
+	if int64(len(body)) > limit {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil, false, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	resp.ContentLength = int64(len(body))
+	return resp, body, true, nil
+}
+
+// storedHeader – nagłówki odpowiedzi zapisywane w Redis (bez hop-by-hop i per odpowiedź)
+func storedHeader(h http.Header) http.Header {
+	header := make(http.Header)
+	for k, v := range h {
+		if isHopByHop(k) || isUncached(k) {
+			continue
+		}
+		header[k] = v
+	}
+	return header
+}
+
+// Nagłówki, których nie zapisujemy we wpisie (per odpowiedź albo wewnętrzne)
+var uncachedHeaders = []string{
+	fiber.HeaderContentLength,
+	fiber.HeaderDate,
+	fiber.HeaderSetCookie,
+	constants.HeaderRequestID,
+	constants.HeaderCacheTag,
+}
+
+func isUncached(header string) bool {
+	return slices.ContainsFunc(uncachedHeaders, func(h string) bool {
+		return strings.EqualFold(h, header)
+	})
+}
+
+// purgeAfterWrite unieważnia tagi użytkownika po udanym zapisie (routes.yaml: purge_tags)
+func purgeAfterWrite(c *fiber.Ctx, container *di.Container, tags []string, status int) {
+	if status < 200 || status >= 300 {
+		return
+	}
+	rc, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	if rc == nil || rc.UserID == nil {
+		return
+	}
+
+	if _, err := container.ResponseCache.Purge(c.UserContext(), rc.UserID.String(), tags...); err != nil {
+		shared.GetLogger().ErrorObj("Response cache purge failed", err)
+	}
+}
+
+// requestHeader – wartości nagłówków żądania dla wariantów z Vary
+func requestHeader(c *fiber.Ctx) func(string) string {
+	return func(name string) string { return c.Get(name) }
+}
+
+// sortedQuery – kolejność parametrów nie tworzy osobnych wpisów
+func sortedQuery(c *fiber.Ctx) string {
+	var pairs []string
+	c.Request().URI().QueryArgs().VisitAll(func(k, v []byte) {
+		pairs = append(pairs, string(k)+"="+string(v))
+	})
+	slices.Sort(pairs)
+	return strings.Join(pairs, "&")
+}
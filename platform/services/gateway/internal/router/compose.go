@@ -0,0 +1,125 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// Większa odpowiedź sekcji jest traktowana jak błąd sekcji
+const maxSectionBody = 1 << 20
+
+// section – wynik jednej sekcji trasy składanej: dane albo znacznik błędu
+type section struct {
+	Data  json.RawMessage `json:"data,omitempty"`
+	Error *sectionError   `json:"error,omitempty"`
+}
+
+type sectionError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Status  int    `json:"status"`
+}
+
+func failedSection(err *apperr.AppError) *section {
+	return &section{Error: &sectionError{Code: err.Code, Message: err.Message, Status: apperr.HTTPStatus(err)}}
+}
+
+// proxyCompose obsługuje trasę składaną (routes.yaml: compose): sekcje są pobierane
+// równolegle z kontekstem użytkownika, każda z własnym limitem czasu. Awaria sekcji
+// nie psuje odpowiedzi – sekcja dostaje znacznik błędu; błąd całości tylko, gdy padły wszystkie.
+func proxyCompose(c *fiber.Ctx, container *di.Container, route *routing.Route) error {
+	results := make(map[string]*section, len(route.Branches))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for _, branch := range route.Branches {
+		// Żądanie (z podpisem) budujemy przed startem gorutyny – fiber.Ctx nie jest współbieżny
+		req, err := http.NewRequestWithContext(c.UserContext(), http.MethodGet, branch.Path, http.NoBody)
+		if err == nil {
+			err = signRequest(c, container, req, branch.Target)
+		}
+		if err != nil {
+			return apperr.SendAppError(c, err)
+		}
+		key := balanceKey(c)
+
+		wg.Go(func() {
+			result := fetchSection(container, branch, req, key)
+			mu.Lock()
+			results[branch.Name] = result
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != nil {
+			failed++
+		}
+	}
+	if failed == len(results) {
+		return apperr.SendAppError(c, apperr.ErrUpstreamUnavailable)
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return c.JSON(results)
+}
+
+func fetchSection(container *di.Container, branch *routing.Branch, req *http.Request, key string) *section {
+	log := shared.GetLogger()
+
+	resp, err := container.Upstreams.Do(branch.Target, req, key, branch.Timeout)
+	if err != nil {
+		return failedSection(transportError(branch.Target, err, log))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSectionBody+1))
+	switch {
+	case err != nil:
+		return failedSection(transportError(branch.Target, err, log))
+	case len(body) > maxSectionBody:
+		log.WarnMap("Compose section response too large", map[string]any{"section": branch.Name, "limit": maxSectionBody})
+		return failedSection(apperr.ErrUpstreamUnreachable)
+	}
+
+	if code := internalRejectionCode(resp.StatusCode, body); code != "" {
+		log.ErrorMap("Security Alert: Upstream rejected internal context", map[string]any{
+			"section":  branch.Name,
+			"upstream": branch.Target,
+			"code":     code,
+		})
+		return failedSection(apperr.ErrUpstreamContextRejected)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		// Błąd serwisu (AppError) trafia do sekcji bez zmian
+		var upstreamErr sectionError
+		if json.Unmarshal(body, &upstreamErr) == nil && upstreamErr.Code != "" {
+			upstreamErr.Status = resp.StatusCode
+			return &section{Error: &upstreamErr}
+		}
+		return &section{Error: &sectionError{
+			Code:    apperr.ErrUpstreamUnreachable.Code,
+			Message: apperr.ErrUpstreamUnreachable.Message,
+			Status:  resp.StatusCode,
+		}}
+	}
+
+	if resp.StatusCode == fiber.StatusNoContent || len(body) == 0 {
+		return &section{Data: json.RawMessage("null")}
+	}
+	if !isJSON(resp.Header.Get(fiber.HeaderContentType)) || !json.Valid(body) {
+		log.WarnMap("Compose section is not JSON", map[string]any{"section": branch.Name})
+		return failedSection(apperr.ErrUpstreamUnreachable)
+	}
+	return &section{Data: body}
+}
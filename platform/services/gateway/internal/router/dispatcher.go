@@ -0,0 +1,225 @@
+package router
+
+import (
+	"io"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	pkgmiddleware "github.com/zerodayz7/platform/pkg/middleware"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/canary"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"go.uber.org/zap"
+)
+
+// Dispatch obsługuje wszystkie trasy z routes.yaml. Trasę ustala middleware APIVersion
+// z aktualnej tablicy przy każdym żądaniu, więc przeładowanie pliku działa bez restartu
+// gatewaya, a żądanie w trakcie obsługi widzi jedną wersję tras.
+func Dispatch(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		route := middleware.RouteOf(c)
+		if route == nil {
+			// Brak trasy – dalej obsłuży fallback (404)
+			return c.Next()
+		}
+
+		if err := checkMaintenance(c, container, route); err != nil {
+			return apperr.SendAppError(c, err)
+		}
+
+		if route.Allowlist != nil && !networkAllowed(c, container, route) {
+			return netguard.Reject(c, netguard.ReasonNotAllowed, apperr.ErrIPBlocked)
+		}
+
+		if len(route.Roles) > 0 {
+			if err := authorizeRoles(c, route); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+		}
+
+		if route.Limiter != "" {
+			if err := enforceLimit(c, container, route); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+		}
+
+		// Trasa składana – odpowiedź powstaje w gatewayu z sekcji (routes.yaml: compose)
+		if len(route.Branches) > 0 {
+			return proxyCompose(c, container, route)
+		}
+
+		if route.Schema != "" {
+			// Walidacja wymaga całego body – buforujemy je (z limitem trasy)
+			if err := bufferBody(c, route.BodyLimit); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+			bind, _ := middleware.BodySchema(route.Schema)
+			if err := bind(c); err != nil {
+				return apperr.SendAppError(c, err)
+			}
+		}
+
+		opts := proxyOptions{
+			Target:      route.Target,
+			Timeout:     route.Timeout,
+			BodyLimit:   route.BodyLimit,
+			PassHeaders: route.PassHeaders,
+			PurgeTags:   route.PurgeTags,
+		}
+		if container.WebSessions != nil {
+			opts.WebSession = route.WebSession
+		}
+
+		// Mapowanie trasy dla wersji API wybranej przez middleware APIVersion
+		var mapping *routing.RouteVersion
+		if version := middleware.APIVersionOf(c); version != nil {
+			mapping = route.ForVersion(version.Name)
+		}
+		if mapping != nil {
+			if mapping.Target != "" {
+				opts.Target = mapping.Target
+			}
+			opts.PathPrefix = mapping.Prefix
+			opts.Rename = mapping.Rename
+		}
+
+		// Kontrakt OpenAPI upstreamu (parametry, query, nagłówki, body). Specyfikacja
+		// opisuje ścieżki domyślnego upstreamu – inny serwis lub prefiks nie jest walidowany.
+		if mapping == nil || (mapping.Target == "" && mapping.Prefix == "") {
+			operation, err := validateRequest(c, container, route)
+			if err != nil {
+				return apperr.SendAppError(c, err)
+			}
+			if operation != nil && container.OpenAPI.ValidatesResponses() {
+				opts.Operation = operation
+			}
+		}
+
+		if route.Idempotency != nil {
+			claim, replayed, err := claimIdempotencyKey(c, container, route)
+			if err != nil {
+				return apperr.SendAppError(c, err)
+			}
+			if replayed {
+				return nil
+			}
+			if claim != nil {
+				// Żądanie nie dotarło do upstreamu albo nie dało wyniku – klucz wraca do puli
+				defer claim.release(c, container)
+				opts.Idempotency = claim
+			}
+		}
+
+		if route.Cache != nil && c.Method() == fiber.MethodGet {
+			if key, ok := cacheKey(c, route); ok {
+				if serveCached(c, container, key) {
+					return nil
+				}
+				opts.Cache = &cacheTarget{Key: key, Spec: route.Cache}
+			}
+		}
+
+		// Wariant canary wybieramy dopiero dla żądań, które faktycznie idą do upstreamu
+		// trasy (nie z cache, nie do upstreamu innej wersji API)
+		if route.CanaryTarget != "" && opts.Target == route.Target {
+			opts.Canary = chooseVariant(c, container, route)
+			if opts.Canary.Variant == canary.VariantCanary {
+				opts.Target = route.CanaryTarget
+			}
+		}
+
+		if route.Stream {
+			return proxyStream(c, container, opts)
+		}
+		if route.SignedContext {
+			return proxySecure(c, container, opts)
+		}
+		return proxyPublic(c, container, opts)
+	}
+}
+
+// chooseVariant przydziela żądanie do wariantu trasy z canary. Decyzja trafia do logu
+// żądania, a poza produkcją także do nagłówka X-Upstream-Variant.
+func chooseVariant(c *fiber.Ctx, container *di.Container, route *routing.Route) *canary.Decision {
+	// Wariant wymusza nagłówkiem tylko tester – bez listy testers nagłówek nic nie zmienia
+	var override string
+	if header := c.Get(constants.HeaderCanary); header != "" && len(route.Canary.Testers) > 0 {
+		rc, _ := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext)
+		if rc != nil && pkgmiddleware.HasAnyRole(rc, route.Canary.Testers...) {
+			override = header
+		}
+	}
+
+	decision := container.Canary.Choose(route, balanceKey(c), override)
+	shared.AddLogField(c, zap.String("variant", decision.Variant))
+	shared.AddLogField(c, zap.String("variant_reason", decision.Reason))
+	if container.Config.Server.Env != "production" {
+		c.Set(constants.HeaderUpstreamVariant, decision.Variant)
+	}
+	return decision
+}
+
+// bufferBody wczytuje strumień body do pamięci, nie więcej niż limit trasy
+func bufferBody(c *fiber.Ctx, limit int64) error {
+	req := c.Request()
+	if int64(req.Header.ContentLength()) > limit {
+		return apperr.ErrPayloadTooLarge
+	}
+
+	stream := c.Context().RequestBodyStream()
+	if stream == nil {
+		return nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(stream, limit+1))
+	_ = req.CloseBodyStream()
+	if err != nil {
+		return apperr.ErrInvalidRequestBody
+	}
+	if int64(len(data)) > limit {
+		return apperr.ErrPayloadTooLarge
+	}
+	req.SetBodyRaw(data)
+	return nil
+}
+
+func authorizeRoles(c *fiber.Ctx, route *routing.Route) error {
+	rc, ok := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext)
+	if !ok || rc == nil || rc.UserID == nil {
+		return apperr.ErrUnauthorized
+	}
+	if !pkgmiddleware.HasAnyRole(rc, route.Roles...) {
+		shared.GetLogger().WarnMap("Access denied: missing role", map[string]any{
+			"uid":      rc.UserID,
+			"roles":    rc.Roles,
+			"required": route.Roles,
+			"path":     c.Path(),
+		})
+		return apperr.ErrForbidden
+	}
+	return nil
+}
+
+// enforceLimit – okno stałe w Redis, współdzielone przez instancje gatewaya.
+// Przy awarii Redis przepuszczamy ruch (globalny limiter nadal działa).
+func enforceLimit(c *fiber.Ctx, container *di.Container, route *routing.Route) error {
+	limit, window, _ := shared.LimitPreset(shared.LimitGroup(route.Limiter))
+
+	count, ttl, err := container.Cache.HitRateLimit(c.UserContext(), "gw:"+route.Limiter+":"+reqctx.ClientIP(c), window)
+	if err != nil {
+		shared.GetLogger().ErrorObj("Route limiter unavailable", err)
+		return nil
+	}
+
+	if count > int64(limit) {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(ttl.Seconds())+1))
+		return apperr.ErrTooManyRequests
+	}
+	return nil
+}
@@ -0,0 +1,232 @@
+package router
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+const (
+	// Większe odpowiedzi nie są zapisywane – powtórzenie żądania kończy się 409
+	maxIdempotentBody = 1 << 20
+	// Blokada trwa tyle, ile może trwać żądanie (timeout trasy), plus zapas na zapis wyniku
+	idempotencyLockGrace = 30 * time.Second
+	maxIdempotencyKeyLen = 255
+)
+
+// idempotencyClaim – klucz Idempotency-Key zajęty przez bieżące żądanie
+type idempotencyClaim struct {
+	key     string
+	pending redis.IdempotencyRecord
+	ttl     time.Duration
+	settled bool
+}
+
+// claimIdempotencyKey zajmuje klucz z nagłówka Idempotency-Key (routes.yaml: idempotency).
+// replayed=true – odpowiedź została już odtworzona z zapisu; claim=nil i brak błędu –
+// żądanie bez klucza albo awaria Redis (przechodzi bez ochrony, jak przy limiterze).
+func claimIdempotencyKey(c *fiber.Ctx, container *di.Container, route *routing.Route) (claim *idempotencyClaim, replayed bool, err error) {
+	key := c.Get(constants.HeaderIdempotencyKey)
+	if key == "" {
+		if route.Idempotency.Required {
+			return nil, false, apperr.ErrIdempotencyKeyRequired
+		}
+		return nil, false, nil
+	}
+	if !validIdempotencyKey(key) {
+		return nil, false, apperr.ErrIdempotencyKeyInvalid
+	}
+
+	// Klucz jest unikalny w zakresie użytkownika (albo sesji urządzenia przed parowaniem)
+	rc, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	var scope string
+	switch {
+	case rc == nil:
+		return nil, false, apperr.ErrUnauthorized
+	case rc.UserID != nil:
+		scope = "user:" + rc.UserID.String()
+	case rc.SessionID != "":
+		scope = "session:" + rc.SessionID
+	default:
+		return nil, false, apperr.ErrUnauthorized
+	}
+
+	// Odcisk żądania wymaga całego body – buforujemy je (z limitem trasy)
+	if err := bufferBody(c, route.BodyLimit); err != nil {
+		return nil, false, err
+	}
+
+	claim = &idempotencyClaim{
+		key: digest(scope, key),
+		pending: redis.IdempotencyRecord{
+			Fingerprint: requestFingerprint(c),
+			Owner:       rc.RequestID,
+			CreatedAt:   time.Now().Unix(),
+		},
+		ttl: route.Idempotency.TTL,
+	}
+
+	existing, err := container.Cache.AcquireIdempotencyKey(c.UserContext(), claim.key, claim.pending, route.Timeout+idempotencyLockGrace)
+	if err != nil {
+		shared.GetLogger().ErrorObj("Idempotency store unavailable", err)
+		return nil, false, nil
+	}
+	if existing == nil {
+		return claim, false, nil
+	}
+
+	switch {
+	case existing.Fingerprint != claim.pending.Fingerprint:
+		shared.GetLogger().WarnMap("Idempotency key reused with a different request", map[string]any{
+			"scope": scope,
+			"path":  c.Path(),
+		})
+		return nil, false, apperr.ErrIdempotencyKeyReused
+	case !existing.Completed:
+		c.Set(fiber.HeaderRetryAfter, "1")
+		return nil, false, apperr.ErrIdempotencyInProgress
+	case existing.Response == nil:
+		return nil, false, apperr.ErrIdempotencyNoReplay
+	}
+
+	replayResponse(c, existing.Response)
+	return nil, true, nil
+}
+
+// completeIdempotency zapisuje wynik żądania pod kluczem. Odpowiedzi, które nie są
+// wynikiem operacji (5xx, 429), zwalniają klucz do ponowienia – odrzucony kontekst
+// wewnętrzny odpada wcześniej (checkContextRejection).
+func completeIdempotency(c *fiber.Ctx, container *di.Container, claim *idempotencyClaim, resp *http.Response) (*http.Response, error) {
+	if !operationResult(resp.StatusCode) {
+		claim.release(c, container)
+		return resp, nil
+	}
+
+	status := resp.StatusCode
+	resp, body, buffered, err := bufferResponseBody(resp, maxIdempotentBody)
+	if err != nil {
+		claim.settleUnreplayable(c, container, status)
+		return nil, err
+	}
+
+	var stored *redis.CachedResponse
+	if buffered {
+		stored = &redis.CachedResponse{
+			Status:   resp.StatusCode,
+			Header:   storedHeader(resp.Header),
+			Body:     body,
+			StoredAt: time.Now().Unix(),
+		}
+	}
+	claim.settle(c, container, resp.StatusCode, stored)
+	return resp, nil
+}
+
+// settleUnreplayable zamyka klucz bez zapisanej odpowiedzi: upstream wykonał operację,
+// ale jej wyniku nie udało się przekazać (np. odpowiedź niezgodna z kontraktem). Zwolnienie
+// klucza pozwoliłoby ponowieniu wykonać ją drugi raz – powtórzenie dostaje 409 (NoReplay).
+func (cl *idempotencyClaim) settleUnreplayable(c *fiber.Ctx, container *di.Container, status int) {
+	if !operationResult(status) {
+		cl.release(c, container)
+		return
+	}
+	cl.settle(c, container, status, nil)
+}
+
+func (cl *idempotencyClaim) settle(c *fiber.Ctx, container *di.Container, status int, stored *redis.CachedResponse) {
+	if cl.settled {
+		return
+	}
+	cl.settled = true
+
+	result := cl.pending
+	result.Owner = ""
+	result.Completed = true
+	result.Response = stored
+
+	ok, err := container.Cache.CompleteIdempotencyKey(c.UserContext(), cl.key, cl.pending, result, cl.ttl)
+	switch {
+	case err != nil:
+		shared.GetLogger().ErrorObj("Idempotency result store failed", err)
+	case !ok:
+		shared.GetLogger().WarnMap("Idempotency lock expired before the request completed", map[string]any{
+			"path":   c.Path(),
+			"status": status,
+		})
+	}
+}
+
+// operationResult – odpowiedź jest wynikiem operacji (nie 5xx ani 429)
+func operationResult(status int) bool {
+	return status < fiber.StatusInternalServerError && status != fiber.StatusTooManyRequests
+}
+
+// release zwalnia klucz, jeśli żądanie nie dało wyniku (np. upstream niedostępny)
+func (cl *idempotencyClaim) release(c *fiber.Ctx, container *di.Container) {
+	if cl.settled {
+		return
+	}
+	cl.settled = true
+	if err := container.Cache.ReleaseIdempotencyKey(c.UserContext(), cl.key, cl.pending); err != nil {
+		shared.GetLogger().ErrorObj("Idempotency key release failed", err)
+	}
+}
+
+func replayResponse(c *fiber.Ctx, stored *redis.CachedResponse) {
+	for k, values := range stored.Header {
+		for _, v := range values {
+			c.Response().Header.Add(k, v)
+		}
+	}
+	c.Set(constants.HeaderIdempotentReplayed, "true")
+	c.Status(stored.Status)
+	_ = c.Send(stored.Body)
+}
+
+// requestFingerprint – skrót metody, ścieżki z query, Content-Type i body. Granica
+// multipart jest losowana przy każdym wysłaniu, więc nie wchodzi do odcisku.
+func requestFingerprint(c *fiber.Ctx) string {
+	contentType := c.Get(fiber.HeaderContentType)
+	body := c.Body()
+
+	if mediaType, params, err := mime.ParseMediaType(contentType); err == nil && strings.HasPrefix(mediaType, "multipart/") {
+		if boundary := params["boundary"]; boundary != "" {
+			contentType = mediaType
+			body = bytes.ReplaceAll(body, []byte("--"+boundary), []byte("--boundary"))
+		}
+	}
+
+	bodySum := sha256.Sum256(body)
+	return digest(c.Method(), versionedPath(c, c.OriginalURL()), contentType, hex.EncodeToString(bodySum[:]))
+}
+
+// validIdempotencyKey – niepusty, widoczne znaki ASCII (np. UUID v4 klienta)
+func validIdempotencyKey(key string) bool {
+	if len(key) > maxIdempotencyKeyLen {
+		return false
+	}
+	for i := 0; i < len(key); i++ {
+		if key[i] < '!' || key[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+func digest(parts ...string) string {
+	sum := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,42 @@
+package router
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"go.uber.org/zap"
+)
+
+// checkMaintenance zwraca 503, gdy trasa jest wyłączona przez operatora albo trwa okno
+// serwisowe jej grupy. Odpowiedź okna ma komunikat w języku klienta, datę końca w meta
+// i Retry-After; testerzy z listy okna przechodzą dalej.
+func checkMaintenance(c *fiber.Ctx, container *di.Container, route *routing.Route) error {
+	if container.Overrides.Maintenance(route) != nil {
+		return apperr.ErrMaintenance
+	}
+
+	now := time.Now()
+	w := container.Maintenance.Active(route, now)
+	if w == nil {
+		return nil
+	}
+
+	if rc, ok := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext); ok && rc != nil && w.Admits(rc.UserID) {
+		shared.AddLogField(c, zap.String("maintenance_tester", w.ID))
+		return nil
+	}
+
+	lang, message := w.Localize(c.Get(fiber.HeaderAcceptLanguage))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(w.End.Sub(now).Seconds())+1))
+	c.Set(fiber.HeaderContentLanguage, lang)
+	c.Vary(fiber.HeaderAcceptLanguage)
+	return apperr.ErrMaintenance.WithMessage(message).
+		WithMeta("window", w.ID).
+		WithMeta("until", w.End.UTC())
+}
@@ -0,0 +1,30 @@
+package router
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// networkAllowed – czy klient pasuje do listy dozwolonych sieci / krajów trasy
+// (routes.yaml: network). Odmowa trafia do audytu – to trasy wrażliwe (np. audyt).
+func networkAllowed(c *fiber.Ctx, container *di.Container, route *routing.Route) bool {
+	addr, ok := netguard.ClientAddr(c)
+	country, _ := c.Locals(netguard.LocalCountry).(string)
+	if ok && route.Allowlist.Allows(addr, country) {
+		return true
+	}
+
+	var userID *uuid.UUID
+	if rc, ok := c.Locals(reqctx.FiberRequestContextKey).(*reqctx.RequestContext); ok && rc != nil {
+		userID = rc.UserID
+	}
+	container.Network.RecordDenied(c.UserContext(), userID, reqctx.ClientIP(c), map[string]any{
+		"route":   route.Key(),
+		"country": country,
+	})
+	return false
+}
@@ -0,0 +1,120 @@
+package router
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/openapi"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+)
+
+// Większe odpowiedzi JSON przechodzą bez walidacji (nie buforujemy ich tylko po to)
+const maxValidatedBody = 1 << 20
+
+// validateRequest sprawdza żądanie względem specyfikacji OpenAPI upstreamu trasy.
+// Zwraca dopasowaną operację (do walidacji odpowiedzi) albo nil, gdy serwis lub
+// operacja nie mają opisu.
+func validateRequest(c *fiber.Ctx, container *di.Container, route *routing.Route) (*openapi.Operation, error) {
+	if !container.OpenAPI.Covers(route.Upstream) {
+		return nil, nil
+	}
+	req, err := specRequest(c)
+	if err != nil {
+		return nil, apperr.ErrInvalidRequest
+	}
+	op := container.OpenAPI.Match(route.Upstream, req)
+	if op == nil {
+		return nil, nil
+	}
+
+	var body []byte
+	var hasBody bool
+	if op.BuffersBody() {
+		// Walidacja schematem wymaga całego body – buforujemy je (z limitem trasy)
+		if err := bufferBody(c, route.BodyLimit); err != nil {
+			return nil, err
+		}
+		body = c.Body()
+		hasBody = len(body) > 0
+	} else {
+		// Body strumieniowane (multipart, binarne) – nie czytamy go, -1 = chunked
+		length := c.Request().Header.ContentLength()
+		hasBody = length > 0 || length == -1
+	}
+
+	if violations := op.ValidateRequest(c.UserContext(), body, hasBody); violations != nil {
+		appErr := *apperr.ErrValidationFailed
+		appErr.Meta = violations
+		return nil, &appErr
+	}
+	return op, nil
+}
+
+// checkResponse waliduje odpowiedź upstreamu (tylko poza produkcją). Naruszenie kontraktu
+// jest logowane i zamienia odpowiedź na błąd z listą pól – rozjazd ma być widoczny od razu.
+func checkResponse(c *fiber.Ctx, op *openapi.Operation, resp *http.Response) (*http.Response, error) {
+	var body []byte
+	if isJSON(resp.Header.Get(fiber.HeaderContentType)) {
+		var buffered bool
+		var err error
+		resp, body, buffered, err = bufferResponseBody(resp, maxValidatedBody)
+		if err != nil {
+			return nil, err
+		}
+		if !buffered {
+			return resp, nil
+		}
+	}
+
+	violations := op.ValidateResponse(c.UserContext(), resp.StatusCode, resp.Header, body)
+	if violations == nil {
+		return resp, nil
+	}
+	resp.Body.Close()
+
+	shared.GetLogger().ErrorMap("Upstream response violates OpenAPI spec", map[string]any{
+		"method":     c.Method(),
+		"path":       c.Path(),
+		"status":     resp.StatusCode,
+		"violations": violations,
+	})
+	appErr := *apperr.ErrUpstreamContractViolation
+	appErr.Meta = violations
+	return nil, &appErr
+}
+
+// serveDocs – GET /openapi.json złożony z dokumentów serwisów (openapi.Aggregator)
+func serveDocs(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		doc, err := container.Docs.Document(c.UserContext())
+		if err != nil {
+			shared.GetLogger().ErrorObj("OpenAPI document build failed", err)
+			return apperr.SendAppError(c, apperr.ErrInternal)
+		}
+		c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+		return c.Send(doc)
+	}
+}
+
+// specRequest – żądanie klienta w postaci net/http (bez body) do dopasowania operacji
+func specRequest(c *fiber.Ctx) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(c.UserContext(), c.Method(), c.OriginalURL(), http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Host = c.Hostname()
+	c.Request().Header.VisitAll(func(k, v []byte) {
+		req.Header.Add(string(k), string(v))
+	})
+	return req, nil
+}
+
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && (mediaType == fiber.MIMEApplicationJSON || strings.HasSuffix(mediaType, "+json"))
+}
@@ -4,183 +4,400 @@ import (
 	"bytes"
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/proxy"
 	"github.com/zerodayz7/platform/pkg/constants"
 	reqctx "github.com/zerodayz7/platform/pkg/context"
 	apperr "github.com/zerodayz7/platform/pkg/errors"
 	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/canary"
 	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/openapi"
+	"github.com/zerodayz7/platform/services/gateway/internal/upstream"
 )
 
-func ReverseProxyFiber(container *di.Container, target string) fiber.Handler {
-	return func(c *fiber.Ctx) error {
-		// 1. Pobieramy Twój kontekst (ID requestu itp.)
-		ctx, _ := c.Locals("requestContext").(*reqctx.RequestContext)
-
-		// 2. Przygotowujemy URL docelowy (np. http://auth-service:8080/auth/login)
-		// c.Path() zawiera pełną ścieżkę
-		url := target + c.Path()
-
-		// 3. Dodajemy Twoje customowe nagłówki przed wysłaniem
-		if ctx != nil {
-			c.Request().Header.Set(constants.HeaderRequestID, ctx.RequestID)
-			c.Request().Header.Set(constants.HeaderXForwardedFor, ctx.IP)
-			c.Request().Header.Set(constants.HeaderXRealIP, ctx.IP)
-		}
+// proxyOptions – parametry przekazania żądania (z trasy routes.yaml)
+type proxyOptions struct {
+	Target    string        // nazwa serwisu lub URL (klucz puli instancji)
+	Timeout   time.Duration // limit czasu do otrzymania nagłówków odpowiedzi
+	BodyLimit int64         // limit body w bajtach
+	// PassHeaders – dodatkowe nagłówki klienta (tylko trasy publiczne)
+	PassHeaders []string
+	// Cache – zapis odpowiedzi w cache (trasy z `cache:` po chybieniu)
+	Cache *cacheTarget
+	// PurgeTags – tagi cache użytkownika unieważniane po udanym zapisie
+	PurgeTags []string
+	// Operation – operacja OpenAPI, względem której walidujemy odpowiedź (poza produkcją)
+	Operation *openapi.Operation
+	// Idempotency – klucz Idempotency-Key zajęty przez żądanie (wynik zostanie zapisany)
+	Idempotency *idempotencyClaim
+	// PathPrefix – doklejany przed ścieżkę żądania w upstreamie (routes.yaml: versions.<v>.prefix)
+	PathPrefix string
+	// Rename – zmiana nazw pól odpowiedzi JSON dla wersji API klienta
+	Rename map[string]string
+	// Canary – przydział do wariantu trasy z canary (wynik trafia do statystyk rollbacku)
+	Canary *canary.Decision
+	// WebSession – routes.yaml: web_session (tylko przy WEB_SESSION_ENABLED)
+	WebSession string
+}
 
-		// Wymusić przekazanie User-Agent i Fingerprint,
-		// Fiber domyślnie przekazuje większość nagłówków klienta.
+func proxyPublic(c *fiber.Ctx, container *di.Container, opts proxyOptions) error {
+	log := shared.GetLogger()
+	ctx, _ := c.Locals("requestContext").(*reqctx.RequestContext)
 
-		// 4. Wykonujemy Proxy
-		return proxy.Do(c, url)
+	req, err := prepareProxyRequest(c, opts)
+	if err != nil {
+		return apperr.SendAppError(c, err)
 	}
-}
 
-// W internal/router/proxy.go popraw ReverseProxy:
-func ReverseProxy(container *di.Container, target string) fiber.Handler {
-	log := shared.GetLogger()
-	return func(c *fiber.Ctx) error {
-		ctx, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	clientHeaders := []string{
+		"Content-Type",
+		"Accept",
+		"User-Agent",
+		"X-Device-Fingerprint",
+	}
+	clientHeaders = append(clientHeaders, opts.PassHeaders...)
 
-		req, err := prepareProxyRequest(c, target)
-		if err != nil {
-			return err
+	for _, h := range clientHeaders {
+		if v := c.Get(h); v != "" {
+			req.Header.Set(h, v)
 		}
+	}
 
-		clientHeaders := []string{
-			"Content-Type",
-			"Accept",
-			"User-Agent",
-			"X-Device-Fingerprint",
-		}
+	if ctx != nil {
+		req.Header.Set(constants.HeaderRequestID, ctx.RequestID)
+		req.Header.Set(constants.HeaderXForwardedFor, ctx.IP)
+		req.Header.Set(constants.HeaderXRealIP, ctx.IP)
 
-		for _, h := range clientHeaders {
-			if v := c.Get(h); v != "" {
-				req.Header.Set(h, v)
-			}
+		// Kontekst bez użytkownika – serwis w trybie INTERNAL_STRICT przyjmuje też trasy publiczne
+		if err := attachContext(req, container, ctx, opts.Target); err != nil {
+			log.ErrorObj("Failed to sign request context", err)
+			return apperr.SendAppError(c, apperr.ErrInternal)
 		}
+	}
 
-		if ctx != nil {
-			req.Header.Set(constants.HeaderRequestID, ctx.RequestID)
-			req.Header.Set(constants.HeaderXForwardedFor, ctx.IP)
-			req.Header.Set(constants.HeaderXRealIP, ctx.IP)
-		}
+	return executeProxyRequest(c, container, opts, req, log)
+}
+
+func proxySecure(c *fiber.Ctx, container *di.Container, opts proxyOptions) error {
+	req, err := secureRequest(c, container, opts)
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+	return executeProxyRequest(c, container, opts, req, shared.GetLogger())
+}
 
-		return executeProxyRequest(c, container, req, log)
+// secureRequest buduje żądanie do upstreamu z podpisanym RequestContext
+func secureRequest(c *fiber.Ctx, container *di.Container, opts proxyOptions) (*http.Request, error) {
+	// ---  Budujemy request do upstream ---
+	req, err := prepareProxyRequest(c, opts)
+	if err != nil {
+		return nil, err
 	}
+	if err := signRequest(c, container, req, opts.Target); err != nil {
+		return nil, err
+	}
+	return req, nil
 }
 
-func ReverseProxySecure(container *di.Container, target string) fiber.Handler {
+// signRequest dokłada do żądania nagłówki klienta z whitelisty i RequestContext podpisany
+// dla serwisu target
+func signRequest(c *fiber.Ctx, container *di.Container, req *http.Request, target string) error {
 	log := shared.GetLogger()
 
-	return func(c *fiber.Ctx) error {
-		// --- Pobieramy RequestContext (JEDYNE źródło prawdy) ---
-		ctx, ok := c.Locals("requestContext").(*reqctx.RequestContext)
-		if !ok || ctx == nil {
-			log.Warn("Missing request context")
-			return fiber.ErrUnauthorized
-		}
+	// --- Pobieramy RequestContext (JEDYNE źródło prawdy) ---
+	ctx, ok := c.Locals("requestContext").(*reqctx.RequestContext)
+	if !ok || ctx == nil {
+		log.Warn("Missing request context")
+		return apperr.ErrUnauthorized
+	}
 
-		// ---  Budujemy request do upstream ---
-		req, err := prepareProxyRequest(c, target)
-		if err != nil {
-			return err
-		}
+	// ---  Whitelist nagłówków z klienta (MINIMUM) ---
+	clientHeaders := []string{
+		"Content-Type",
+		"Accept",
+		"User-Agent",
+		"X-Device-Fingerprint",
+	}
 
-		// ---  Whitelist nagłówków z klienta (MINIMUM) ---
-		clientHeaders := []string{
-			"Content-Type",
-			"Accept",
-			"User-Agent",
-			"X-Device-Fingerprint",
+	for _, h := range clientHeaders {
+		if v := c.Get(h); v != "" {
+			req.Header.Set(h, v)
 		}
+	}
 
-		for _, h := range clientHeaders {
-			if v := c.Get(h); v != "" {
-				req.Header.Set(h, v)
-			}
-		}
+	// --- Nagłówki kontrolowane  ---
+	req.Header.Set(constants.HeaderRequestID, ctx.RequestID)
+	req.Header.Set(constants.HeaderXForwardedFor, ctx.IP)
+	req.Header.Set(constants.HeaderXRealIP, ctx.IP)
 
-		// --- Nagłówki kontrolowane  ---
-		req.Header.Set(constants.HeaderRequestID, ctx.RequestID)
-		req.Header.Set(constants.HeaderXForwardedFor, ctx.IP)
-		req.Header.Set(constants.HeaderXRealIP, ctx.IP)
+	if ctx.UserID != nil {
+		req.Header.Set("X-User-Id", ctx.UserID.String())
+	}
+	if ctx.SessionID != "" {
+		req.Header.Set("X-Session-Id", ctx.SessionID)
+	}
 
-		if ctx.UserID != nil {
-			req.Header.Set("X-User-Id", ctx.UserID.String())
-		}
-		if ctx.SessionID != "" {
-			req.Header.Set("X-Session-Id", ctx.SessionID)
-		}
+	if ctx.DeviceID != "" {
+		req.Header.Set(constants.HeaderDeviceFingerprint, ctx.DeviceID)
+		req.Header.Set("X-Device-Id", ctx.DeviceID)
+	}
 
-		if ctx.DeviceID != "" {
-			req.Header.Set(constants.HeaderDeviceFingerprint, ctx.DeviceID)
-			req.Header.Set("X-Device-Id", ctx.DeviceID)
-		}
+	// ---  Zero trust: auth-related ---
+	req.Header.Del(constants.HeaderAuth)
+	req.Header.Del(constants.HeaderCookie)
 
-		// ---  Zero trust: auth-related ---
-		req.Header.Del(constants.HeaderAuth)
-		req.Header.Del(constants.HeaderCookie)
+	// --- podpisany kontekst ---
+	if err := attachContext(req, container, ctx, target); err != nil {
+		log.ErrorObj("Failed to encode request context", err)
+		return apperr.ErrInternal
+	}
 
-		// --- podpisany kontekst ---
-		payload, err := reqctx.Encode(*ctx)
-		if err != nil {
-			log.ErrorObj("Failed to encode request context", err)
-			return apperr.SendAppError(c, apperr.ErrInternal)
-		}
-		sig := reqctx.Sign(payload, container.InternalSecret)
-		req.Header.Set(constants.HeaderInternalContext, base64.StdEncoding.EncodeToString(payload))
-		req.Header.Set(constants.HeaderInternalSignature, sig)
+	return nil
+}
 
-		return executeProxyRequest(c, container, req, log)
+// attachContext dokłada kopertę kontekstu: ważną INTERNAL_CONTEXT_TTL, z nonce żądania
+// i odbiorcą target (nazwa upstreamu z routes.yaml – INTERNAL_AUDIENCE serwisu)
+func attachContext(req *http.Request, container *di.Container, ctx *reqctx.RequestContext, target string) error {
+	payload, sig, err := container.InternalSigner.Seal(*ctx, target, container.Config.Internal.ContextTTL)
+	if err != nil {
+		return err
 	}
+	req.Header.Set(constants.HeaderInternalContext, base64.StdEncoding.EncodeToString(payload))
+	req.Header.Set(constants.HeaderInternalSignature, sig)
+	return nil
 }
 
 // --- FUNKCJE POMOCNICZE (DRY) ---
 
-func prepareProxyRequest(c *fiber.Ctx, target string) (*http.Request, error) {
-	body := c.Body()
+// Body do tego rozmiaru (ze znaną długością) jest buforowane – można je ponowić
+// przy retry; większe oraz chunked są strumieniowane prosto do upstreamu.
+const maxBufferedBody = 64 << 10
 
-	url := target + c.OriginalURL()
+var errBodyTooLarge = errors.New("request body exceeds route limit")
+
+// prepareProxyRequest buduje żądanie ze ścieżką względną – instancję upstreamu
+// (a więc schemat i host) wybiera upstream.Client przy każdej próbie.
+func prepareProxyRequest(c *fiber.Ctx, opts proxyOptions) (*http.Request, error) {
+	bodyLimit := opts.BodyLimit
+	contentLength := int64(c.Request().Header.ContentLength())
+	if contentLength > bodyLimit {
+		return nil, apperr.ErrPayloadTooLarge
+	}
+
+	var body io.Reader
+	stream := c.Context().RequestBodyStream()
+	if stream == nil || (contentLength >= 0 && contentLength <= maxBufferedBody) {
+		body = bytes.NewReader(c.Body())
+	} else {
+		// Strumień z backpressure: czytamy od klienta dopiero, gdy upstream przyjmuje dane
+		body = &limitedBody{r: stream, remaining: bodyLimit}
+	}
 
 	req, err := http.NewRequestWithContext(
 		c.UserContext(),
 		string(c.Method()),
-		url,
-		bytes.NewReader(body),
+		opts.PathPrefix+c.OriginalURL(),
+		body,
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// Zachowujemy Content-Length; -1 (chunked) zostaje przekazane jako chunked
+	if _, streamed := body.(*limitedBody); streamed {
+		req.ContentLength = contentLength
+		if contentLength == 0 {
+			req.Body = http.NoBody
+		}
+	}
+
 	return req, nil
 }
 
-func executeProxyRequest(c *fiber.Ctx, container *di.Container, req *http.Request, log *shared.Logger) error {
-	resp, err := container.HTTPClient.Do(req)
+// limitedBody egzekwuje limit trasy dla body bez znanej długości (chunked)
+type limitedBody struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.remaining < 0 {
+		return 0, errBodyTooLarge
+	}
+	if int64(len(p)) > b.remaining+1 {
+		p = p[:b.remaining+1]
+	}
+	n, err := b.r.Read(p)
+	b.remaining -= int64(n)
+	if b.remaining < 0 {
+		return 0, errBodyTooLarge
+	}
+	return n, err
+}
+
+func executeProxyRequest(c *fiber.Ctx, container *di.Container, opts proxyOptions, req *http.Request, log *shared.Logger) error {
+	resp, err := container.Upstreams.Do(opts.Target, req, balanceKey(c), opts.Timeout)
+	recordVariant(opts, resp, err)
 	if err != nil {
-		if errors.Is(err, context.DeadlineExceeded) {
-			return apperr.SendAppError(c, apperr.ErrUpstreamTimeout)
+		return upstreamError(c, container, opts.Target, err, log)
+	}
+	// Odmowa podpisanego kontekstu nie jest wynikiem żądania – klucz Idempotency-Key zostaje zwolniony
+	if resp, err = checkContextRejection(c, opts.Target, resp, log); err != nil {
+		return upstreamError(c, container, opts.Target, err, log)
+	}
+	status := resp.StatusCode
+
+	// Walidacja przed zapisem – odpowiedź niezgodna z kontraktem nie trafia do cache ani pod klucz.
+	// Operacja jest już wykonana, więc błąd obróbki zamyka klucz bez odpowiedzi (nie zwalnia go).
+	if opts.Operation != nil {
+		if resp, err = checkResponse(c, opts.Operation, resp); err != nil {
+			return transformError(c, container, opts, status, err, log)
+		}
+	}
+	// Zapis (cache, Idempotency-Key) przechowuje odpowiedź już w kształcie wersji klienta
+	if len(opts.Rename) > 0 {
+		if resp, err = renameResponseFields(resp, opts.Rename); err != nil {
+			return transformError(c, container, opts, status, err, log)
+		}
+	}
+	if opts.WebSession != "" {
+		if resp, err = applyWebSession(c, container, opts.WebSession, resp); err != nil {
+			return transformError(c, container, opts, status, err, log)
 		}
+	}
+	if opts.Idempotency != nil {
+		if resp, err = completeIdempotency(c, container, opts.Idempotency, resp); err != nil {
+			return upstreamError(c, container, opts.Target, err, log)
+		}
+	}
+	if opts.Cache != nil {
+		if resp, err = storeResponse(c, container, opts.Cache, resp); err != nil {
+			return upstreamError(c, container, opts.Target, err, log)
+		}
+	}
+	if len(opts.PurgeTags) > 0 && c.Method() != fiber.MethodGet && c.Method() != fiber.MethodHead {
+		purgeAfterWrite(c, container, opts.PurgeTags, resp.StatusCode)
+	}
+
+	return relayResponse(c, resp)
+}
 
-		log.ErrorObj("Upstream request failed", err)
+// transformError – błąd obróbki odpowiedzi upstreamu po wykonaniu operacji
+func transformError(c *fiber.Ctx, container *di.Container, opts proxyOptions, status int, err error, log *shared.Logger) error {
+	if opts.Idempotency != nil {
+		opts.Idempotency.settleUnreplayable(c, container, status)
+	}
+	return upstreamError(c, container, opts.Target, err, log)
+}
 
-		return apperr.SendAppError(c, apperr.ErrUpstreamUnreachable)
+// recordVariant zapisuje wynik żądania wariantu canary. Błąd to 5xx albo błąd transportu;
+// żądanie przerwane przez klienta nic nie mówi o wersji upstreamu.
+func recordVariant(opts proxyOptions, resp *http.Response, err error) {
+	if opts.Canary == nil || errors.Is(err, context.Canceled) {
+		return
 	}
-	defer resp.Body.Close()
+	opts.Canary.Record(err != nil || resp.StatusCode >= fiber.StatusInternalServerError)
+}
 
-	if resp.StatusCode == fiber.StatusForbidden {
-		log.Error("Security Alert: Upstream rejected internal signature or context")
-		return apperr.SendAppError(c, apperr.ErrInternal)
+// Kody błędów InternalAuthMiddleware (pkg/middleware) – serwis odrzucił kontekst podpisany przez gateway
+const internalRejectionPrefix = "INTERNAL_"
+
+// Odmowa middleware to krótki JSON z kodem; większe body na pewno nią nie jest
+const maxRejectionBody = 4 << 10
+
+// checkContextRejection wykrywa 401/403 z kodem INTERNAL_*. Taka odmowa oznacza rozjazd
+// kluczy, zegarów albo INTERNAL_AUDIENCE między gatewayem a serwisem, a nie błąd klienta –
+// przekazane 401 wylogowałoby użytkownika. Klient dostaje 502, a my alert w logach.
+func checkContextRejection(c *fiber.Ctx, target string, resp *http.Response, log *shared.Logger) (*http.Response, error) {
+	if resp.StatusCode != fiber.StatusUnauthorized && resp.StatusCode != fiber.StatusForbidden {
+		return resp, nil
+	}
+	if !isJSON(resp.Header.Get(fiber.HeaderContentType)) {
+		return resp, nil
 	}
 
+	resp, body, buffered, err := bufferResponseBody(resp, maxRejectionBody)
+	if err != nil || !buffered {
+		return resp, err
+	}
+	code := internalRejectionCode(resp.StatusCode, body)
+	if code == "" {
+		return resp, nil
+	}
+
+	log.ErrorMap("Security Alert: Upstream rejected internal context", map[string]any{
+		"upstream": target,
+		"code":     code,
+		"status":   resp.StatusCode,
+		"path":     c.Path(),
+	})
+	return nil, apperr.ErrUpstreamContextRejected
+}
+
+// internalRejectionCode zwraca kod INTERNAL_* z odpowiedzi 401/403 ("" – zwykła odmowa serwisu)
+func internalRejectionCode(status int, body []byte) string {
+	if status != fiber.StatusUnauthorized && status != fiber.StatusForbidden {
+		return ""
+	}
+	var payload struct {
+		Code string `json:"code"`
+	}
+	if json.Unmarshal(body, &payload) != nil || !strings.HasPrefix(payload.Code, internalRejectionPrefix) {
+		return ""
+	}
+	return payload.Code
+}
+
+// upstreamError mapuje błąd upstream.Client na odpowiedź dla klienta
+func upstreamError(c *fiber.Ctx, container *di.Container, target string, err error, log *shared.Logger) error {
+	// Błąd aplikacyjny ustalony po drodze (np. naruszenie kontraktu OpenAPI)
+	var appErr *apperr.AppError
+	if errors.As(err, &appErr) {
+		return apperr.SendAppError(c, appErr)
+	}
+	if errors.Is(err, errBodyTooLarge) {
+		return apperr.SendAppError(c, apperr.ErrPayloadTooLarge)
+	}
+
+	// Breaker otwarty – szybka odmowa bez obciążania upstreamu
+	if errors.Is(err, upstream.ErrBreakerOpen) {
+		if up, _ := container.Upstreams.Get(target); up != nil {
+			if wait := up.Breaker.RetryAfter(); wait > 0 {
+				c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(wait.Seconds())+1))
+			}
+		}
+	}
+	return apperr.SendAppError(c, transportError(target, err, log))
+}
+
+// transportError mapuje błąd wywołania upstream.Client (bez odpowiedzi) na błąd aplikacyjny
+func transportError(target string, err error, log *shared.Logger) *apperr.AppError {
+	switch {
+	case errors.Is(err, upstream.ErrBreakerOpen):
+		return apperr.ErrUpstreamUnavailable
+	case errors.Is(err, upstream.ErrNoHealthyInstance):
+		log.WarnMap("No healthy upstream instance", map[string]any{"upstream": target})
+		return apperr.ErrUpstreamUnavailable
+	case errors.Is(err, context.DeadlineExceeded):
+		return apperr.ErrUpstreamTimeout
+	}
+
+	log.ErrorObj("Upstream request failed", err)
+	return apperr.ErrUpstreamUnreachable
+}
+
+// relayResponse przekazuje odpowiedź upstreamu klientowi (body strumieniowo)
+func relayResponse(c *fiber.Ctx, resp *http.Response) error {
 	for k, v := range resp.Header {
-		if isHopByHop(k) {
+		if isHopByHop(k) || k == fiber.HeaderContentLength || k == constants.HeaderCacheTag {
 			continue
 		}
 		for _, vv := range v {
@@ -190,8 +407,26 @@ func executeProxyRequest(c *fiber.Ctx, container *di.Container, req *http.Reques
 
 	c.Status(resp.StatusCode)
 
-	_, err = io.Copy(c.Response().BodyWriter(), resp.Body)
-	return err
+	// Odpowiedź jest strumieniowana do klienta (fasthttp zamyka body po wysłaniu).
+	// Znana długość => Content-Length, nieznana (-1) => chunked.
+	if resp.ContentLength == 0 {
+		resp.Body.Close()
+		return nil
+	}
+	c.Response().SetBodyStream(resp.Body, int(resp.ContentLength))
+	return nil
+}
+
+// balanceKey – klucz consistent hashing: ID użytkownika, a dla żądań anonimowych IP
+func balanceKey(c *fiber.Ctx) string {
+	ctx, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	if ctx == nil {
+		return reqctx.ClientIP(c)
+	}
+	if ctx.UserID != nil {
+		return ctx.UserID.String()
+	}
+	return ctx.IP
 }
 
 // Pomocnicza funkcja do filtrowania nagłówków technicznych
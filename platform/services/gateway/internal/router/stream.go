@@ -0,0 +1,234 @@
+package router
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	reqctx "github.com/zerodayz7/platform/pkg/context"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+)
+
+// Nagłówki handshake WebSocket przekazywane do upstreamu
+var websocketHeaders = []string{
+	"Sec-WebSocket-Key",
+	"Sec-WebSocket-Version",
+	"Sec-WebSocket-Protocol",
+	"Sec-WebSocket-Extensions",
+	"Origin",
+}
+
+// proxyStream obsługuje trasy `stream: true` – WebSocket (Upgrade) oraz SSE
+// (text/event-stream). JWT, sesja Redis i fingerprint zostały sprawdzone przez
+// middleware jak dla każdej trasy chronionej, a upstream dostaje podpisany kontekst.
+// Po zestawieniu połączenia pilnuje go container.Streams (zakończenie sesji = zerwanie).
+func proxyStream(c *fiber.Ctx, container *di.Container, opts proxyOptions) error {
+	log := shared.GetLogger()
+
+	req, err := secureRequest(c, container, opts)
+	if err != nil {
+		return apperr.SendAppError(c, err)
+	}
+
+	websocket := isWebSocketUpgrade(c)
+	if websocket {
+		req.Header.Set(fiber.HeaderConnection, "Upgrade")
+		req.Header.Set(fiber.HeaderUpgrade, "websocket")
+		for _, h := range websocketHeaders {
+			if v := c.Get(h); v != "" {
+				req.Header.Set(h, v)
+			}
+		}
+	}
+
+	// timeout trasy obejmuje tylko handshake / nagłówki odpowiedzi
+	resp, err := container.Upstreams.Do(opts.Target, req, balanceKey(c), opts.Timeout)
+	recordVariant(opts, resp, err)
+	if err != nil {
+		return upstreamError(c, container, opts.Target, err, log)
+	}
+	if resp, err = checkContextRejection(c, opts.Target, resp, log); err != nil {
+		return upstreamError(c, container, opts.Target, err, log)
+	}
+
+	switch {
+	case websocket && resp.StatusCode == fiber.StatusSwitchingProtocols:
+		if _, ok := resp.Body.(io.ReadWriteCloser); !ok {
+			resp.Body.Close()
+			log.Error("Upstream switched protocols without a writable connection")
+			return apperr.SendAppError(c, apperr.ErrUpstreamUnreachable)
+		}
+		return hijackStream(c, container, opts, resp, true)
+	case !websocket && resp.StatusCode == fiber.StatusOK && isEventStream(resp.Header):
+		return hijackStream(c, container, opts, resp, false)
+	}
+
+	// Odmowa upstreamu (np. 401, 404) albo zwykła odpowiedź – przekazujemy bez zmian
+	return relayResponse(c, resp)
+}
+
+// hijackStream przejmuje połączenie klienta. Nagłówki odpowiedzi składamy w c.Response(),
+// żeby zawierały też CORS / helmet ustawione przez middleware, i wysyłamy je ręcznie –
+// fasthttp nie wysyła już własnej odpowiedzi ani nie pilnuje Read/WriteTimeout.
+func hijackStream(c *fiber.Ctx, container *di.Container, opts proxyOptions, resp *http.Response, websocket bool) error {
+	rc, _ := c.Locals("requestContext").(*reqctx.RequestContext)
+	if rc == nil || rc.SessionID == "" {
+		resp.Body.Close()
+		return apperr.SendAppError(c, apperr.ErrUnauthorized)
+	}
+
+	for k, v := range resp.Header {
+		if isHopByHop(k) || k == fiber.HeaderContentLength {
+			continue
+		}
+		for _, vv := range v {
+			c.Set(k, vv)
+		}
+	}
+	c.Status(resp.StatusCode)
+
+	kind := "sse"
+	if websocket {
+		kind = "websocket"
+		c.Set(fiber.HeaderConnection, "Upgrade")
+		c.Set(fiber.HeaderUpgrade, "websocket")
+	} else {
+		// Chunked bez buforowania po drodze (nginx: X-Accel-Buffering)
+		c.Response().Header.SetContentLength(-1)
+		c.Set(fiber.HeaderCacheControl, "no-cache")
+		c.Set("X-Accel-Buffering", "no")
+	}
+	head := append([]byte(nil), c.Response().Header.Header()...)
+
+	fields := map[string]any{
+		"kind":       kind,
+		"path":       c.Path(),
+		"upstream":   opts.Target,
+		"sid":        rc.SessionID,
+		"request_id": rc.RequestID,
+	}
+	if rc.UserID != nil {
+		fields["uid"] = rc.UserID.String()
+	}
+
+	c.Context().HijackSetNoResponse(true)
+	c.Context().Hijack(func(conn net.Conn) {
+		log := shared.GetLogger()
+		defer resp.Body.Close()
+
+		ctx, untrack := container.Streams.Track(rc.SessionID)
+		defer untrack()
+
+		// Deadline'y ustawione przez fasthttp dla zwykłych żądań nie dotyczą połączeń długotrwałych
+		_ = conn.SetDeadline(time.Time{})
+		if _, err := conn.Write(head); err != nil {
+			return
+		}
+
+		// Zakończenie sesji / shutdown – przerywamy blokujące operacje po obu stronach.
+		// Czekamy na tę gorutynę przed wyjściem: po powrocie fasthttp zamyka i odkłada conn do puli.
+		relayed := make(chan struct{})
+		watched := make(chan struct{})
+		go func() {
+			defer close(watched)
+			select {
+			case <-ctx.Done():
+				_ = conn.SetDeadline(time.Now())
+				resp.Body.Close()
+			case <-relayed:
+			}
+		}()
+
+		log.InfoMap("Stream opened", fields)
+		started := time.Now()
+
+		if websocket {
+			tunnel(conn, resp.Body.(io.ReadWriteCloser))
+		} else {
+			relayEvents(conn, resp.Body)
+		}
+		close(relayed)
+		<-watched
+
+		fields["duration"] = time.Since(started).String()
+		if cause := context.Cause(ctx); cause != nil {
+			fields["reason"] = cause.Error()
+		}
+		log.InfoMap("Stream closed", fields)
+	})
+	return nil
+}
+
+// tunnel przekazuje ramki WebSocket w obie strony, aż jedna ze stron się rozłączy
+func tunnel(client net.Conn, upstream io.ReadWriteCloser) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(upstream, client)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+
+	<-done
+	_ = client.SetDeadline(time.Now())
+	upstream.Close()
+	<-done
+}
+
+// relayEvents przekazuje strumień SSE jako chunked – każdy odczyt od razu trafia do klienta
+func relayEvents(client net.Conn, events io.ReadCloser) {
+	// Klient po żądaniu już nic nie wysyła – EOF oznacza rozłączenie
+	disconnected := make(chan struct{})
+	go func() {
+		_, _ = io.Copy(io.Discard, client)
+		events.Close()
+		close(disconnected)
+	}()
+	defer func() {
+		_ = client.SetReadDeadline(time.Now())
+		<-disconnected
+	}()
+
+	w := bufio.NewWriter(client)
+	chunked := httputil.NewChunkedWriter(w)
+	buf := make([]byte, 32<<10)
+	for {
+		n, err := events.Read(buf)
+		if n > 0 {
+			if _, werr := chunked.Write(buf[:n]); werr != nil {
+				return
+			}
+			if werr := w.Flush(); werr != nil {
+				return
+			}
+		}
+		if err != nil {
+			if err == io.EOF {
+				// Upstream zakończył strumień – zamykamy go poprawnie po stronie klienta
+				_ = chunked.Close()
+				_, _ = w.WriteString("\r\n")
+				_ = w.Flush()
+			}
+			return
+		}
+	}
+}
+
+func isWebSocketUpgrade(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(fiber.HeaderUpgrade), "websocket") &&
+		strings.Contains(strings.ToLower(c.Get(fiber.HeaderConnection)), "upgrade")
+}
+
+func isEventStream(h http.Header) bool {
+	return strings.HasPrefix(h.Get(fiber.HeaderContentType), "text/event-stream")
+}
@@ -0,0 +1,89 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/middleware"
+)
+
+// Większe odpowiedzi są przekazywane bez zmiany nazw pól
+const maxRenamedBody = 1 << 20
+
+// renameResponseFields dostosowuje udaną odpowiedź JSON do wersji API klienta
+// (routes.yaml: versions.<v>.rename). Błędy (AppError) i inne typy treści przechodzą bez zmian.
+func renameResponseFields(resp *http.Response, rename map[string]string) (*http.Response, error) {
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 || !isJSON(resp.Header.Get(fiber.HeaderContentType)) {
+		return resp, nil
+	}
+
+	resp, body, buffered, err := bufferResponseBody(resp, maxRenamedBody)
+	if err != nil || !buffered {
+		if err == nil {
+			shared.GetLogger().WarnMap("Response too large to rename fields", map[string]any{"limit": maxRenamedBody})
+		}
+		return resp, err
+	}
+
+	// UseNumber – liczby (np. identyfikatory int64) przechodzą bez utraty precyzji
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		// Upstream zadeklarował JSON, ale body nim nie jest – przekazujemy bez zmian
+		return resp, nil
+	}
+
+	// Stała kolejność – wynik nie zależy od iteracji po mapie
+	for _, from := range slices.Sorted(maps.Keys(rename)) {
+		renameField(doc, strings.Split(from, "."), rename[from])
+	}
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(out))
+	resp.ContentLength = int64(len(out))
+	resp.Header.Del(fiber.HeaderContentLength)
+	// Walidator ETag upstreamu dotyczy innego body
+	resp.Header.Del(fiber.HeaderETag)
+	return resp, nil
+}
+
+// renameField zmienia nazwę pola pod ścieżką path; tablice są przechodzone element po elemencie
+func renameField(node any, path []string, to string) {
+	switch n := node.(type) {
+	case []any:
+		for _, item := range n {
+			renameField(item, path, to)
+		}
+	case map[string]any:
+		value, ok := n[path[0]]
+		if !ok {
+			return
+		}
+		if len(path) > 1 {
+			renameField(value, path[1:], to)
+			return
+		}
+		delete(n, path[0])
+		n[to] = value
+	}
+}
+
+// versionedPath – ścieżka z wersją API klienta. Klucze cache i odcisk Idempotency-Key
+// muszą ją uwzględniać: po usunięciu prefiksu /v1 i /v2 wyglądają tak samo.
+func versionedPath(c *fiber.Ctx, path string) string {
+	if version := middleware.APIVersionOf(c); version != nil {
+		return "/" + version.Name + path
+	}
+	return path
+}
@@ -0,0 +1,92 @@
+package router
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/gofiber/fiber/v2"
+	apperr "github.com/zerodayz7/platform/pkg/errors"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/di"
+	"github.com/zerodayz7/platform/services/gateway/internal/routing"
+	"github.com/zerodayz7/platform/services/gateway/internal/websession"
+)
+
+// Odpowiedzi logowania są małe – limit chroni tylko przed buforowaniem czegoś nieoczekiwanego
+const maxSessionBody = 64 << 10
+
+// applyWebSession obsługuje trasy z web_session. issue: tokeny z udanej odpowiedzi (jeśli są –
+// krok 2FA ich nie ma) są zamieniane na ciasteczko sesji, a klient dostaje tylko token CSRF.
+// end: udane wylogowanie sesji z ciasteczka czyści ciasteczka.
+func applyWebSession(c *fiber.Ctx, container *di.Container, kind string, resp *http.Response) (*http.Response, error) {
+	web := container.WebSessions
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp, nil
+	}
+
+	if kind == routing.WebSessionEnd {
+		if cookieAuth, _ := c.Locals(websession.LocalCookieAuth).(bool); cookieAuth {
+			web.End(c)
+		}
+		return resp, nil
+	}
+	if !web.Requested(c) || !isJSON(resp.Header.Get(fiber.HeaderContentType)) {
+		return resp, nil
+	}
+
+	resp, body, buffered, err := bufferResponseBody(resp, maxSessionBody)
+	if err != nil || !buffered {
+		if err == nil {
+			shared.GetLogger().WarnMap("Response too large to issue web session", map[string]any{"limit": maxSessionBody})
+		}
+		return resp, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return resp, nil
+	}
+	accessToken, _ := doc["access_token"].(string)
+	if accessToken == "" {
+		return resp, nil
+	}
+
+	csrf, err := web.Issue(c, accessToken)
+	if err != nil {
+		shared.GetLogger().ErrorObj("Web session not issued", err)
+		return nil, apperr.ErrInternal
+	}
+	// Portal nie dostaje tokenów – sesję przedłuża samo używanie (okno bezczynności w Redis)
+	delete(doc, "access_token")
+	delete(doc, "refresh_token")
+	doc["csrf_token"] = csrf
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(out))
+	resp.ContentLength = int64(len(out))
+	resp.Header.Del(fiber.HeaderContentLength)
+	resp.Header.Del(fiber.HeaderETag)
+	resp.Header.Set(fiber.HeaderCacheControl, "no-store")
+	return resp, nil
+}
+
+// serveCSRF zwraca token CSRF sesji z ciasteczka – portal po przeładowaniu strony
+// (albo z innego originu, bez dostępu do ciasteczka CSRF) pobiera go ponownie
+func serveCSRF(container *di.Container) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		cookieAuth, _ := c.Locals(websession.LocalCookieAuth).(bool)
+		sid, _ := c.Locals("sessionID").(string)
+		if !cookieAuth || sid == "" {
+			return apperr.SendAppError(c, apperr.ErrUnauthorized)
+		}
+		c.Set(fiber.HeaderCacheControl, "no-store")
+		return c.JSON(fiber.Map{"csrf_token": container.WebSessions.CSRFToken(sid)})
+	}
+}
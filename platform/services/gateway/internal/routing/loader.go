@@ -0,0 +1,546 @@
+package routing
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+	"go.yaml.in/yaml/v3"
+)
+
+const (
+	maxRouteTimeout     = 5 * time.Minute
+	maxRouteBodyLimitMB = 512
+	maxRouteCacheTTL    = time.Hour
+
+	defaultIdempotencyTTL = 24 * time.Hour
+	maxIdempotencyTTL     = 7 * 24 * time.Hour
+
+	// Każda sekcja to osobne żądanie do upstreamu przy każdym wywołaniu trasy
+	maxComposeBranches = 10
+
+	defaultRollbackWindow      = 5 * time.Minute
+	defaultRollbackMinRequests = 50
+	maxRollbackWindow          = time.Hour
+)
+
+// Metody, dla których Idempotency-Key ma sens (GET/HEAD/OPTIONS są idempotentne z definicji)
+var unsafeMethods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+// Nazwy wersji API: v1, v2, ... (prefiks ścieżki /v2/...)
+var versionName = regexp.MustCompile(`^v[1-9][0-9]*$`)
+
+// Nazwy sekcji trasy składanej (klucze JSON odpowiedzi)
+var sectionName = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*$`)
+
+var allowedMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut,
+	http.MethodPatch, http.MethodDelete, http.MethodOptions,
+}
+
+// Catalog opisuje to, co gateway potrafi obsłużyć – plik tras jest względem niego walidowany
+type Catalog struct {
+	Upstreams          map[string][]string // nazwa -> instancje (np. "auth" -> SERVICE_AUTH_URL)
+	HasSchema          func(name string) bool
+	HasLimiter         func(name string) bool
+	DefaultTimeout     time.Duration
+	DefaultBodyLimitMB int
+	// GeoIP – wczytana baza krajów (network.countries jej wymaga)
+	GeoIP bool
+}
+
+// Load czyta i waliduje plik tras
+func Load(path string, cat Catalog) (*Table, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read routes file: %w", err)
+	}
+	return Parse(data, cat)
+}
+
+// Parse buduje tablicę tras; zwraca wszystkie błędy walidacji naraz
+func Parse(data []byte, cat Catalog) (*Table, error) {
+	var file File
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&file); err != nil {
+		return nil, fmt.Errorf("parse routes file: %w", err)
+	}
+	if len(file.Routes) == 0 {
+		return nil, errors.New("routes file defines no routes")
+	}
+
+	table := &Table{routes: make([]*Route, 0, len(file.Routes))}
+	seen := make(map[string]int)
+	var errs []error
+
+	if file.Versions != nil {
+		versions, err := compileVersions(*file.Versions)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("versions: %w", err))
+		}
+		table.versions = versions
+	}
+
+	ids := make(map[string]bool)
+	for i, spec := range file.Maintenance {
+		window, err := CompileMaintenance(spec, cat)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("maintenance #%d (%s): %w", i+1, spec.ID, err))
+			continue
+		}
+		if ids[spec.ID] {
+			errs = append(errs, fmt.Errorf("maintenance #%d: duplicate id %q", i+1, spec.ID))
+		}
+		ids[spec.ID] = true
+		table.maintenance = append(table.maintenance, window)
+	}
+
+	for i, spec := range file.Routes {
+		route, err := compileRoute(spec, cat, table.versions)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("route #%d (%s): %w", i+1, spec.Path, err))
+			continue
+		}
+
+		methods := route.Methods
+		if len(methods) == 0 {
+			methods = []string{"*"}
+		}
+		for _, m := range methods {
+			key := m + " " + route.Path
+			if prev, dup := seen[key]; dup {
+				errs = append(errs, fmt.Errorf("route #%d (%s): duplicates route #%d for %s", i+1, spec.Path, prev, m))
+			}
+			seen[key] = i + 1
+		}
+
+		table.routes = append(table.routes, route)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return table, nil
+}
+
+func compileRoute(spec RouteSpec, cat Catalog, versions *Versions) (*Route, error) {
+	var errs []error
+
+	p, err := compilePattern(spec.Path)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	for i, m := range spec.Methods {
+		spec.Methods[i] = strings.ToUpper(m)
+		if !slices.Contains(allowedMethods, spec.Methods[i]) {
+			errs = append(errs, fmt.Errorf("unsupported method %q", m))
+		}
+	}
+
+	var target string
+	if len(spec.Compose) > 0 {
+		if spec.Upstream != "" {
+			errs = append(errs, errors.New("compose routes have no upstream (each section names its own)"))
+		}
+	} else {
+		target, err = resolveUpstream(spec.Upstream, cat.Upstreams)
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	switch spec.Mode {
+	case ModePublic:
+		if len(spec.Roles) > 0 {
+			errs = append(errs, errors.New("roles require mode: secure"))
+		}
+	case ModeSecure:
+		if len(spec.PassHeaders) > 0 {
+			errs = append(errs, errors.New("pass_headers are only allowed on public routes"))
+		}
+		spec.SignedContext = true
+	default:
+		errs = append(errs, fmt.Errorf("mode must be %q or %q", ModePublic, ModeSecure))
+	}
+
+	if spec.Stream {
+		if spec.Mode != ModeSecure {
+			errs = append(errs, errors.New("stream requires mode: secure"))
+		}
+		if len(spec.Methods) == 0 {
+			spec.Methods = []string{http.MethodGet}
+		} else if len(spec.Methods) != 1 || spec.Methods[0] != http.MethodGet {
+			errs = append(errs, errors.New("stream routes accept only GET"))
+		}
+		if spec.Schema != "" {
+			errs = append(errs, errors.New("stream routes cannot have a body schema"))
+		}
+	}
+
+	if spec.Cache != nil {
+		if spec.Cache.TTL < time.Second || spec.Cache.TTL > maxRouteCacheTTL {
+			errs = append(errs, fmt.Errorf("cache.ttl must be between 1s and %s", maxRouteCacheTTL))
+		}
+		if spec.Stream {
+			errs = append(errs, errors.New("stream routes cannot be cached"))
+		}
+		if len(spec.Methods) > 0 && !slices.Contains(spec.Methods, http.MethodGet) {
+			errs = append(errs, errors.New("cache requires GET among methods"))
+		}
+	}
+	if len(spec.PurgeTags) > 0 && spec.Mode != ModeSecure {
+		// Bez użytkownika nie ma czyich wpisów unieważniać – globalny purge robią serwisy
+		errs = append(errs, errors.New("purge_tags require mode: secure"))
+	}
+
+	if spec.Idempotency != nil {
+		switch {
+		case spec.Idempotency.TTL < 0 || spec.Idempotency.TTL > maxIdempotencyTTL:
+			errs = append(errs, fmt.Errorf("idempotency.ttl must be between 0 and %s", maxIdempotencyTTL))
+		case spec.Idempotency.TTL == 0:
+			spec.Idempotency.TTL = defaultIdempotencyTTL
+		}
+		// Klucze są w zakresie użytkownika / sesji – trasa publiczna nie ma zakresu
+		if spec.Mode != ModeSecure {
+			errs = append(errs, errors.New("idempotency requires mode: secure"))
+		}
+		if spec.Stream {
+			errs = append(errs, errors.New("stream routes cannot use idempotency"))
+		}
+		if len(spec.Methods) == 0 || slices.ContainsFunc(spec.Methods, func(m string) bool {
+			return !slices.Contains(unsafeMethods, m)
+		}) {
+			errs = append(errs, errors.New("idempotency requires methods limited to POST, PUT, PATCH or DELETE"))
+		}
+	}
+
+	switch spec.WebSession {
+	case "", WebSessionIssue:
+	case WebSessionEnd:
+		if spec.Mode != ModeSecure {
+			errs = append(errs, errors.New("web_session: end requires mode: secure"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("web_session must be %q or %q", WebSessionIssue, WebSessionEnd))
+	}
+	// Odpowiedź z tokenami nie może trafić do współdzielonego cache. Pod Idempotency-Key
+	// trafia już wersja bez tokenów (powtórzenie nie odtwarza ciasteczek – trzeba zalogować się ponownie).
+	if spec.WebSession != "" && (spec.Stream || spec.Cache != nil || len(spec.Compose) > 0) {
+		errs = append(errs, errors.New("web_session routes cannot use stream, cache or compose"))
+	}
+
+	if spec.Schema != "" && !cat.HasSchema(spec.Schema) {
+		errs = append(errs, fmt.Errorf("unknown schema %q", spec.Schema))
+	}
+	if spec.Limiter != "" && !cat.HasLimiter(spec.Limiter) {
+		errs = append(errs, fmt.Errorf("unknown limiter policy %q", spec.Limiter))
+	}
+
+	switch {
+	case spec.Timeout < 0 || spec.Timeout > maxRouteTimeout:
+		errs = append(errs, fmt.Errorf("timeout must be between 0 and %s", maxRouteTimeout))
+	case spec.Timeout == 0:
+		spec.Timeout = cat.DefaultTimeout
+	}
+
+	switch {
+	case spec.BodyLimitMB < 0 || spec.BodyLimitMB > maxRouteBodyLimitMB:
+		errs = append(errs, fmt.Errorf("body_limit_mb must be between 0 and %d", maxRouteBodyLimitMB))
+	case spec.BodyLimitMB == 0:
+		spec.BodyLimitMB = cat.DefaultBodyLimitMB
+	}
+
+	routeVersions, err := compileRouteVersions(spec, cat, versions)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	branches, err := compileBranches(&spec, cat)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	canaryTarget, err := compileCanary(&spec, cat, target)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	network, err := compileNetwork(spec.Network, cat)
+	if err != nil {
+		errs = append(errs, err)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &Route{
+		RouteSpec:    spec,
+		Target:       target,
+		BodyLimit:    int64(spec.BodyLimitMB) << 20,
+		Branches:     branches,
+		CanaryTarget: canaryTarget,
+		Allowlist:    network,
+		pattern:      p,
+		versions:     routeVersions,
+	}, nil
+}
+
+// compileBranches kompiluje sekcje trasy składanej. Odpowiedź powstaje w gatewayu,
+// więc trasa nie może mieć niczego, co dotyczy pojedynczego upstreamu lub body.
+func compileBranches(spec *RouteSpec, cat Catalog) ([]*Branch, error) {
+	if len(spec.Compose) == 0 {
+		return nil, nil
+	}
+
+	var errs []error
+	if spec.Mode != ModeSecure {
+		errs = append(errs, errors.New("compose requires mode: secure"))
+	}
+	if len(spec.Methods) == 0 {
+		spec.Methods = []string{http.MethodGet}
+	} else if len(spec.Methods) != 1 || spec.Methods[0] != http.MethodGet {
+		errs = append(errs, errors.New("compose routes accept only GET"))
+	}
+	if spec.Stream || spec.Schema != "" || spec.Cache != nil || spec.Idempotency != nil ||
+		len(spec.PurgeTags) > 0 || len(spec.Versions) > 0 {
+		errs = append(errs, errors.New("compose routes cannot use stream, schema, cache, idempotency, purge_tags or versions"))
+	}
+	if len(spec.Compose) > maxComposeBranches {
+		errs = append(errs, fmt.Errorf("compose allows at most %d sections", maxComposeBranches))
+	}
+
+	branches := make([]*Branch, 0, len(spec.Compose))
+	seen := make(map[string]bool, len(spec.Compose))
+	for i, b := range spec.Compose {
+		prefix := fmt.Sprintf("compose #%d (%s)", i+1, b.Name)
+		switch {
+		case !sectionName.MatchString(b.Name):
+			errs = append(errs, fmt.Errorf("%s: name must be an identifier (letters, digits, _)", prefix))
+		case seen[b.Name]:
+			errs = append(errs, fmt.Errorf("%s: duplicate section name", prefix))
+		}
+		seen[b.Name] = true
+
+		target, err := resolveUpstream(b.Upstream, cat.Upstreams)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", prefix, err))
+		}
+		if u, err := url.Parse(b.Path); err != nil || !strings.HasPrefix(b.Path, "/") || u.Host != "" || strings.Contains(u.Path, "..") {
+			errs = append(errs, fmt.Errorf("%s: path must be an absolute upstream path", prefix))
+		}
+
+		switch {
+		case b.Timeout < 0 || b.Timeout > spec.Timeout:
+			errs = append(errs, fmt.Errorf("%s: timeout must be between 0 and the route timeout (%s)", prefix, spec.Timeout))
+		case b.Timeout == 0:
+			b.Timeout = spec.Timeout
+		}
+
+		branches = append(branches, &Branch{Name: b.Name, Target: target, Path: b.Path, Timeout: b.Timeout})
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return branches, nil
+}
+
+// compileCanary sprawdza wydanie canary trasy i uzupełnia wartości domyślne rollbacku
+func compileCanary(spec *RouteSpec, cat Catalog, target string) (string, error) {
+	canary := spec.Canary
+	if canary == nil {
+		return "", nil
+	}
+
+	var errs []error
+	if len(spec.Compose) > 0 {
+		errs = append(errs, errors.New("compose routes cannot use canary (sections name their upstreams)"))
+	}
+	canaryTarget, err := resolveUpstream(canary.Upstream, cat.Upstreams)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("canary: %w", err))
+	} else if canaryTarget == target {
+		errs = append(errs, errors.New("canary: upstream must differ from the route upstream"))
+	}
+	if len(canary.Testers) > 0 && spec.Mode != ModeSecure {
+		errs = append(errs, errors.New("canary: testers require mode: secure"))
+	}
+	if canary.Weight < 0 || canary.Weight > 100 {
+		errs = append(errs, errors.New("canary: weight must be between 0 and 100"))
+	}
+	// Bez testerów nagłówek X-Canary jest ignorowany – canary z wagą 0 nie dostałby ruchu
+	if canary.Weight == 0 && len(canary.Testers) == 0 {
+		errs = append(errs, errors.New("canary: weight 0 requires testers (X-Canary is honoured only for testers)"))
+	}
+	switch canary.Sticky {
+	case "":
+		canary.Sticky = CanaryStickyUser
+	case CanaryStickyUser, CanaryStickyRequest:
+	default:
+		errs = append(errs, fmt.Errorf("canary: sticky must be %q or %q", CanaryStickyUser, CanaryStickyRequest))
+	}
+
+	if rb := canary.Rollback; rb != nil {
+		if rb.MaxErrorRateDelta <= 0 || rb.MaxErrorRateDelta > 1 {
+			errs = append(errs, errors.New("canary.rollback: max_error_rate_delta must be in (0, 1]"))
+		}
+		switch {
+		case rb.MinRequests < 0:
+			errs = append(errs, errors.New("canary.rollback: min_requests must not be negative"))
+		case rb.MinRequests == 0:
+			rb.MinRequests = defaultRollbackMinRequests
+		}
+		switch {
+		case rb.Window < 0 || rb.Window > maxRollbackWindow:
+			errs = append(errs, fmt.Errorf("canary.rollback: window must be between 0 and %s", maxRollbackWindow))
+		case rb.Window == 0:
+			rb.Window = defaultRollbackWindow
+		}
+	}
+
+	if len(errs) > 0 {
+		return "", errors.Join(errs...)
+	}
+	return canaryTarget, nil
+}
+
+func compileNetwork(spec *NetworkSpec, cat Catalog) (*NetworkPolicy, error) {
+	if spec == nil {
+		return nil, nil
+	}
+
+	var errs []error
+	allow, err := netguard.ParsePrefixes(spec.AllowCIDRs)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("network.allow_cidrs: %w", err))
+	}
+	countries, err := netguard.ParseCountries(spec.Countries)
+	if err != nil {
+		errs = append(errs, fmt.Errorf("network.countries: %w", err))
+	}
+	if len(countries) > 0 && !cat.GeoIP {
+		errs = append(errs, errors.New("network.countries requires GEOIP_DATABASE"))
+	}
+	if len(spec.AllowCIDRs) == 0 && len(spec.Countries) == 0 {
+		errs = append(errs, errors.New("network requires allow_cidrs or countries"))
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return &NetworkPolicy{Allow: allow, Countries: countries}, nil
+}
+
+func compileVersions(spec VersionsSpec) (*Versions, error) {
+	var errs []error
+	v := &Versions{byName: make(map[string]*APIVersion)}
+
+	if len(spec.Supported) == 0 {
+		errs = append(errs, errors.New("supported must list at least one version"))
+	}
+	for _, s := range spec.Supported {
+		switch {
+		case !versionName.MatchString(s.Name):
+			errs = append(errs, fmt.Errorf("invalid version name %q (expected v1, v2, ...)", s.Name))
+			continue
+		case v.byName[s.Name] != nil:
+			errs = append(errs, fmt.Errorf("duplicate version %q", s.Name))
+			continue
+		}
+		if !s.Deprecated.IsZero() && !s.Sunset.IsZero() && s.Sunset.Before(s.Deprecated) {
+			errs = append(errs, fmt.Errorf("version %s: sunset precedes deprecated", s.Name))
+		}
+		if s.Link != "" {
+			if u, err := url.Parse(s.Link); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+				errs = append(errs, fmt.Errorf("version %s: link must be an http(s) URL", s.Name))
+			}
+		}
+		v.byName[s.Name] = &APIVersion{APIVersionSpec: s}
+		v.names = append(v.names, s.Name)
+	}
+
+	v.Default = v.byName[spec.Default]
+	if v.Default == nil {
+		errs = append(errs, fmt.Errorf("default version %q is not listed in supported", spec.Default))
+	}
+
+	// Poprawne wersje są zwracane mimo błędów – trasy odwołujące się do nich nie dokładają fałszywych błędów
+	return v, errors.Join(errs...)
+}
+
+func compileRouteVersions(spec RouteSpec, cat Catalog, versions *Versions) (map[string]*RouteVersion, error) {
+	if len(spec.Versions) == 0 {
+		return nil, nil
+	}
+	if versions == nil {
+		return nil, errors.New("versions require a top-level versions section")
+	}
+
+	var errs []error
+	compiled := make(map[string]*RouteVersion, len(spec.Versions))
+	for name, v := range spec.Versions {
+		if versions.Lookup(name) == nil {
+			errs = append(errs, fmt.Errorf("versions: unknown version %q", name))
+			continue
+		}
+		if v == nil {
+			continue
+		}
+
+		rv := &RouteVersion{Prefix: v.Prefix, Rename: v.Rename}
+		if v.Upstream != "" {
+			target, err := resolveUpstream(v.Upstream, cat.Upstreams)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("versions.%s: %w", name, err))
+			}
+			rv.Target = target
+		}
+		if v.Prefix != "" && (!strings.HasPrefix(v.Prefix, "/") || strings.HasSuffix(v.Prefix, "/")) {
+			errs = append(errs, fmt.Errorf("versions.%s: prefix must start with '/' and not end with '/'", name))
+		}
+		for from, to := range v.Rename {
+			if from == "" || to == "" || strings.Contains(to, ".") {
+				errs = append(errs, fmt.Errorf("versions.%s: invalid rename %q -> %q (target is a field name)", name, from, to))
+			}
+		}
+		// Odpowiedzi strumieni nie są przepisywane
+		if len(v.Rename) > 0 && spec.Stream {
+			errs = append(errs, fmt.Errorf("versions.%s: stream routes cannot rename response fields", name))
+		}
+		compiled[name] = rv
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return compiled, nil
+}
+
+// resolveUpstream przyjmuje nazwę serwisu z konfiguracji albo pełny URL (nowy serwis bez wydania gatewaya)
+func resolveUpstream(upstream string, known map[string][]string) (string, error) {
+	if upstream == "" {
+		return "", errors.New("upstream is required")
+	}
+	if _, ok := known[upstream]; ok {
+		return upstream, nil
+	}
+
+	u, err := url.Parse(upstream)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("unknown upstream %q (expected a service name or http(s) URL)", upstream)
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" {
+		return "", fmt.Errorf("upstream URL %q must not contain a path or query", upstream)
+	}
+	return strings.TrimRight(upstream, "/"), nil
+}
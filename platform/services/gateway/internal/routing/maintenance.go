@@ -0,0 +1,133 @@
+package routing
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DefaultMaintenanceLanguage – komunikat, gdy klient nie prosi o żaden z dostępnych języków
+const DefaultMaintenanceLanguage = "pl"
+
+const maxMaintenanceAnnounce = 30 * 24 * time.Hour
+
+// Identyfikatory okien: część klucza Redis i ścieżki admin API
+var maintenanceID = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// MaintenanceWindow – zwalidowane okno serwisowe
+type MaintenanceWindow struct {
+	MaintenanceSpec
+	targets  []string
+	prefixes []string
+	testers  map[uuid.UUID]struct{}
+}
+
+// Active – czy okno trwa w chwili now
+func (w *MaintenanceWindow) Active(now time.Time) bool {
+	return !now.Before(w.Start) && now.Before(w.End)
+}
+
+// Covers – czy trasa należy do grupy okna
+func (w *MaintenanceWindow) Covers(route *Route) bool {
+	if slices.Contains(w.targets, route.Target) || (route.CanaryTarget != "" && slices.Contains(w.targets, route.CanaryTarget)) {
+		return true
+	}
+	if slices.Contains(w.Paths, route.Path) {
+		return true
+	}
+	return slices.ContainsFunc(w.prefixes, func(prefix string) bool {
+		return strings.HasPrefix(route.Path, prefix)
+	})
+}
+
+// Admits – tester z listy okna przechodzi mimo prac serwisowych
+func (w *MaintenanceWindow) Admits(userID *uuid.UUID) bool {
+	if userID == nil {
+		return false
+	}
+	_, ok := w.testers[*userID]
+	return ok
+}
+
+// Localize wybiera komunikat wg Accept-Language (języki w kolejności z nagłówka,
+// "en-US" pasuje do "en"); bez dopasowania – DefaultMaintenanceLanguage
+func (w *MaintenanceWindow) Localize(acceptLanguage string) (lang, message string) {
+	for part := range strings.SplitSeq(acceptLanguage, ",") {
+		tag, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if msg, ok := w.Message[primary]; ok {
+			return primary, msg
+		}
+	}
+	return DefaultMaintenanceLanguage, w.Message[DefaultMaintenanceLanguage]
+}
+
+// CompileMaintenance waliduje okno serwisowe; upstreamy są sprawdzane względem katalogu gatewaya
+func CompileMaintenance(spec MaintenanceSpec, cat Catalog) (*MaintenanceWindow, error) {
+	var errs []error
+	w := &MaintenanceWindow{testers: make(map[uuid.UUID]struct{}, len(spec.Testers))}
+
+	if !maintenanceID.MatchString(spec.ID) {
+		errs = append(errs, fmt.Errorf("invalid id %q (lowercase letters, digits, '.', '_', '-')", spec.ID))
+	}
+	if len(spec.Upstreams) == 0 && len(spec.Paths) == 0 {
+		errs = append(errs, errors.New("at least one of upstreams or paths is required"))
+	}
+	for _, name := range spec.Upstreams {
+		target, err := resolveUpstream(name, cat.Upstreams)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		w.targets = append(w.targets, target)
+	}
+	for _, path := range spec.Paths {
+		if _, err := compilePattern(path); err != nil {
+			errs = append(errs, fmt.Errorf("paths: %w", err))
+			continue
+		}
+		if prefix, ok := strings.CutSuffix(path, "*"); ok {
+			w.prefixes = append(w.prefixes, prefix)
+		}
+	}
+
+	if spec.Start.IsZero() || spec.End.IsZero() {
+		errs = append(errs, errors.New("start and end are required"))
+	} else if !spec.End.After(spec.Start) {
+		errs = append(errs, errors.New("end must be after start"))
+	}
+	if spec.Announce < 0 || spec.Announce > maxMaintenanceAnnounce {
+		errs = append(errs, fmt.Errorf("announce must be between 0 and %s", maxMaintenanceAnnounce))
+	}
+
+	if spec.Message[DefaultMaintenanceLanguage] == "" {
+		errs = append(errs, fmt.Errorf("message.%s is required", DefaultMaintenanceLanguage))
+	}
+	// Klucze jak w Accept-Language po obcięciu regionu – inaczej komunikat nigdy nie zostanie wybrany
+	for _, lang := range slices.Sorted(maps.Keys(spec.Message)) {
+		if lang != strings.ToLower(lang) || strings.Contains(lang, "-") || spec.Message[lang] == "" {
+			errs = append(errs, fmt.Errorf("message.%s: expected a non-empty message under a lowercase language code", lang))
+		}
+	}
+
+	for _, id := range spec.Testers {
+		uid, err := uuid.Parse(id)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("testers: invalid user id %q", id))
+			continue
+		}
+		w.testers[uid] = struct{}{}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	w.MaintenanceSpec = spec
+	return w, nil
+}
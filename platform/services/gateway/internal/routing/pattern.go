@@ -0,0 +1,68 @@
+package routing
+
+import (
+	"fmt"
+	"strings"
+)
+
+// pattern to skompilowany wzorzec ścieżki w składni zbliżonej do Fiber:
+//   - "/auth/login"      – dopasowanie dokładne
+//   - "/users/:id"       – ":" dopasowuje dokładnie jeden niepusty segment
+//   - "/documents/*"     – "*" na końcu dopasowuje dowolną resztę (również pustą)
+//   - "/notifications*"  – jak wyżej, bez wymaganego "/"
+type pattern struct {
+	raw      string
+	segments []string
+	prefix   string // niepusty dla wzorców z "*"
+	wildcard bool
+}
+
+func compilePattern(raw string) (pattern, error) {
+	if !strings.HasPrefix(raw, "/") {
+		return pattern{}, fmt.Errorf("path %q must start with '/'", raw)
+	}
+
+	p := pattern{raw: raw}
+	if i := strings.IndexByte(raw, '*'); i >= 0 {
+		if i != len(raw)-1 {
+			return pattern{}, fmt.Errorf("path %q: '*' is only allowed at the end", raw)
+		}
+		p.wildcard = true
+		p.prefix = raw[:i]
+		if strings.Contains(p.prefix, ":") {
+			return pattern{}, fmt.Errorf("path %q: parameters cannot be combined with '*'", raw)
+		}
+		return p, nil
+	}
+
+	p.segments = strings.Split(strings.Trim(raw, "/"), "/")
+	for _, seg := range p.segments {
+		if seg == ":" {
+			return pattern{}, fmt.Errorf("path %q: unnamed parameter", raw)
+		}
+	}
+	return p, nil
+}
+
+func (p pattern) match(path string) bool {
+	if p.wildcard {
+		return strings.HasPrefix(path, p.prefix)
+	}
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	if len(segments) != len(p.segments) {
+		return false
+	}
+	for i, seg := range p.segments {
+		if strings.HasPrefix(seg, ":") {
+			if segments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if seg != segments[i] {
+			return false
+		}
+	}
+	return true
+}
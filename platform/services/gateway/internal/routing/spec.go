@@ -0,0 +1,175 @@
+package routing
+
+import "time"
+
+// Tryby dostępu do trasy
+const (
+	ModePublic = "public" // bez JWT i sesji Redis
+	ModeSecure = "secure" // JWT + sesja Redis + podpisany kontekst
+)
+
+// File to struktura pliku routes.yaml
+type File struct {
+	// Versions – wersje API (brak = ścieżki bez wersjonowania)
+	Versions *VersionsSpec `yaml:"versions"`
+	// Maintenance – zaplanowane okna serwisowe grup tras (także z admin API)
+	Maintenance []MaintenanceSpec `yaml:"maintenance"`
+	Routes      []RouteSpec       `yaml:"routes"`
+}
+
+// MaintenanceSpec – okno serwisowe: od Start do End trasy grupy zwracają 503 z komunikatem
+// i Retry-After. Grupę wyznaczają upstreamy i / lub ścieżki tras.
+type MaintenanceSpec struct {
+	// ID – identyfikator okna (admin API zastępuje okno z pliku o tym samym ID)
+	ID string `yaml:"id" json:"id"`
+	// Upstreams – wszystkie trasy tych upstreamów (także ich canary); trasy compose degradują sekcje
+	Upstreams []string `yaml:"upstreams" json:"upstreams,omitempty"`
+	// Paths – ścieżki tras jak w routes.yaml albo prefiks zakończony /* (np. /documents/*)
+	Paths []string  `yaml:"paths" json:"paths,omitempty"`
+	Start time.Time `yaml:"start" json:"start"`
+	End   time.Time `yaml:"end" json:"end"`
+	// Message – komunikat per język (pl, en, ...) wybierany wg Accept-Language; pl jest wymagany
+	Message map[string]string `yaml:"message" json:"message"`
+	// Testers – ID użytkowników, którzy w trakcie okna nadal mają dostęp (weryfikacja migracji)
+	Testers []string `yaml:"testers" json:"testers,omitempty"`
+	// Announce – z jakim wyprzedzeniem wysłać powiadomienie do wszystkich użytkowników (0 = bez)
+	Announce time.Duration `yaml:"announce" json:"announce,omitempty"`
+}
+
+// VersionsSpec – wersje API obsługiwane przez gateway. Wersję wybiera prefiks ścieżki
+// (/v2/auth/login) albo nagłówek API-Version; trasy są dopasowywane bez prefiksu.
+type VersionsSpec struct {
+	// Default – wersja żądań bez prefiksu i nagłówka (dotychczasowe aplikacje)
+	Default   string           `yaml:"default"`
+	Supported []APIVersionSpec `yaml:"supported"`
+}
+
+// APIVersionSpec – cykl życia wersji API
+type APIVersionSpec struct {
+	Name string `yaml:"name"` // v1, v2, ...
+	// Deprecated – data wycofywania (nagłówek Deprecation, także zapowiedź na przyszłość)
+	Deprecated time.Time `yaml:"deprecated"`
+	// Sunset – od tej chwili wersja zwraca 410 (wcześniej nagłówek Sunset)
+	Sunset time.Time `yaml:"sunset"`
+	// Link – opis migracji (nagłówek Link)
+	Link string `yaml:"link"`
+}
+
+// RouteVersionSpec – odstępstwa trasy w danej wersji API
+type RouteVersionSpec struct {
+	// Upstream – inny serwis albo URL dla tej wersji (brak = upstream trasy)
+	Upstream string `yaml:"upstream"`
+	// Prefix – doklejany do ścieżki w upstreamie (np. /v2 dla serwisu z wersjonowanymi trasami)
+	Prefix string `yaml:"prefix"`
+	// Rename – zmiana nazw pól odpowiedzi JSON: pole upstreamu (a.b = zagnieżdżone) -> nazwa dla klienta
+	Rename map[string]string `yaml:"rename"`
+}
+
+// RouteSpec to pojedynczy wpis w pliku tras
+type RouteSpec struct {
+	Path     string        `yaml:"path"`
+	Methods  []string      `yaml:"methods"`
+	Upstream string        `yaml:"upstream"`
+	Mode     string        `yaml:"mode"`
+	Schema   string        `yaml:"schema"`
+	Limiter  string        `yaml:"limiter"`
+	Timeout  time.Duration `yaml:"timeout"`
+	// BodyLimitMB – limit body żądania dla trasy (0 = BODY_LIMIT_MB)
+	BodyLimitMB int `yaml:"body_limit_mb"`
+	// SignedContext – trasa publiczna, ale upstream oczekuje podpisanego RequestContext (np. /auth/login)
+	SignedContext bool `yaml:"signed_context"`
+	// PassHeaders – dodatkowe nagłówki klienta przepuszczane do upstream (tylko trasy publiczne)
+	PassHeaders []string `yaml:"pass_headers"`
+	// Roles – wymagane role (co najmniej jedna), tylko trasy chronione
+	Roles []string `yaml:"roles"`
+	// Stream – połączenie długotrwałe (WebSocket / SSE), tylko GET na trasach chronionych
+	Stream bool `yaml:"stream"`
+	// Cache – cache odpowiedzi GET w Redis (brak = wyłączony)
+	Cache *CacheSpec `yaml:"cache"`
+	// PurgeTags – tagi cache użytkownika unieważniane po udanym zapisie (POST/PUT/PATCH/DELETE)
+	PurgeTags []string `yaml:"purge_tags"`
+	// Idempotency – obsługa nagłówka Idempotency-Key (tylko metody modyfikujące na trasach chronionych)
+	Idempotency *IdempotencySpec `yaml:"idempotency"`
+	// Versions – mapowanie trasy per wersja API (wersje spoza listy używają ustawień trasy)
+	Versions map[string]*RouteVersionSpec `yaml:"versions"`
+	// Compose – trasa składana (BFF): odpowiedź z równoległych wywołań upstreamów zamiast upstream
+	Compose []ComposeSpec `yaml:"compose"`
+	// Canary – część ruchu trasy trafia do nowej wersji upstreamu
+	Canary *CanarySpec `yaml:"canary"`
+	// Network – dostęp tylko z wybranych sieci lub krajów (np. trasy administracyjne)
+	Network *NetworkSpec `yaml:"network"`
+	// WebSession – tryb przeglądarkowy (X-Session-Mode: cookie): issue – tokeny z odpowiedzi
+	// zamieniane na ciasteczko sesji, end – wylogowanie czyści ciasteczka
+	WebSession string `yaml:"web_session"`
+}
+
+// Rola trasy w sesji przeglądarkowej
+const (
+	WebSessionIssue = "issue"
+	WebSessionEnd   = "end"
+)
+
+// NetworkSpec – lista dozwolonych trasy: żądanie przechodzi, gdy adres klienta należy
+// do AllowCIDRs albo jego kraj jest na liście Countries
+type NetworkSpec struct {
+	AllowCIDRs []string `yaml:"allow_cidrs"`
+	// Countries – kody ISO 3166-1 alpha-2 (wymaga GEOIP_DATABASE)
+	Countries []string `yaml:"countries"`
+}
+
+// Sposób przydziału żądań do wariantu canary
+const (
+	CanaryStickyUser    = "user"    // ten sam użytkownik (lub IP bez sesji) zawsze w tym samym wariancie
+	CanaryStickyRequest = "request" // losowanie przy każdym żądaniu
+)
+
+// CanarySpec – wydanie canary trasy: Weight procent ruchu idzie do Upstream zamiast upstreamu trasy
+type CanarySpec struct {
+	Upstream string `yaml:"upstream"`
+	// Weight – procent ruchu (0–100); 0 = canary tylko dla testerów z nagłówkiem X-Canary
+	Weight int `yaml:"weight"`
+	// Sticky – user (domyślnie) albo request
+	Sticky string `yaml:"sticky"`
+	// Testers – role, którym wolno wybrać wariant nagłówkiem X-Canary (puste = nikomu)
+	Testers  []string      `yaml:"testers"`
+	Rollback *RollbackSpec `yaml:"rollback"`
+}
+
+// RollbackSpec – automatyczne wycofanie canary, gdy jego odsetek błędów (5xx, błędy
+// transportu) przekroczy odsetek wersji bazowej o więcej niż MaxErrorRateDelta
+type RollbackSpec struct {
+	// MaxErrorRateDelta – dopuszczalna różnica odsetka błędów (0.05 = 5 punktów procentowych)
+	MaxErrorRateDelta float64 `yaml:"max_error_rate_delta"`
+	// MinRequests – minimalna liczba żądań canary w oknie, zanim zapadnie decyzja
+	MinRequests int `yaml:"min_requests"`
+	// Window – okno porównania (domyślnie 5m)
+	Window time.Duration `yaml:"window"`
+}
+
+// ComposeSpec – sekcja odpowiedzi trasy składanej
+type ComposeSpec struct {
+	// Name – klucz sekcji w odpowiedzi
+	Name     string `yaml:"name"`
+	Upstream string `yaml:"upstream"`
+	// Path – ścieżka w upstreamie (z query), wywoływana metodą GET z kontekstem użytkownika
+	Path string `yaml:"path"`
+	// Timeout – limit czasu sekcji (domyślnie timeout trasy); po nim sekcja ma znacznik błędu
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// CacheSpec – polityka cache odpowiedzi trasy
+type CacheSpec struct {
+	// TTL – domyślny i zarazem maksymalny czas życia wpisu; upstream może go skrócić
+	// przez Cache-Control (max-age / s-maxage) albo wyłączyć (no-store, no-cache)
+	TTL time.Duration `yaml:"ttl"`
+	// Tags – tagi nadawane wpisom (oprócz tych z nagłówka Cache-Tag upstreamu)
+	Tags []string `yaml:"tags"`
+}
+
+// IdempotencySpec – zapamiętywanie wyników żądań z nagłówkiem Idempotency-Key
+type IdempotencySpec struct {
+	// TTL – jak długo pamiętamy klucz i odpowiedź (0 = domyślnie 24h)
+	TTL time.Duration `yaml:"ttl"`
+	// Required – żądanie bez nagłówka jest odrzucane (400)
+	Required bool `yaml:"required"`
+}
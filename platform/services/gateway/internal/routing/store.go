@@ -0,0 +1,117 @@
+package routing
+
+import (
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+const reloadDebounce = 250 * time.Millisecond
+
+// Store trzyma aktualną tablicę tras; podmiana jest atomowa, więc żądanie
+// w trakcie obsługi zawsze widzi spójną wersję (starą albo nową).
+type Store struct {
+	path    string
+	catalog Catalog
+	current atomic.Pointer[Table]
+	watcher *fsnotify.Watcher
+}
+
+// NewStore wczytuje plik tras – błąd walidacji przy starcie jest fatalny
+func NewStore(path string, cat Catalog) (*Store, error) {
+	table, err := Load(path, cat)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{path: path, catalog: cat}
+	s.current.Store(table)
+	return s, nil
+}
+
+// Table zwraca aktualną tablicę tras
+func (s *Store) Table() *Table {
+	return s.current.Load()
+}
+
+// CompileMaintenance waliduje okno serwisowe spoza pliku (admin API) tym samym katalogiem
+func (s *Store) CompileMaintenance(spec MaintenanceSpec) (*MaintenanceWindow, error) {
+	return CompileMaintenance(spec, s.catalog)
+}
+
+// Reload wczytuje plik ponownie; przy błędzie zostaje poprzednia wersja
+func (s *Store) Reload() error {
+	table, err := Load(s.path, s.catalog)
+	if err != nil {
+		return err
+	}
+	s.current.Store(table)
+	return nil
+}
+
+// Watch obserwuje katalog pliku (edytory i ConfigMapy podmieniają plik przez rename)
+func (s *Store) Watch() error {
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	if err := w.Add(filepath.Dir(s.path)); err != nil {
+		_ = w.Close()
+		return err
+	}
+	s.watcher = w
+
+	go s.watchLoop(w)
+	return nil
+}
+
+func (s *Store) watchLoop(w *fsnotify.Watcher) {
+	log := shared.GetLogger()
+	target := filepath.Clean(s.path)
+
+	var debounce *time.Timer
+	for {
+		select {
+		case ev, ok := <-w.Events:
+			if !ok {
+				return
+			}
+			if filepath.Clean(ev.Name) != target || ev.Has(fsnotify.Chmod) {
+				continue
+			}
+			// Zapis pliku generuje kilka zdarzeń – przeładowujemy raz, po ich ustaniu
+			if debounce != nil {
+				debounce.Stop()
+			}
+			debounce = time.AfterFunc(reloadDebounce, func() {
+				if err := s.Reload(); err != nil {
+					log.ErrorMap("Routes reload rejected, keeping previous table", map[string]any{
+						"file":  s.path,
+						"error": err.Error(),
+					})
+					return
+				}
+				log.InfoMap("Routes reloaded", map[string]any{
+					"file":   s.path,
+					"routes": s.Table().Len(),
+				})
+			})
+		case err, ok := <-w.Errors:
+			if !ok {
+				return
+			}
+			log.ErrorObj("Routes watcher error", err)
+		}
+	}
+}
+
+// Close zatrzymuje obserwację pliku
+func (s *Store) Close() error {
+	if s.watcher == nil {
+		return nil
+	}
+	return s.watcher.Close()
+}
@@ -0,0 +1,162 @@
+package routing
+
+import (
+	"net/netip"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/zerodayz7/platform/services/gateway/internal/netguard"
+)
+
+// Route to zwalidowana trasa gotowa do obsługi żądań
+type Route struct {
+	RouteSpec
+	// Target – nazwa serwisu z konfiguracji albo znormalizowany URL (klucz puli instancji)
+	Target string
+	// BodyLimit – limit body w bajtach (egzekwowany również dla body strumieniowanych)
+	BodyLimit int64
+	// Branches – sekcje trasy składanej (compose); puste dla zwykłych tras
+	Branches []*Branch
+	// CanaryTarget – klucz puli instancji wersji canary (pusty = trasa bez canary)
+	CanaryTarget string
+	// Allowlist – skompilowana sekcja network (nil = bez ograniczeń)
+	Allowlist *NetworkPolicy
+	pattern   pattern
+	versions  map[string]*RouteVersion
+}
+
+// Branch – skompilowana sekcja trasy składanej
+type Branch struct {
+	Name    string
+	Target  string
+	Path    string
+	Timeout time.Duration
+}
+
+// NetworkPolicy – lista dozwolonych sieci i krajów trasy
+type NetworkPolicy struct {
+	Allow     []netip.Prefix
+	Countries []string
+}
+
+// Allows – adres z dozwolonej sieci albo kraj z listy ("" = kraj nieznany, nie pasuje)
+func (p *NetworkPolicy) Allows(addr netip.Addr, country string) bool {
+	return netguard.Contains(p.Allow, addr) || (country != "" && slices.Contains(p.Countries, country))
+}
+
+// RouteVersion – skompilowane odstępstwa trasy dla wersji API
+type RouteVersion struct {
+	Target string // pusty = Target trasy
+	Prefix string
+	Rename map[string]string
+}
+
+// ForVersion zwraca odstępstwa trasy dla wersji API albo nil (ustawienia trasy)
+func (r *Route) ForVersion(name string) *RouteVersion {
+	return r.versions[name]
+}
+
+// Key identyfikuje trasę w tablicy ("GET,POST /a/:id", "* /a/*" dla wszystkich metod) –
+// ścieżka może się powtarzać z innymi metodami
+func (r *Route) Key() string {
+	methods := "*"
+	if len(r.Methods) > 0 {
+		methods = strings.Join(r.Methods, ",")
+	}
+	return methods + " " + r.Path
+}
+
+// Public zwraca true dla tras bez JWT i sesji
+func (r *Route) Public() bool {
+	return r.Mode == ModePublic
+}
+
+// AllowsMethod sprawdza metodę HTTP (pusta lista = wszystkie metody)
+func (r *Route) AllowsMethod(method string) bool {
+	return len(r.Methods) == 0 || slices.Contains(r.Methods, method)
+}
+
+// APIVersion – wersja API z cyklem życia (routes.yaml: versions)
+type APIVersion struct {
+	APIVersionSpec
+}
+
+// Retired – wersja po dacie Sunset
+func (v *APIVersion) Retired(now time.Time) bool {
+	return !v.Sunset.IsZero() && !now.Before(v.Sunset)
+}
+
+// Versions – wersje API tablicy tras
+type Versions struct {
+	Default *APIVersion
+	byName  map[string]*APIVersion
+	names   []string
+}
+
+// Lookup zwraca wersję po nazwie ("v2") albo nil
+func (v *Versions) Lookup(name string) *APIVersion {
+	return v.byName[name]
+}
+
+// Names – nazwy wersji w kolejności z pliku
+func (v *Versions) Names() []string {
+	return slices.Clone(v.names)
+}
+
+// SplitVersion rozdziela prefiks wersji ze ścieżki: "/v2/auth/login" -> "v2", "/auth/login".
+// ok=false – pierwszy segment nie jest nazwą wersji.
+func SplitVersion(path string) (name, rest string, ok bool) {
+	name, rest, _ = strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if !versionName.MatchString(name) {
+		return "", path, false
+	}
+	return name, "/" + rest, true
+}
+
+// Table jest niemutowalna – przeładowanie tworzy nową instancję
+type Table struct {
+	routes      []*Route
+	versions    *Versions
+	maintenance []*MaintenanceWindow
+}
+
+// Maintenance zwraca okna serwisowe z pliku tras
+func (t *Table) Maintenance() []*MaintenanceWindow {
+	return slices.Clone(t.maintenance)
+}
+
+// Versions zwraca wersje API albo nil, gdy plik tras ich nie definiuje
+func (t *Table) Versions() *Versions {
+	return t.versions
+}
+
+// Match zwraca pierwszą trasę (w kolejności z pliku) pasującą do ścieżki i metody
+func (t *Table) Match(method, path string) *Route {
+	for _, r := range t.routes {
+		if r.pattern.match(path) && r.AllowsMethod(method) {
+			return r
+		}
+	}
+	return nil
+}
+
+// Routes zwraca trasy w kolejności z pliku
+func (t *Table) Routes() []*Route {
+	return slices.Clone(t.routes)
+}
+
+// Lookup zwraca trasę o kluczu Route.Key albo nil
+func (t *Table) Lookup(key string) *Route {
+	for _, r := range t.routes {
+		if r.Key() == key {
+			return r
+		}
+	}
+	return nil
+}
+
+// Len zwraca liczbę tras
+func (t *Table) Len() int {
+	return len(t.routes)
+}
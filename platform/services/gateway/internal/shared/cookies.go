@@ -3,10 +3,17 @@ package shared
 
 import "github.com/gofiber/fiber/v2"
 
+// Ciasteczka trybu przeglądarkowego. Prefiks __Host- wymusza Secure, Path=/ i brak Domain,
+// więc subdomena nie może ich nadpisać.
+const (
+	SessionCookie = "__Host-session_"
+	CSRFCookie    = "__Host-csrf_"
+)
+
 // SetSessionCookie ustawia ciasteczko sesji
 func SetSessionCookie(c *fiber.Ctx, sessionID string) {
 	c.Cookie(&fiber.Cookie{
-		Name:     "__Host-session_",
+		Name:     SessionCookie,
 		Value:    sessionID,
 		HTTPOnly: true,
 		Secure:   true, // włączone w produkcji
@@ -18,7 +25,7 @@ func SetSessionCookie(c *fiber.Ctx, sessionID string) {
 // ClearSessionCookie usuwa ciasteczko sesji (wylogowanie)
 func ClearSessionCookie(c *fiber.Ctx) {
 	c.Cookie(&fiber.Cookie{
-		Name:     "__Host-session_",
+		Name:     SessionCookie,
 		Value:    "",
 		HTTPOnly: true,
 		Secure:   true,
@@ -27,3 +34,27 @@ func ClearSessionCookie(c *fiber.Ctx) {
 		MaxAge:   -1,
 	})
 }
+
+// SetCSRFCookie ustawia token CSRF – czytelny dla skryptu portalu (bez HttpOnly),
+// który odsyła go w nagłówku X-CSRF-Token
+func SetCSRFCookie(c *fiber.Ctx, token string) {
+	c.Cookie(&fiber.Cookie{
+		Name:     CSRFCookie,
+		Value:    token,
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/",
+	})
+}
+
+// ClearCSRFCookie usuwa token CSRF (wylogowanie)
+func ClearCSRFCookie(c *fiber.Ctx) {
+	c.Cookie(&fiber.Cookie{
+		Name:     CSRFCookie,
+		Value:    "",
+		Secure:   true,
+		SameSite: "Strict",
+		Path:     "/",
+		MaxAge:   -1,
+	})
+}
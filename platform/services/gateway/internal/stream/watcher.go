@@ -0,0 +1,166 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+)
+
+// ErrSessionRevoked – przyczyna zerwania połączenia po zakończeniu sesji
+var ErrSessionRevoked = errors.New("session revoked")
+
+// ErrShutdown – przyczyna zerwania połączenia przy zamykaniu gatewaya
+var ErrShutdown = errors.New("gateway shutting down")
+
+// Watcher pilnuje sesji połączeń długotrwałych (WebSocket / SSE). Połączenie jest
+// zrywane natychmiast po komunikacie z Redis Pub/Sub (wylogowanie, ban, limit sesji),
+// a najpóźniej po interwale kontroli (wygaśnięcie, absolutny czas życia, utracony komunikat).
+type Watcher struct {
+	cache    *redis.Cache
+	session  viper.SessionConfig
+	interval time.Duration
+
+	mu    sync.Mutex
+	seq   uint64
+	conns map[string]map[uint64]context.CancelCauseFunc // sid -> połączenia
+
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func NewWatcher(cache *redis.Cache, session viper.SessionConfig, interval time.Duration) *Watcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Watcher{
+		cache:    cache,
+		session:  session,
+		interval: interval,
+		conns:    make(map[string]map[uint64]context.CancelCauseFunc),
+		ctx:      ctx,
+		cancel:   cancel,
+	}
+}
+
+// Start uruchamia subskrypcję unieważnień i okresową kontrolę sesji
+func (w *Watcher) Start() {
+	go w.subscribe()
+	go w.checkLoop()
+}
+
+// Close zrywa wszystkie śledzone połączenia i zatrzymuje obserwację
+func (w *Watcher) Close() {
+	w.mu.Lock()
+	for _, conns := range w.conns {
+		for _, cancel := range conns {
+			cancel(ErrShutdown)
+		}
+	}
+	w.mu.Unlock()
+
+	w.cancel()
+}
+
+// Track rejestruje połączenie sesji sid. Zwrócony kontekst jest anulowany (z przyczyną
+// ErrSessionRevoked lub ErrShutdown), gdy połączenie trzeba zerwać; untrack należy
+// wywołać po jego zamknięciu.
+func (w *Watcher) Track(sid string) (ctx context.Context, untrack func()) {
+	ctx, cancel := context.WithCancelCause(w.ctx)
+
+	w.mu.Lock()
+	w.seq++
+	id := w.seq
+	if w.conns[sid] == nil {
+		w.conns[sid] = make(map[uint64]context.CancelCauseFunc)
+	}
+	w.conns[sid][id] = cancel
+	w.mu.Unlock()
+
+	return ctx, func() {
+		w.mu.Lock()
+		delete(w.conns[sid], id)
+		if len(w.conns[sid]) == 0 {
+			delete(w.conns, sid)
+		}
+		w.mu.Unlock()
+		cancel(nil)
+	}
+}
+
+func (w *Watcher) revoke(sid string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	for _, cancel := range w.conns[sid] {
+		cancel(ErrSessionRevoked)
+	}
+}
+
+func (w *Watcher) sessions() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	sids := make([]string, 0, len(w.conns))
+	for sid := range w.conns {
+		sids = append(sids, sid)
+	}
+	return sids
+}
+
+// subscribe – przy błędzie Redis ponawiamy po interwale (do tego czasu działa checkLoop)
+func (w *Watcher) subscribe() {
+	for {
+		err := w.cache.WatchRevokedSessions(w.ctx, w.revoke)
+		if w.ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			shared.GetLogger().ErrorObj("Session revocation subscription failed", err)
+		}
+
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-time.After(w.interval):
+		}
+	}
+}
+
+func (w *Watcher) checkLoop() {
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.ctx.Done():
+			return
+		case <-ticker.C:
+			w.check()
+		}
+	}
+}
+
+// check – otwarte połączenie jest aktywnością, więc przesuwamy okno bezczynności
+// (jak przy zwykłym żądaniu); absolutny czas życia sesji nadal obowiązuje
+func (w *Watcher) check() {
+	log := shared.GetLogger()
+
+	for _, sid := range w.sessions() {
+		ctx, cancel := context.WithTimeout(w.ctx, w.interval)
+		_, err := w.cache.TouchSession(ctx, sid, w.session.TTL, w.session.AbsoluteTTL)
+		cancel()
+
+		switch {
+		case err == nil:
+		case errors.Is(err, goredis.Nil), errors.Is(err, redis.ErrSessionLifetimeExceeded):
+			w.revoke(sid)
+		default:
+			// Awaria Redis – nie zrywamy połączeń, spróbujemy przy następnej kontroli
+			log.ErrorObj("Stream session check failed", err)
+		}
+	}
+}
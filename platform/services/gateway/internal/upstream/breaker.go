@@ -0,0 +1,233 @@
+package upstream
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+// State to stan circuit breakera
+type State int
+
+const (
+	StateClosed State = iota
+	StateHalfOpen
+	StateOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateHalfOpen:
+		return "half_open"
+	case StateOpen:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// ErrBreakerOpen – upstream odrzucony bez wysyłania żądania
+var ErrBreakerOpen = errors.New("circuit breaker open")
+
+// BreakerConfig – progi breakera
+type BreakerConfig struct {
+	FailureThreshold int
+	OpenTimeout      time.Duration
+	HalfOpenProbes   int
+}
+
+// Breaker to circuit breaker oparty na kolejnych porażkach:
+//   - closed:    ruch przechodzi; FailureThreshold porażek z rzędu otwiera breaker
+//   - open:      żądania są odrzucane od razu, aż minie OpenTimeout
+//   - half-open: przepuszczamy HalfOpenProbes żądań próbnych; komplet sukcesów zamyka
+//     breaker, pierwsza porażka otwiera go ponownie
+type Breaker struct {
+	name string
+	cfg  BreakerConfig
+
+	mu        sync.Mutex
+	state     State
+	failures  int
+	openedAt  time.Time
+	inFlight  int    // próby w half-open
+	successes int    // sukcesy w half-open
+	forced    *State // stan wymuszony przez operatora (open / closed); nil = automat
+}
+
+func NewBreaker(name string, cfg BreakerConfig) *Breaker {
+	return &Breaker{name: name, cfg: cfg}
+}
+
+// Outcome – wynik żądania zgłaszany do breakera
+type Outcome int
+
+const (
+	OutcomeSuccess Outcome = iota
+	OutcomeFailure
+	// OutcomeAbandoned – żądanie przerwane przez klienta: zwalnia miejsce bez wyniku,
+	// bo nic nie mówi o stanie upstreamu
+	OutcomeAbandoned
+)
+
+// Allow rezerwuje miejsce na żądanie. Zwrócone done musi zostać wywołane z wynikiem.
+func (b *Breaker) Allow() (done func(Outcome), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Wymuszony stan: wyniki żądań go nie zmieniają
+	if b.forced != nil {
+		if *b.forced == StateOpen {
+			return nil, ErrBreakerOpen
+		}
+		return func(Outcome) {}, nil
+	}
+
+	if b.state == StateOpen {
+		if time.Since(b.openedAt) < b.cfg.OpenTimeout {
+			return nil, ErrBreakerOpen
+		}
+		b.transition(StateHalfOpen)
+	}
+
+	probe := b.state == StateHalfOpen
+	if probe {
+		if b.inFlight >= b.cfg.HalfOpenProbes {
+			return nil, ErrBreakerOpen
+		}
+		b.inFlight++
+	}
+
+	return func(outcome Outcome) { b.record(probe, outcome) }, nil
+}
+
+func (b *Breaker) record(probe bool, outcome Outcome) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if probe {
+		b.inFlight--
+	}
+	if outcome == OutcomeAbandoned {
+		return
+	}
+	success := outcome == OutcomeSuccess
+
+	if probe {
+		// Wynik próby z poprzedniego cyklu half-open nie zmienia już stanu
+		if b.state != StateHalfOpen {
+			return
+		}
+		if !success {
+			b.transition(StateOpen)
+			return
+		}
+		b.successes++
+		if b.successes >= b.cfg.HalfOpenProbes {
+			b.transition(StateClosed)
+		}
+		return
+	}
+
+	if success {
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == StateClosed && b.failures >= b.cfg.FailureThreshold {
+		b.transition(StateOpen)
+	}
+}
+
+// transition wymaga trzymanego b.mu
+func (b *Breaker) transition(to State) {
+	from := b.state
+	b.state = to
+	b.failures = 0
+	b.successes = 0
+	if to == StateOpen {
+		b.openedAt = time.Now()
+	}
+
+	shared.GetLogger().WarnMap("Upstream circuit breaker state changed", map[string]any{
+		"upstream": b.name,
+		"from":     from.String(),
+		"to":       to.String(),
+	})
+}
+
+// Force wymusza stan breakera (StateOpen / StateClosed); nil przywraca automat od stanu closed
+func (b *Breaker) Force(state *State) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch {
+	case state == nil && b.forced == nil:
+		return
+	case state != nil && b.forced != nil && *state == *b.forced:
+		return
+	}
+	from := "auto"
+	if b.forced != nil {
+		from = b.forced.String()
+	}
+	to := "auto"
+	if state != nil {
+		forced := *state
+		b.forced = &forced
+		to = forced.String()
+	} else {
+		b.forced = nil
+		b.state, b.failures, b.successes = StateClosed, 0, 0
+	}
+
+	shared.GetLogger().WarnMap("Upstream circuit breaker forced", map[string]any{
+		"upstream": b.name,
+		"from":     from,
+		"to":       to,
+	})
+}
+
+// Forced zwraca stan wymuszony przez operatora (ok=false – automat)
+func (b *Breaker) Forced() (State, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.forced == nil {
+		return 0, false
+	}
+	return *b.forced, true
+}
+
+// State zwraca bieżący stan (open po upływie OpenTimeout raportujemy jako half-open)
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.forced != nil {
+		return *b.forced
+	}
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cfg.OpenTimeout {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// RetryAfter – ile pozostało do próbnego żądania (0 gdy breaker nie jest otwarty)
+func (b *Breaker) RetryAfter() time.Duration {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Wymuszone otwarcie nie ma terminu – podpowiadamy klientom OpenTimeout
+	if b.forced != nil {
+		if *b.forced == StateOpen {
+			return b.cfg.OpenTimeout
+		}
+		return 0
+	}
+	if b.state != StateOpen {
+		return 0
+	}
+	return max(b.cfg.OpenTimeout-time.Since(b.openedAt), 0)
+}
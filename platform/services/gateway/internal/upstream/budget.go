@@ -0,0 +1,57 @@
+package upstream
+
+import (
+	"sync"
+	"time"
+)
+
+const budgetWindow = 10 * time.Second
+
+// RetryBudget ogranicza ponowienia do ułamka ruchu w oknie – przy awarii upstreamu
+// ponowienia nie mogą zwielokrotnić obciążenia.
+type RetryBudget struct {
+	ratio      float64
+	minRetries int
+
+	mu          sync.Mutex
+	windowStart time.Time
+	requests    int
+	retries     int
+}
+
+func NewRetryBudget(ratio float64, minRetries int) *RetryBudget {
+	return &RetryBudget{ratio: ratio, minRetries: minRetries, windowStart: time.Now()}
+}
+
+// Request rejestruje pierwsze podejście żądania
+func (b *RetryBudget) Request() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+	b.requests++
+}
+
+// Withdraw pobiera jedno ponowienie z budżetu; false gdy budżet wyczerpany
+func (b *RetryBudget) Withdraw() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.rotate()
+	allowed := max(b.minRetries, int(float64(b.requests)*b.ratio))
+	if b.retries >= allowed {
+		return false
+	}
+	b.retries++
+	return true
+}
+
+// rotate wymaga trzymanego b.mu
+func (b *RetryBudget) rotate() {
+	if time.Since(b.windowStart) < budgetWindow {
+		return
+	}
+	b.windowStart = time.Now()
+	b.requests = 0
+	b.retries = 0
+}
@@ -0,0 +1,368 @@
+package upstream
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/router/health"
+	"github.com/zerodayz7/platform/pkg/shared"
+	"github.com/zerodayz7/platform/pkg/viper"
+)
+
+// Limit czasu pojedynczej sondy /health
+const probeTimeout = 2 * time.Second
+
+// Metody, które można bezpiecznie powtórzyć
+var idempotentMethods = []string{
+	http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete,
+}
+
+// Upstream grupuje instancje, breaker i budżet ponowień jednego serwisu docelowego
+type Upstream struct {
+	Name    string
+	Pool    *Pool
+	Breaker *Breaker
+	Budget  *RetryBudget
+}
+
+// Client wykonuje żądania do upstreamów: wybór instancji, circuit breaker i ponowienia.
+// Serwisy z konfiguracji są tworzone od razu; upstreamy podane w routes.yaml jako
+// pełny URL – leniwie, bo mogą dojść po przeładowaniu tablicy tras.
+type Client struct {
+	http     *http.Client
+	cfg      viper.ResilienceConfig
+	strategy Strategy
+	poolCfg  PoolConfig
+	interval time.Duration
+
+	mu        sync.RWMutex
+	upstreams map[string]*Upstream
+	stop      chan struct{}
+	stopOnce  sync.Once
+}
+
+func NewClient(httpClient *http.Client, cfg viper.ResilienceConfig, services viper.ServicesConfig, named map[string][]string) (*Client, error) {
+	c := &Client{
+		http:     httpClient,
+		cfg:      cfg,
+		strategy: Strategy(services.Balancer),
+		poolCfg: PoolConfig{
+			EjectAfter:    services.EjectAfter,
+			EjectDuration: services.EjectDuration,
+		},
+		interval:  services.HealthInterval,
+		upstreams: make(map[string]*Upstream),
+		stop:      make(chan struct{}),
+	}
+
+	for name, urls := range named {
+		up, err := c.newUpstream(name, urls)
+		if err != nil {
+			return nil, err
+		}
+		c.upstreams[name] = up
+	}
+
+	registerMetrics(c)
+	return c, nil
+}
+
+func (c *Client) newUpstream(name string, urls []string) (*Upstream, error) {
+	pool, err := NewPool(name, urls, c.poolCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &Upstream{
+		Name: name,
+		Pool: pool,
+		Breaker: NewBreaker(name, BreakerConfig{
+			FailureThreshold: c.cfg.FailureThreshold,
+			OpenTimeout:      c.cfg.OpenTimeout,
+			HalfOpenProbes:   c.cfg.HalfOpenProbes,
+		}),
+		Budget: NewRetryBudget(c.cfg.RetryBudgetRatio, c.cfg.RetryBudgetMin),
+	}, nil
+}
+
+// Get zwraca upstream dla nazwy serwisu lub (tworząc go w razie potrzeby) pełnego URL
+func (c *Client) Get(target string) (*Upstream, error) {
+	c.mu.RLock()
+	up, ok := c.upstreams[target]
+	c.mu.RUnlock()
+	if ok {
+		return up, nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if up, ok := c.upstreams[target]; ok {
+		return up, nil
+	}
+
+	name := target
+	if u, err := url.Parse(target); err == nil && u.Host != "" {
+		name = u.Host
+	}
+	up, err := c.newUpstream(name, []string{target})
+	if err != nil {
+		return nil, err
+	}
+	c.upstreams[target] = up
+	return up, nil
+}
+
+// All zwraca wszystkie upstreamy (także utworzone leniwie z URL w routes.yaml) posortowane po nazwie
+func (c *Client) All() []*Upstream {
+	ups := c.all()
+	slices.SortFunc(ups, func(a, b *Upstream) int { return strings.Compare(a.Name, b.Name) })
+	return ups
+}
+
+func (c *Client) all() []*Upstream {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	out := make([]*Upstream, 0, len(c.upstreams))
+	for _, up := range c.upstreams {
+		out = append(out, up)
+	}
+	return out
+}
+
+// Snapshot zwraca stan breakerów (health / metryki)
+func (c *Client) Snapshot() map[string]State {
+	out := make(map[string]State)
+	for _, up := range c.all() {
+		out[up.Name] = up.Breaker.State()
+	}
+	return out
+}
+
+// HealthChecks raportuje instancje i breakery w formacie health.Checker ("ok" = zdrowy)
+func (c *Client) HealthChecks(context.Context) map[string]string {
+	checks := make(map[string]string)
+	for _, up := range c.all() {
+		checks["upstream_"+up.Name] = up.Pool.Status()
+
+		if state := up.Breaker.State(); state == StateClosed {
+			checks["breaker_"+up.Name] = "ok"
+		} else {
+			checks["breaker_"+up.Name] = state.String()
+		}
+	}
+	return checks
+}
+
+// StartHealthChecks uruchamia aktywne sondowanie /health wszystkich instancji
+func (c *Client) StartHealthChecks() {
+	go func() {
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			c.probe()
+			select {
+			case <-c.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+func (c *Client) probe() {
+	ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+	defer cancel()
+
+	for _, up := range c.all() {
+		byURL := make(map[string]*Instance, len(up.Pool.instances))
+		// Transport proxy – sondy przechodzą przez to samo mTLS co ruch
+		checker := &health.Checker{
+			Service:    up.Name,
+			HTTPClient: &http.Client{Transport: c.http.Transport, Timeout: probeTimeout},
+		}
+		for _, inst := range up.Pool.instances {
+			probeURL := inst.URL.JoinPath("/health").String()
+			byURL[probeURL] = inst
+			checker.Upstreams = append(checker.Upstreams, probeURL)
+		}
+
+		for probeURL, ok := range checker.ProbeUpstreams(ctx) {
+			up.Pool.setHealthy(byURL[probeURL], ok)
+		}
+	}
+}
+
+// Close zatrzymuje sondowanie
+func (c *Client) Close() {
+	c.stopOnce.Do(func() { close(c.stop) })
+}
+
+// Do wysyła żądanie do upstreamu. req musi mieć względny URL (ścieżka + query) –
+// adres instancji jest ustawiany przy każdej próbie. hashKey (ID użytkownika)
+// jest używany przy strategii consistent_hash.
+//
+// timeout obejmuje oczekiwanie na nagłówki odpowiedzi; body jest potem strumieniowane
+// bez limitu czasu, a zamknięcie resp.Body zwalnia kontekst próby.
+//
+// Metody idempotentne są ponawiane (z jitterem, na innej instancji) przy błędach
+// transportu i odpowiedziach 502/503/504, o ile pozwala budżet i body da się
+// wysłać ponownie (req.GetBody). Po odpowiedzi 101 (WebSocket) resp.Body implementuje
+// io.ReadWriteCloser, a instancja jest liczona jako aktywna do jego zamknięcia.
+// Zwraca ErrBreakerOpen lub ErrNoHealthyInstance,
+// gdy żądanie odrzucono bez kontaktu z upstreamem.
+func (c *Client) Do(target string, req *http.Request, hashKey string, timeout time.Duration) (*http.Response, error) {
+	up, err := c.Get(target)
+	if err != nil {
+		return nil, err
+	}
+	up.Budget.Request()
+
+	attempts := 1
+	if slices.Contains(idempotentMethods, req.Method) && replayable(req) {
+		attempts += c.cfg.RetryMax
+	}
+
+	// Limit trasy obejmuje wszystkie próby razem z backoffem. Dotyczy tylko nagłówków –
+	// po ich otrzymaniu kontekst żyje do zamknięcia body (strumieniowanie dużych odpowiedzi).
+	ctx, cancel := context.WithCancelCause(req.Context())
+	timer := time.AfterFunc(timeout, func() { cancel(context.DeadlineExceeded) })
+	req = req.WithContext(ctx)
+
+	var prev *Instance
+	for attempt := 0; ; attempt++ {
+		inst, err := up.Pool.Pick(c.strategy, hashKey, prev)
+		if err != nil {
+			rejectedCounter(up.Name)
+			return settle(ctx, timer, cancel, nil, err)
+		}
+		prev = inst
+
+		done, err := up.Breaker.Allow()
+		if err != nil {
+			rejectedCounter(up.Name)
+			return settle(ctx, timer, cancel, nil, err)
+		}
+
+		inst.active.Add(1)
+		resp, err := c.http.Do(requestFor(req, inst, attempt))
+		if err != nil {
+			inst.active.Add(-1)
+		} else {
+			resp.Body = &trackedBody{ReadCloser: resp.Body, release: func() { inst.active.Add(-1) }}
+		}
+
+		// Klient przerwał żądanie (nie limit trasy) – to nie porażka upstreamu ani instancji
+		if errors.Is(err, context.Canceled) && !errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+			done(OutcomeAbandoned)
+			return settle(ctx, timer, cancel, nil, err)
+		}
+
+		failed := err != nil || isUnavailable(resp.StatusCode)
+		if failed {
+			done(OutcomeFailure)
+		} else {
+			done(OutcomeSuccess)
+		}
+		up.Pool.Report(inst, !failed)
+
+		if !failed {
+			return settle(ctx, timer, cancel, resp, nil)
+		}
+		failureCounter(up.Name)
+
+		// Po przekroczeniu limitu trasy nie ponawiamy – kolejna próba nie miałaby już czasu
+		last := attempt+1 >= attempts
+		if last || !retryable(ctx) || !up.Budget.Withdraw() {
+			return settle(ctx, timer, cancel, resp, err)
+		}
+
+		if resp != nil {
+			_, _ = io.Copy(io.Discard, resp.Body)
+			resp.Body.Close()
+		}
+		retryCounter(up.Name)
+		shared.GetLogger().DebugMap("Retrying upstream request", map[string]any{
+			"upstream": up.Name,
+			"attempt":  attempt + 1,
+		})
+
+		if err := sleepBackoff(ctx, c.cfg.RetryBackoff, c.cfg.RetryBackoffMax, attempt); err != nil {
+			return settle(ctx, timer, cancel, nil, err)
+		}
+	}
+}
+
+// settle kończy Do: zatrzymuje limit trasy i wiąże anulowanie kontekstu z zamknięciem body
+func settle(ctx context.Context, timer *time.Timer, cancel context.CancelCauseFunc, resp *http.Response, err error) (*http.Response, error) {
+	if !timer.Stop() || errors.Is(context.Cause(ctx), context.DeadlineExceeded) {
+		// Timer zdążył anulować kontekst – to przekroczenie czasu trasy, nie zerwanie połączenia
+		cancel(nil)
+		if resp != nil {
+			resp.Body.Close()
+		}
+		return nil, context.DeadlineExceeded
+	}
+	if resp == nil {
+		cancel(nil)
+		return nil, err
+	}
+
+	resp.Body = &trackedBody{ReadCloser: resp.Body, release: func() { cancel(nil) }}
+	return resp, err
+}
+
+// replayable – body można wysłać ponownie (bufor), strumień klienta – nie
+func replayable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// requestFor kieruje żądanie na instancję (kopia – oryginał służy kolejnym próbom)
+func requestFor(req *http.Request, inst *Instance, attempt int) *http.Request {
+	r := req.Clone(req.Context())
+	if attempt > 0 && req.GetBody != nil {
+		r.Body, _ = req.GetBody()
+	}
+
+	r.URL.Scheme = inst.URL.Scheme
+	r.URL.Host = inst.URL.Host
+	if base := strings.TrimRight(inst.URL.Path, "/"); base != "" {
+		r.URL.Path = base + req.URL.Path
+		r.URL.RawPath = ""
+	}
+	r.Host = ""
+	return r
+}
+
+func isUnavailable(status int) bool {
+	return status == http.StatusBadGateway ||
+		status == http.StatusServiceUnavailable ||
+		status == http.StatusGatewayTimeout
+}
+
+// retryable – nie ponawiamy, gdy skończył się czas trasy lub klient się rozłączył
+func retryable(ctx context.Context) bool {
+	return ctx.Err() == nil
+}
+
+// sleepBackoff – wykładniczy backoff z pełnym jitterem
+func sleepBackoff(ctx context.Context, base, maxBackoff time.Duration, attempt int) error {
+	backoff := min(base<<attempt, maxBackoff)
+	timer := time.NewTimer(rand.N(backoff) + 1)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,42 @@
+package upstream
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metryki OTEL – eksportowane, gdy w procesie zarejestrowany jest MeterProvider
+var (
+	meter = otel.Meter("github.com/zerodayz7/platform/services/gateway/upstream")
+
+	retries, _  = meter.Int64Counter("gateway.upstream.retries", metric.WithDescription("Ponowienia żądań do upstreamu"))
+	failures, _ = meter.Int64Counter("gateway.upstream.failures", metric.WithDescription("Nieudane próby (transport / 502 / 503 / 504)"))
+	rejected, _ = meter.Int64Counter("gateway.upstream.rejected", metric.WithDescription("Żądania odrzucone przez otwarty breaker"))
+)
+
+func registerMetrics(c *Client) {
+	_, _ = meter.Int64ObservableGauge("gateway.upstream.breaker.state",
+		metric.WithDescription("Stan breakera: 0 = closed, 1 = half_open, 2 = open"),
+		metric.WithInt64Callback(func(_ context.Context, o metric.Int64Observer) error {
+			for name, state := range c.Snapshot() {
+				o.Observe(int64(state), metric.WithAttributes(attribute.String("upstream", name)))
+			}
+			return nil
+		}),
+	)
+}
+
+func retryCounter(name string) {
+	retries.Add(context.Background(), 1, metric.WithAttributes(attribute.String("upstream", name)))
+}
+
+func failureCounter(name string) {
+	failures.Add(context.Background(), 1, metric.WithAttributes(attribute.String("upstream", name)))
+}
+
+func rejectedCounter(name string) {
+	rejected.Add(context.Background(), 1, metric.WithAttributes(attribute.String("upstream", name)))
+}
@@ -0,0 +1,260 @@
+package upstream
+
+import (
+	"cmp"
+	"errors"
+	"hash/crc32"
+	"io"
+	"net/url"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+// Strategy określa sposób wyboru instancji upstreamu
+type Strategy string
+
+const (
+	RoundRobin     Strategy = "round_robin"
+	LeastConn      Strategy = "least_conn"
+	ConsistentHash Strategy = "consistent_hash"
+)
+
+const virtualNodes = 100
+
+// ErrNoHealthyInstance – wszystkie instancje są niezdrowe lub wyłączone
+var ErrNoHealthyInstance = errors.New("no healthy upstream instance")
+
+// Instance to pojedyncza replika serwisu
+type Instance struct {
+	URL *url.URL
+
+	healthy  atomic.Bool  // wynik aktywnego sondowania
+	active   atomic.Int64 // żądania w toku (least_conn)
+	draining atomic.Bool  // wyłączona przez operatora – bez nowych żądań, trwające kończą się normalnie
+
+	mu           sync.Mutex
+	failures     int
+	ejectedUntil time.Time
+}
+
+func (i *Instance) available(now time.Time) bool {
+	if !i.healthy.Load() || i.draining.Load() {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return !now.Before(i.ejectedUntil)
+}
+
+// PoolConfig – progi pasywnego wyłączania instancji
+type PoolConfig struct {
+	EjectAfter    int
+	EjectDuration time.Duration
+}
+
+// Pool to zbiór instancji jednego serwisu
+type Pool struct {
+	name      string
+	cfg       PoolConfig
+	instances []*Instance
+	ring      []ringPoint
+	next      atomic.Uint64
+}
+
+type ringPoint struct {
+	hash     uint32
+	instance *Instance
+}
+
+func NewPool(name string, urls []string, cfg PoolConfig) (*Pool, error) {
+	p := &Pool{name: name, cfg: cfg}
+	for _, raw := range urls {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return nil, errors.New("invalid upstream instance URL: " + raw)
+		}
+		inst := &Instance{URL: u}
+		// Do pierwszej sondy zakładamy, że instancja działa
+		inst.healthy.Store(true)
+		p.instances = append(p.instances, inst)
+
+		for v := range virtualNodes {
+			h := crc32.ChecksumIEEE([]byte(raw + "#" + strconv.Itoa(v)))
+			p.ring = append(p.ring, ringPoint{hash: h, instance: inst})
+		}
+	}
+	slices.SortFunc(p.ring, func(a, b ringPoint) int { return cmp.Compare(a.hash, b.hash) })
+	return p, nil
+}
+
+// Pick wybiera instancję. exclude (poprzednia próba) jest pomijana, o ile jest alternatywa.
+func (p *Pool) Pick(strategy Strategy, key string, exclude *Instance) (*Instance, error) {
+	now := time.Now()
+	candidates := make([]*Instance, 0, len(p.instances))
+	for _, inst := range p.instances {
+		if inst != exclude && inst.available(now) {
+			candidates = append(candidates, inst)
+		}
+	}
+	if len(candidates) == 0 {
+		if exclude != nil && exclude.available(now) {
+			return exclude, nil
+		}
+		return nil, ErrNoHealthyInstance
+	}
+
+	switch {
+	case strategy == LeastConn:
+		return slices.MinFunc(candidates, func(a, b *Instance) int {
+			return cmp.Compare(a.active.Load(), b.active.Load())
+		}), nil
+	case strategy == ConsistentHash && key != "":
+		return p.pickByHash(key, candidates), nil
+	default:
+		return candidates[p.next.Add(1)%uint64(len(candidates))], nil
+	}
+}
+
+// pickByHash – pierwsza dostępna instancja zgodnie z ruchem wskazówek zegara na pierścieniu
+func (p *Pool) pickByHash(key string, candidates []*Instance) *Instance {
+	h := crc32.ChecksumIEEE([]byte(key))
+	start, _ := slices.BinarySearchFunc(p.ring, h, func(pt ringPoint, t uint32) int {
+		return cmp.Compare(pt.hash, t)
+	})
+	for i := range p.ring {
+		pt := p.ring[(start+i)%len(p.ring)]
+		if slices.Contains(candidates, pt.instance) {
+			return pt.instance
+		}
+	}
+	return candidates[0]
+}
+
+// Report – pasywne wyłączanie instancji po kolejnych błędach
+func (p *Pool) Report(inst *Instance, success bool) {
+	inst.mu.Lock()
+	defer inst.mu.Unlock()
+
+	if success {
+		inst.failures = 0
+		return
+	}
+	inst.failures++
+	if inst.failures >= p.cfg.EjectAfter {
+		inst.failures = 0
+		inst.ejectedUntil = time.Now().Add(p.cfg.EjectDuration)
+		shared.GetLogger().WarnMap("Upstream instance ejected", map[string]any{
+			"upstream": p.name,
+			"instance": inst.URL.String(),
+			"for":      p.cfg.EjectDuration.String(),
+		})
+	}
+}
+
+// setHealthy zapisuje wynik aktywnej sondy
+func (p *Pool) setHealthy(inst *Instance, ok bool) {
+	if inst.healthy.Swap(ok) != ok {
+		shared.GetLogger().WarnMap("Upstream instance health changed", map[string]any{
+			"upstream": p.name,
+			"instance": inst.URL.String(),
+			"healthy":  ok,
+		})
+	}
+}
+
+// InstanceStatus – stan instancji dla admin API
+type InstanceStatus struct {
+	URL          string    `json:"url"`
+	Healthy      bool      `json:"healthy"`
+	Draining     bool      `json:"draining"`
+	EjectedUntil time.Time `json:"ejected_until,omitzero"`
+	Active       int64     `json:"active"`
+}
+
+// Instances zwraca stan wszystkich instancji puli
+func (p *Pool) Instances() []InstanceStatus {
+	now := time.Now()
+	out := make([]InstanceStatus, 0, len(p.instances))
+	for _, inst := range p.instances {
+		st := InstanceStatus{
+			URL:      inst.URL.String(),
+			Healthy:  inst.healthy.Load(),
+			Draining: inst.draining.Load(),
+			Active:   inst.active.Load(),
+		}
+		inst.mu.Lock()
+		if now.Before(inst.ejectedUntil) {
+			st.EjectedUntil = inst.ejectedUntil
+		}
+		inst.mu.Unlock()
+		out = append(out, st)
+	}
+	return out
+}
+
+// HasInstance – czy rawURL jest instancją puli
+func (p *Pool) HasInstance(rawURL string) bool {
+	return slices.ContainsFunc(p.instances, func(inst *Instance) bool { return inst.URL.String() == rawURL })
+}
+
+// SetDraining włącza / wyłącza drain instancji (pusty rawURL = wszystkie instancje)
+func (p *Pool) SetDraining(rawURL string, drain bool) {
+	for _, inst := range p.instances {
+		if rawURL != "" && inst.URL.String() != rawURL {
+			continue
+		}
+		if inst.draining.Swap(drain) != drain {
+			shared.GetLogger().WarnMap("Upstream instance drain changed", map[string]any{
+				"upstream": p.name,
+				"instance": inst.URL.String(),
+				"draining": drain,
+			})
+		}
+	}
+}
+
+// Status zwraca "ok", "partial (n/m)" lub "down" – format health.Checker
+func (p *Pool) Status() string {
+	now := time.Now()
+	up := 0
+	for _, inst := range p.instances {
+		if inst.available(now) {
+			up++
+		}
+	}
+	switch up {
+	case len(p.instances):
+		return "ok"
+	case 0:
+		return "down"
+	default:
+		return "partial (" + strconv.Itoa(up) + "/" + strconv.Itoa(len(p.instances)) + ")"
+	}
+}
+
+// trackedBody zwalnia licznik aktywnych żądań dopiero po przeczytaniu odpowiedzi
+type trackedBody struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+func (b *trackedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// Write – po "101 Switching Protocols" body jest połączeniem dwukierunkowym (WebSocket)
+func (b *trackedBody) Write(p []byte) (int, error) {
+	w, ok := b.ReadCloser.(io.Writer)
+	if !ok {
+		return 0, errors.ErrUnsupported
+	}
+	return w.Write(p)
+}
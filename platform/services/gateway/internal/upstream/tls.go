@@ -0,0 +1,81 @@
+package upstream
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/server"
+)
+
+var tlsDialer = &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+
+// UseMutualTLS przełącza połączenia https transportu na mTLS: gateway przedstawia
+// własny certyfikat, a certyfikat serwisu musi pochodzić z wewnętrznego CA i nosić
+// przypiętą tożsamość (pins: host:port instancji -> SAN DNS). Instancje spoza pins
+// są weryfikowane po nazwie hosta z URL. Certyfikaty są brane z certs przy każdym
+// połączeniu, więc rotacja nie wymaga restartu.
+func UseMutualTLS(transport *http.Transport, certs *server.Certificates, pins map[string]string) {
+	transport.DialTLSContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		serverName, ok := pins[addr]
+		if !ok {
+			host, _, err := net.SplitHostPort(addr)
+			if err != nil {
+				return nil, err
+			}
+			serverName = host
+		}
+
+		conn, err := tlsDialer.DialContext(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+		tlsConn := tls.Client(conn, certs.ClientConfig(serverName))
+		if err := tlsConn.HandshakeContext(ctx); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("mtls handshake with %s (%s): %w", addr, serverName, err)
+		}
+		return tlsConn, nil
+	}
+}
+
+// PinIdentities zamienia wpisy "serwis=tożsamość" (MTLS_UPSTREAM_IDENTITIES) na mapę
+// host:port każdej instancji serwisu -> oczekiwana tożsamość
+func PinIdentities(entries []string, named map[string][]string) (map[string]string, error) {
+	pins := make(map[string]string)
+	for _, entry := range entries {
+		service, identity, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok || service == "" || identity == "" {
+			return nil, fmt.Errorf("mtls identity %q: expected service=identity", entry)
+		}
+		urls, known := named[service]
+		if !known {
+			return nil, fmt.Errorf("mtls identity %q: unknown service %q", entry, service)
+		}
+		for _, raw := range urls {
+			u, err := url.Parse(raw)
+			if err != nil {
+				return nil, fmt.Errorf("mtls identity %q: %w", entry, err)
+			}
+			pins[hostPort(u)] = identity
+		}
+	}
+	return pins, nil
+}
+
+// hostPort – adres w postaci przekazywanej do DialTLSContext
+func hostPort(u *url.URL) string {
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return net.JoinHostPort(u.Hostname(), port)
+}
@@ -0,0 +1,38 @@
+package versioning
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Metryki OTEL – eksportowane, gdy w procesie zarejestrowany jest MeterProvider
+var (
+	meter = otel.Meter("github.com/zerodayz7/platform/services/gateway/versioning")
+
+	requests, _       = meter.Int64Counter("gateway.api.requests", metric.WithDescription("Żądania per wersja API (i trasa) – do decyzji o wycofaniu wersji"))
+	updateRequired, _ = meter.Int64Counter("gateway.app.update_required", metric.WithDescription("Żądania odrzucone przez politykę minimalnej wersji aplikacji"))
+)
+
+// Outcome żądania w danej wersji API
+const (
+	OutcomeActive     = "active"
+	OutcomeDeprecated = "deprecated"
+	OutcomeRetired    = "retired"
+)
+
+// RecordRequest zlicza żądanie w wersji API. route – wzorzec z routes.yaml (ograniczona kardynalność).
+func RecordRequest(version, route, outcome string) {
+	requests.Add(context.Background(), 1, metric.WithAttributes(
+		attribute.String("version", version),
+		attribute.String("route", route),
+		attribute.String("outcome", outcome),
+	))
+}
+
+// RecordUpdateRequired zlicza żądania odrzucone z powodu zbyt starej aplikacji
+func RecordUpdateRequired() {
+	updateRequired.Add(context.Background(), 1)
+}
@@ -0,0 +1,161 @@
+package versioning
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/zerodayz7/platform/pkg/shared"
+)
+
+// Polityka jest odświeżana co policyRefresh; do pierwszego udanego pobrania nie jest egzekwowana
+const policyRefresh = time.Minute
+
+// PolicyPath – endpoint version-service z minimalną wersją aplikacji
+const PolicyPath = "/version"
+
+// Do wysyła żądanie do serwisu (upstream.Client.Do z nazwą serwisu i limitem czasu)
+type Do func(req *http.Request) (*http.Response, error)
+
+// policy – fragment odpowiedzi version-service (model.VersionResponse)
+type policy struct {
+	MinVersion  string `json:"minVersion"`
+	ForceUpdate bool   `json:"forceUpdate"`
+}
+
+// AppPolicy egzekwuje w gatewayu politykę minimalnej wersji aplikacji z version-service:
+// przy forceUpdate aplikacja poniżej minVersion (nagłówek X-App-Version) dostaje 426.
+// Klienci bez nagłówka (np. web) nie są sprawdzani.
+type AppPolicy struct {
+	do      Do
+	current atomic.Pointer[policy]
+
+	stop     chan struct{}
+	stopOnce sync.Once
+}
+
+func NewAppPolicy(do Do) *AppPolicy {
+	return &AppPolicy{do: do, stop: make(chan struct{})}
+}
+
+// Start uruchamia odświeżanie polityki w tle
+func (p *AppPolicy) Start() {
+	go func() {
+		ticker := time.NewTicker(policyRefresh)
+		defer ticker.Stop()
+
+		for {
+			p.refresh()
+			select {
+			case <-p.stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// Close zatrzymuje odświeżanie
+func (p *AppPolicy) Close() {
+	p.stopOnce.Do(func() { close(p.stop) })
+}
+
+func (p *AppPolicy) refresh() {
+	ctx, cancel := context.WithTimeout(context.Background(), policyRefresh/2)
+	defer cancel()
+
+	fetched, err := p.fetch(ctx)
+	if err != nil {
+		// Zostaje ostatnia znana polityka
+		shared.GetLogger().WarnMap("App version policy unavailable", map[string]any{
+			"error":  err.Error(),
+			"cached": p.current.Load() != nil,
+		})
+		return
+	}
+	p.current.Store(fetched)
+}
+
+func (p *AppPolicy) fetch(ctx context.Context) (*policy, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, PolicyPath, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	var fetched policy
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&fetched); err != nil {
+		return nil, err
+	}
+	if _, ok := parseVersion(fetched.MinVersion); !ok {
+		return nil, fmt.Errorf("invalid minVersion %q", fetched.MinVersion)
+	}
+	return &fetched, nil
+}
+
+// RequiresUpdate – aplikacja w wersji appVersion jest poniżej wymuszonego minimum.
+// Nieczytelna wersja nie blokuje żądania.
+func (p *AppPolicy) RequiresUpdate(appVersion string) bool {
+	current := p.current.Load()
+	if current == nil || !current.ForceUpdate || appVersion == "" {
+		return false
+	}
+	app, ok := parseVersion(appVersion)
+	if !ok {
+		return false
+	}
+	minimum, _ := parseVersion(current.MinVersion)
+	return compare(app, minimum) < 0
+}
+
+// parseVersion czyta "2.1.1" (także "v2.1", "2.1.1-beta+42" – bez pre-release i build)
+func parseVersion(v string) ([]int, bool) {
+	v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+	if i := strings.IndexAny(v, "-+"); i >= 0 {
+		v = v[:i]
+	}
+	if v == "" {
+		return nil, false
+	}
+
+	parts := strings.Split(v, ".")
+	nums := make([]int, len(parts))
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums[i] = n
+	}
+	return nums, true
+}
+
+func compare(a, b []int) int {
+	for i := range max(len(a), len(b)) {
+		var x, y int
+		if i < len(a) {
+			x = a[i]
+		}
+		if i < len(b) {
+			y = b[i]
+		}
+		if x != y {
+			return x - y
+		}
+	}
+	return 0
+}
@@ -0,0 +1,101 @@
+package websession
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
+	"github.com/zerodayz7/platform/pkg/redis"
+	"github.com/zerodayz7/platform/pkg/viper"
+	"github.com/zerodayz7/platform/services/gateway/internal/shared"
+	"github.com/zerodayz7/platform/services/gateway/internal/shared/security"
+)
+
+// ModeCookie – wartość X-Session-Mode, którą portal prosi o sesję w ciasteczku
+const ModeCookie = "cookie"
+
+// LocalCookieAuth – Locals: żądanie uwierzytelnione ciasteczkiem sesji (nie tokenem bearer)
+const LocalCookieAuth = "cookieAuth"
+
+const minCSRFSecret = 32
+
+var errNoSession = errors.New("access token without session id")
+
+// Manager – sesje przeglądarkowe. Ciasteczko niesie ID tej samej sesji Redis, którą
+// wskazuje claim "sid" tokenu aplikacji mobilnej; token CSRF to HMAC z ID sesji,
+// więc nie trzeba go przechowywać, a po wylogowaniu traci ważność razem z sesją.
+// Ciasteczko działa tylko dla sesji oznaczonych przy Issue (UserSession.Web) – sam "sid"
+// z przechwyconego tokenu nie wystarcza.
+type Manager struct {
+	cache        *redis.Cache
+	csrfSecret   []byte
+	accessSecret string
+}
+
+// NewManager zwraca nil, gdy tryb przeglądarkowy jest wyłączony
+func NewManager(cache *redis.Cache, cfg viper.WebSessionConfig, accessSecret string) (*Manager, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	if len(cfg.CSRFSecret) < minCSRFSecret {
+		return nil, errors.New("WEB_SESSION_CSRF_SECRET must be at least 32 characters")
+	}
+	return &Manager{cache: cache, csrfSecret: []byte(cfg.CSRFSecret), accessSecret: accessSecret}, nil
+}
+
+// Requested – klient prosi o sesję w ciasteczku zamiast tokenów
+func (m *Manager) Requested(c *fiber.Ctx) bool {
+	return strings.EqualFold(c.Get(constants.HeaderSessionMode), ModeCookie)
+}
+
+// SessionID – ID sesji z ciasteczka ("" – brak)
+func (m *Manager) SessionID(c *fiber.Ctx) string {
+	return c.Cookies(shared.SessionCookie)
+}
+
+// CSRFToken – token CSRF sesji
+func (m *Manager) CSRFToken(sessionID string) string {
+	mac := hmac.New(sha256.New, m.csrfSecret)
+	mac.Write([]byte(sessionID))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyCSRF – metody bezpieczne przechodzą, pozostałe wymagają X-CSRF-Token sesji
+func (m *Manager) VerifyCSRF(c *fiber.Ctx, sessionID string) bool {
+	switch c.Method() {
+	case fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions:
+		return true
+	}
+	got := c.Get(constants.HeaderCSRFToken)
+	return got != "" && hmac.Equal([]byte(got), []byte(m.CSRFToken(sessionID)))
+}
+
+// Issue zakłada ciasteczka sesji wskazanej przez token dostępu (claim "sid") i zwraca token CSRF
+func (m *Manager) Issue(c *fiber.Ctx, accessToken string) (string, error) {
+	claims, err := security.ParseJWT(accessToken, m.accessSecret)
+	if err != nil {
+		return "", err
+	}
+	sid, _ := claims["sid"].(string)
+	if sid == "" {
+		return "", errNoSession
+	}
+	if err := m.cache.MarkWebSession(c.UserContext(), sid); err != nil {
+		return "", err
+	}
+
+	token := m.CSRFToken(sid)
+	shared.SetSessionCookie(c, sid)
+	shared.SetCSRFCookie(c, token)
+	return token, nil
+}
+
+// End usuwa ciasteczka sesji
+func (m *Manager) End(c *fiber.Ctx) {
+	shared.ClearSessionCookie(c)
+	shared.ClearCSRFCookie(c)
+}
@@ -47,7 +47,10 @@ func main() {
 	defer closeDB()
 
 	// Dependency Injection setup
-	container := di.NewContainer(db, redisClient, log, &config.AppConfig)
+	container, err := di.NewContainer(db, redisClient, log, &config.AppConfig)
+	if err != nil {
+		log.Fatal("Container setup failed", "error", err)
+	}
 
 	// Start background workers
 	utils.SafeGo(log, container.Workers.NotificationWorker.Start)
@@ -56,6 +59,12 @@ func main() {
 	app := config.NewNotificationApp(container)
 	router.SetupRoutes(app, container)
 
+	// MTLS_ENABLED – serwis przyjmuje tylko połączenia z certyfikatem klienta (gateway)
+	tlsConfig, certs, err := server.MutualTLS(config.AppConfig.MTLS)
+	if err != nil {
+		log.Fatal("mTLS setup failed", "error", err)
+	}
+
 	// Start server with unified run handler
 	server.Run(
 		app,
@@ -65,10 +74,12 @@ func main() {
 			AppVersion: config.AppConfig.Server.AppVersion,
 			Env:        config.AppConfig.Server.Env,
 			Shutdown:   config.AppConfig.Shutdown,
+			TLS:        tlsConfig,
 		},
 		*log,
 		func() {
 			closeDB()
+			certs.Close()
 			_ = redisClient.Close()
 			// Additional resource cleanup can be added here
 		},
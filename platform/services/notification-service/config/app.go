@@ -4,6 +4,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/recover"
 	"github.com/gofiber/fiber/v2/middleware/requestid"
+	"github.com/zerodayz7/platform/pkg/middleware"
 	"github.com/zerodayz7/platform/pkg/server"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/notification-service/internal/di"
@@ -25,7 +26,7 @@ func NewNotificationApp(container *di.Container) *fiber.App {
 
 		ProxyHeader:             fiber.HeaderXForwardedFor,
 		EnableTrustedProxyCheck: true,
-		TrustedProxies:          []string{"127.0.0.1", "::1"},
+		TrustedProxies:          cfg.TrustedProxies, // adres gatewaya (TRUSTED_PROXIES)
 		BodyLimit:               cfg.BodyLimitMB * 1024 * 1024,
 		DisableStartupMessage:   true,
 		EnableIPValidation:      true,
@@ -41,8 +42,12 @@ func NewNotificationApp(container *di.Container) *fiber.App {
 	app.Use(shared.GetLimiter(shared.LimitGlobal, nil))
 	app.Use(shared.RequestLoggerMiddleware())
 
-	// Jeśli potrzebujesz InternalAuthMiddleware w powiadomieniach:
-	// app.Use(middleware.InternalAuthMiddleware(container.InternalSecret))
+	app.Use(middleware.InternalAuthMiddleware(middleware.InternalAuthConfig{
+		Keys:     container.InternalKeys,
+		Audience: container.Config.Internal.Audience,
+		Strict:   container.Config.Internal.Strict,
+		Nonces:   container.Cache,
+	}))
 
 	return app
 }
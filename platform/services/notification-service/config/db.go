@@ -8,10 +8,11 @@ import (
 )
 
 func MustInitDB(cfg viper.DBConfig) (*gorm.DB, func()) {
-	// 1. Inicjalizacja z pkg - przekazujemy tylko model powiadomienia
+	// 1. Inicjalizacja z pkg - przekazujemy modele powiadomień
 	// pkg/database sam zadba o MaxOpenConns, Ping i całą resztę.
 	db, closeDB, err := database.NewPostgres(cfg,
 		&model.Notification{},
+		&model.BroadcastReceipt{},
 	)
 	if err != nil {
 		// Jeśli baza jest niezbędna do działania serwisu, panic jest tu ok
@@ -1,6 +1,9 @@
 package di
 
 import (
+	"fmt"
+
+	reqctx "github.com/zerodayz7/platform/pkg/context"
 	"github.com/zerodayz7/platform/pkg/redis"
 	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/pkg/viper"
@@ -8,25 +11,36 @@ import (
 )
 
 type Container struct {
-	Handlers *Handlers
-	Workers  *Workers
-	Redis    *redis.Client
-	Logger   *shared.Logger
-	Config   *viper.Config
+	Handlers     *Handlers
+	Workers      *Workers
+	Redis        *redis.Client
+	Cache        *redis.Cache
+	InternalKeys *reqctx.Keyring
+	Logger       *shared.Logger
+	Config       *viper.Config
 }
 
-func NewContainer(db *gorm.DB, redisClient *redis.Client, log *shared.Logger, cfg *viper.Config) *Container {
+func NewContainer(db *gorm.DB, redisClient *redis.Client, log *shared.Logger, cfg *viper.Config) (*Container, error) {
 	repos := NewRepositories(db)
-	services := NewServices(repos)
+	// Cache służy tu do publikacji zdarzeń (cache_purge_stream) i nonce kontekstu wewnętrznego
+	cache := redis.NewCache(redisClient, cfg.Session.TTL)
+	services := NewServices(repos, cache)
 
 	handlers := NewHandlers(services)
 	workers := NewWorkers(redisClient, services, log)
 
-	return &Container{
-		Handlers: handlers,
-		Workers:  workers,
-		Redis:    redisClient,
-		Logger:   log,
-		Config:   cfg,
+	internalKeys, err := reqctx.NewKeyring(cfg.Internal.KeyID, []byte(cfg.Internal.HMACSecret), cfg.Internal.AcceptedKeys)
+	if err != nil {
+		return nil, fmt.Errorf("INTERNAL_HMAC_ACCEPTED_KEYS: %w", err)
 	}
+
+	return &Container{
+		Handlers:     handlers,
+		Workers:      workers,
+		Redis:        redisClient,
+		Cache:        cache,
+		InternalKeys: internalKeys,
+		Logger:       log,
+		Config:       cfg,
+	}, nil
 }
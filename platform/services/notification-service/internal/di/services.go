@@ -1,6 +1,8 @@
 package di
 
 import (
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/redis"
 	notificationService "github.com/zerodayz7/platform/services/notification-service/internal/service"
 )
 
@@ -8,8 +10,11 @@ type Services struct {
 	NotificationSvc *notificationService.NotificationService
 }
 
-func NewServices(repos *Repositories) *Services {
+func NewServices(repos *Repositories, cache *redis.Cache) *Services {
 	return &Services{
-		NotificationSvc: notificationService.NewNotificationService(repos.NotificationRepo),
+		NotificationSvc: notificationService.NewNotificationService(
+			repos.NotificationRepo,
+			events.NewCachePurger(cache),
+		),
 	}
 }
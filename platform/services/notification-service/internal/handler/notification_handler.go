@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/constants"
 	"github.com/zerodayz7/platform/pkg/errors"
 	"github.com/zerodayz7/platform/pkg/utils"
 	"github.com/zerodayz7/platform/services/notification-service/internal/model"
@@ -35,6 +36,10 @@ func (h *NotificationHandler) ListMyNotifications(c *fiber.Ctx) error {
 	if err != nil {
 		return errors.SendAppError(c, errors.ErrInternal)
 	}
+
+	// Gateway może trzymać listę w cache (per użytkownik); zapisy unieważniają tag
+	c.Set(fiber.HeaderCacheControl, "private, max-age=30")
+	c.Set(constants.HeaderCacheTag, service.CacheTag)
 	return c.JSON(notifications)
 }
 
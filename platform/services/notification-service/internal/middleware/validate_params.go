@@ -2,9 +2,12 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/openapi"
 )
 
 func ValidateParams[T any]() fiber.Handler {
+	openapi.ExpectParams[T]()
+
 	return func(c *fiber.Ctx) error {
 		params := new(T)
 		if err := c.ParamsParser(params); err != nil {
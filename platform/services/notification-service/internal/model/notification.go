@@ -21,6 +21,20 @@ type Notification struct {
 	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
+// BroadcastUserID – właściciel powiadomień dla wszystkich użytkowników (jeden wiersz
+// zamiast kopii per użytkownik; stan u użytkownika trzyma BroadcastReceipt)
+var BroadcastUserID = uuid.Nil
+
+// BroadcastReceipt – stan powiadomienia broadcast u jednego użytkownika: przeczytane,
+// w koszu albo usunięte na stałe (wiersz powstaje przy pierwszej zmianie stanu)
+type BroadcastReceipt struct {
+	NotificationID uuid.UUID  `gorm:"type:uuid;primaryKey"`
+	UserID         uuid.UUID  `gorm:"type:uuid;primaryKey;index"`
+	IsRead         bool       `gorm:"default:false"`
+	TrashedAt      *time.Time `gorm:"index"`
+	Dismissed      bool       `gorm:"default:false"`
+}
+
 type NotificationEvent struct {
 	UserID   uuid.UUID      `json:"user_id"`
 	Title    string         `json:"title"`
@@ -28,6 +42,8 @@ type NotificationEvent struct {
 	Priority string         `json:"priority"`
 	Category string         `json:"category"`
 	Metadata map[string]any `json:"metadata"`
+	// Broadcast – powiadomienie dla wszystkich użytkowników (np. zapowiedź prac serwisowych)
+	Broadcast bool `json:"broadcast"`
 }
 
 func (n *Notification) BeforeCreate(tx *gorm.DB) (err error) {
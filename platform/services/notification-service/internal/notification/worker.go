@@ -61,6 +61,15 @@ func (w *NotificationWorker) Start() {
 				continue
 			}
 
+			// Brak odbiorcy bez jawnego broadcast to błąd producenta – nie rozsyłamy tego do wszystkich
+			if evt.Broadcast {
+				evt.UserID = model.BroadcastUserID
+			} else if evt.UserID == model.BroadcastUserID {
+				w.logger.WarnMap("NotificationWorker: event without user_id dropped", map[string]any{"id": entry.ID, "title": evt.Title})
+				_ = w.redis.AckStream(ctx, notificationStream, notificationGroup, entry.ID)
+				continue
+			}
+
 			// LOGIKA: Nie ustawiamy ID, CreatedAt ani IsRead ręcznie.
 			// Model zrobi to sam w BeforeCreate podczas s.svc.Send(ctx, notification)
 			notification := &model.Notification{
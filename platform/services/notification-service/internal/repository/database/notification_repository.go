@@ -2,10 +2,12 @@ package mysql
 
 import (
 	"context"
+	"slices"
 
 	"github.com/google/uuid"
 	"github.com/zerodayz7/platform/services/notification-service/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type NotificationRepository struct {
@@ -22,16 +24,44 @@ func (r *NotificationRepository) Create(ctx context.Context, n *model.Notificati
 	return r.db.WithContext(ctx).Create(n).Error
 }
 
+// GetByUserID zwraca powiadomienia użytkownika razem z powiadomieniami dla wszystkich (broadcast).
+// Broadcast ma stan przeczytania z BroadcastReceipt; wyrzucone do kosza lub usunięte są pomijane.
 func (r *NotificationRepository) GetByUserID(ctx context.Context, userID uuid.UUID) ([]model.Notification, error) {
 	var notifications []model.Notification
 	err := r.db.WithContext(ctx).
 		Where("user_id = ? AND deleted_at IS NULL", userID).
-		Order("created_at DESC").
 		Find(&notifications).Error
-	return notifications, err
+	if err != nil {
+		return nil, err
+	}
+
+	var broadcasts []model.Notification
+	err = r.db.WithContext(ctx).
+		Select(`notifications.id, notifications.user_id, notifications.title, notifications.content,
+			notifications.priority, notifications.category, notifications.created_at, notifications.updated_at,
+			COALESCE(br.is_read, false) AS is_read`).
+		Joins("LEFT JOIN broadcast_receipts br ON br.notification_id = notifications.id AND br.user_id = ?", userID).
+		Where("notifications.user_id = ? AND br.trashed_at IS NULL AND COALESCE(br.dismissed, false) = false", model.BroadcastUserID).
+		Find(&broadcasts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	notifications = append(notifications, broadcasts...)
+	slices.SortFunc(notifications, func(a, b model.Notification) int {
+		return b.CreatedAt.Compare(a.CreatedAt)
+	})
+	return notifications, nil
 }
 
 func (r *NotificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	broadcast, err := r.isBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if broadcast {
+		return r.setReceipt(ctx, id, userID, "is_read", true)
+	}
 	return r.db.WithContext(ctx).
 		Model(&model.Notification{}).
 		Where("id = ? AND user_id = ?", id, userID).
@@ -39,13 +69,31 @@ func (r *NotificationRepository) MarkAsRead(ctx context.Context, id uuid.UUID, u
 }
 
 func (r *NotificationRepository) MarkAllAsRead(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Model(&model.Notification{}).
-		Where("user_id = ? AND is_read = ?", userID, false).
-		Update("is_read", true).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&model.Notification{}).
+			Where("user_id = ? AND is_read = ?", userID, false).
+			Update("is_read", true).Error
+		if err != nil {
+			return err
+		}
+		// Broadcasty bez stanu u użytkownika dostają go od razu jako przeczytane
+		return tx.Exec(`
+			INSERT INTO broadcast_receipts (notification_id, user_id, is_read, dismissed)
+			SELECT id, ?, true, false FROM notifications WHERE user_id = ? AND deleted_at IS NULL
+			ON CONFLICT (notification_id, user_id) DO UPDATE SET is_read = true`,
+			userID, model.BroadcastUserID,
+		).Error
+	})
 }
 
 func (r *NotificationRepository) MoveToTrash(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	broadcast, err := r.isBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if broadcast {
+		return r.setReceipt(ctx, id, userID, "trashed_at", gorm.Expr("NOW()"))
+	}
 	return r.db.WithContext(ctx).
 		Model(&model.Notification{}).
 		Where("id = ? AND user_id = ?", id, userID).
@@ -53,6 +101,16 @@ func (r *NotificationRepository) MoveToTrash(ctx context.Context, id uuid.UUID,
 }
 
 func (r *NotificationRepository) RestoreFromTrash(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	broadcast, err := r.isBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if broadcast {
+		return r.db.WithContext(ctx).
+			Model(&model.BroadcastReceipt{}).
+			Where("notification_id = ? AND user_id = ? AND dismissed = ?", id, userID, false).
+			Update("trashed_at", nil).Error
+	}
 	return r.db.WithContext(ctx).
 		Model(&model.Notification{}).
 		Where("id = ? AND user_id = ?", id, userID).
@@ -60,13 +118,53 @@ func (r *NotificationRepository) RestoreFromTrash(ctx context.Context, id uuid.U
 }
 
 func (r *NotificationRepository) HardDeleteTrash(ctx context.Context, userID uuid.UUID) error {
-	return r.db.WithContext(ctx).
-		Where("user_id = ? AND deleted_at IS NOT NULL", userID).
-		Delete(&model.Notification{}).Error
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ? AND deleted_at IS NOT NULL", userID).
+			Delete(&model.Notification{}).Error
+		if err != nil {
+			return err
+		}
+		// Wiersz broadcastu jest wspólny – u użytkownika zostaje tylko oznaczony jako usunięty
+		return tx.Model(&model.BroadcastReceipt{}).
+			Where("user_id = ? AND trashed_at IS NOT NULL", userID).
+			Update("dismissed", true).Error
+	})
 }
 
 func (r *NotificationRepository) DeletePermanently(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
+	broadcast, err := r.isBroadcast(ctx, id)
+	if err != nil {
+		return err
+	}
+	if broadcast {
+		return r.setReceipt(ctx, id, userID, "dismissed", true)
+	}
 	return r.db.WithContext(ctx).
 		Where("id = ? AND user_id = ?", id, userID).
 		Delete(&model.Notification{}).Error
 }
+
+// isBroadcast – powiadomienie należy do wszystkich użytkowników (model.BroadcastUserID)
+func (r *NotificationRepository) isBroadcast(ctx context.Context, id uuid.UUID) (bool, error) {
+	var count int64
+	err := r.db.WithContext(ctx).
+		Model(&model.Notification{}).
+		Where("id = ? AND user_id = ?", id, model.BroadcastUserID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// setReceipt ustawia kolumnę stanu broadcastu u użytkownika (wiersz powstaje przy pierwszej zmianie)
+func (r *NotificationRepository) setReceipt(ctx context.Context, id, userID uuid.UUID, column string, value any) error {
+	return r.db.WithContext(ctx).
+		Model(&model.BroadcastReceipt{}).
+		Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "notification_id"}, {Name: "user_id"}},
+			DoUpdates: clause.Assignments(map[string]any{column: value}),
+		}).
+		Create(map[string]any{
+			"notification_id": id,
+			"user_id":         userID,
+			column:            value,
+		}).Error
+}
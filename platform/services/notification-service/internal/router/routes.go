@@ -2,6 +2,7 @@ package router
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"github.com/zerodayz7/platform/pkg/openapi"
 	pkgRouter "github.com/zerodayz7/platform/pkg/router"
 	"github.com/zerodayz7/platform/pkg/router/health"
 	"github.com/zerodayz7/platform/pkg/shared"
@@ -20,6 +21,11 @@ func SetupRoutes(app *fiber.App, container *di.Container) {
 	}
 	health.RegisterRoutes(app, checker)
 
+	docs := openapi.New(app, openapi.Info{
+		Title:   "notification-service",
+		Version: container.Config.Server.AppVersion,
+	})
+
 	// 3. Grupa powiadomień
 	notifications := app.Group("/notifications")
 	{
@@ -40,6 +46,9 @@ func SetupRoutes(app *fiber.App, container *di.Container) {
 		notifications.Delete("/:id", h.DeletePermanently)
 	}
 
+	// GET /openapi.json – trasy zarejestrowane powyżej (pkg/openapi)
+	docs.RegisterRoutes()
+
 	// 4. Globalny Fallback z pkg (404, favicon itp.)
 	pkgRouter.SetupFallbackHandlers(app)
 }
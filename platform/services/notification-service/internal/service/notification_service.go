@@ -4,21 +4,27 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/zerodayz7/platform/pkg/events"
+	"github.com/zerodayz7/platform/pkg/shared"
 	"github.com/zerodayz7/platform/services/notification-service/internal/model"
 	mysql "github.com/zerodayz7/platform/services/notification-service/internal/repository/database"
 )
 
+// CacheTag – tag odpowiedzi GET /notifications w cache gatewaya
+const CacheTag = "notifications"
+
 type NotificationService struct {
-	repo *mysql.NotificationRepository
+	repo   *mysql.NotificationRepository
+	purger *events.CachePurger
 }
 
-func NewNotificationService(repo *mysql.NotificationRepository) *NotificationService {
-	return &NotificationService{repo: repo}
+func NewNotificationService(repo *mysql.NotificationRepository, purger *events.CachePurger) *NotificationService {
+	return &NotificationService{repo: repo, purger: purger}
 }
 
 func (s *NotificationService) Send(ctx context.Context, n *model.Notification) error {
 	// Logika ID i dat została przeniesiona do model.BeforeCreate
-	return s.repo.Create(ctx, n)
+	return s.purgeAfter(ctx, n.UserID, s.repo.Create(ctx, n))
 }
 
 func (s *NotificationService) ListByUser(ctx context.Context, userID uuid.UUID) ([]model.Notification, error) {
@@ -26,25 +32,42 @@ func (s *NotificationService) ListByUser(ctx context.Context, userID uuid.UUID)
 }
 
 func (s *NotificationService) MarkAllRead(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.MarkAllAsRead(ctx, userID)
+	return s.purgeAfter(ctx, userID, s.repo.MarkAllAsRead(ctx, userID))
 }
 
 func (s *NotificationService) MarkRead(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.repo.MarkAsRead(ctx, id, userID)
+	return s.purgeAfter(ctx, userID, s.repo.MarkAsRead(ctx, id, userID))
 }
 
 func (s *NotificationService) MoveToTrash(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.repo.MoveToTrash(ctx, id, userID)
+	return s.purgeAfter(ctx, userID, s.repo.MoveToTrash(ctx, id, userID))
 }
 
 func (s *NotificationService) ClearTrash(ctx context.Context, userID uuid.UUID) error {
-	return s.repo.HardDeleteTrash(ctx, userID)
+	return s.purgeAfter(ctx, userID, s.repo.HardDeleteTrash(ctx, userID))
 }
 
 func (s *NotificationService) Restore(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.repo.RestoreFromTrash(ctx, id, userID)
+	return s.purgeAfter(ctx, userID, s.repo.RestoreFromTrash(ctx, id, userID))
 }
 
 func (s *NotificationService) DeletePermanently(ctx context.Context, id uuid.UUID, userID uuid.UUID) error {
-	return s.repo.DeletePermanently(ctx, id, userID)
+	return s.purgeAfter(ctx, userID, s.repo.DeletePermanently(ctx, id, userID))
+}
+
+// purgeAfter unieważnia listę powiadomień użytkownika w cache gatewaya po udanym zapisie.
+// Błąd publikacji nie cofa zapisu – wpis wygaśnie po TTL trasy.
+func (s *NotificationService) purgeAfter(ctx context.Context, userID uuid.UUID, err error) error {
+	if err != nil {
+		return err
+	}
+	// Broadcast zmienia listę każdego użytkownika
+	target := userID.String()
+	if userID == model.BroadcastUserID {
+		target = ""
+	}
+	if perr := s.purger.Purge(ctx, target, CacheTag); perr != nil {
+		shared.GetLogger().ErrorObj("Failed to publish cache purge", perr)
+	}
+	return nil
 }